@@ -1,22 +1,33 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/cli"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
 	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
 )
 
 func main() {
-	conf := &database.GormConfing{
-		Dsn:     "vFS.db",
-		Migrate: true,
+	conf, err := cli.LoadConfig(os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
 
-	infra, err := inject.NewInfra(conf)
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Driver: conf.Driver,
+		Dsn:    conf.Dsn,
+		Debug:  conf.Debug,
+	})
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
 
 	command := inject.NewRootCommand(infra)
+	command.AddCommand(cli.NewConfigCommand(conf))
 
 	command.Execute()
 
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func audit(svc app.AuditService) *cobra.Command {
+	const prompt = "audit [username] [--since] [time|duration] [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "audit", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	since := command.Flags().String("since", "", "only list the entries since the time or the duration ago, such as 2024-05-27 or 7d")
+	output := addOutputFlag(command)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		req := app.ListAuditParams{}
+		if *since != "" {
+			req.Since, err = parseSince(*since, time.Now())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: The since %v %v\n", *since, app.ErrInvalidParams)
+				return
+			}
+		}
+
+		entries, err := svc.ListAudit(cmd.Context(), username, req)
+		isEmpty := errors.Is(err, app.ErrListAuditEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toAuditRecords(entries))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, entry := range entries {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v %v %v", entry.CreatedTime.Format("2006-01-02 15:04:05"), auditActor(entry.Actor), entry.Action)
+			for _, value := range []string{entry.Before, entry.After} {
+				if value != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), " %v", value)
+				}
+			}
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+	}
+	return command
+}
+
+// parseSince accepts a time, or a duration before now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	duration, err := pkg.ParseDuration(s)
+	if err == nil {
+		return now.Add(-duration), nil
+	}
+	return pkg.ParseTime(s, time.Local)
+}
+
+// auditActor names the changes made without login.
+func auditActor(actor string) string {
+	if actor == "" {
+		return "anonymous"
+	}
+	return actor
+}
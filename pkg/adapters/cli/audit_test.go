@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_audit(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "empty",
+			request:      `audit user1`,
+			hasErr:       false,
+			wantResponse: "Warning: There are no audit entries.\n",
+		},
+		{
+			name:         "create folder",
+			request:      `create-folder user1 folder4`,
+			hasErr:       false,
+			wantResponse: "Create folder4 successfully.\n",
+		},
+		{
+			name:         "The entries before --since are skipped.",
+			request:      `audit user1 --since 2999-01-01`,
+			hasErr:       false,
+			wantResponse: "Warning: There are no audit entries.\n",
+		},
+		{
+			name:         "The [since] is invalid.",
+			request:      `audit user1 --since yesterday`,
+			hasErr:       true,
+			wantResponse: "Error: The since yesterday contain invalid chars.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `audit user4`,
+			hasErr:       true,
+			wantResponse: "Error: The user4 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
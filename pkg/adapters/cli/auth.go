@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// EnvSession overrides where login stores the session token,
+// the default is $XDG_STATE_HOME/vFS/session.
+const EnvSession = "VFS_SESSION"
+
+func sessionPath(getenv func(key string) string) string {
+	path := getenv(EnvSession)
+	if path != "" {
+		return path
+	}
+	return filepath.Join(xdgDir(getenv("XDG_STATE_HOME"), getenv("HOME"), ".local/state"), "vFS", "session")
+}
+
+func readSessionToken() (string, error) {
+	data, err := os.ReadFile(sessionPath(os.Getenv))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeSessionToken keeps the token readable by the current os user only.
+func writeSessionToken(token string) error {
+	path := sessionPath(os.Getenv)
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(token+"\n"), 0o600)
+}
+
+func removeSessionToken() error {
+	err := os.Remove(sessionPath(os.Getenv))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// authenticate puts the app.Principal of the stored session into the context of the command,
+// the command runs without a principal when nobody has logged in.
+func authenticate(svc app.AuthService) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		token, err := readSessionToken()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err == nil {
+			var principal app.Principal
+			principal, err = svc.Authenticate(cmd.Context(), token, time.Now())
+			if err == nil {
+				cmd.SetContext(app.ContextWithPrincipal(cmd.Context(), principal))
+				return nil
+			}
+		}
+
+		cmd.SilenceUsage = true
+		fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+		return err
+	}
+}
+
+// readPassword takes the password from args, or from the first line of stdin,
+// so the password doesn't have to stay in the shell history.
+func readPassword(cmd *cobra.Command, args []string, i int) (string, error) {
+	if len(args) > i {
+		return args[i], nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Password: ")
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return "", scanner.Err()
+		}
+		return "", errors.New("Error: The password is required.")
+	}
+	return scanner.Text(), nil
+}
+
+func setPassword(svc app.AuthService) *cobra.Command {
+	const prompt = "set-password [username] [password]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "auth", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.RangeArgs(1, 2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		password, err := readPassword(cmd, args, 1)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		req := app.SetPasswordParams{
+			Username: args[0],
+			Password: password,
+		}
+
+		err = svc.SetPassword(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Set the password of %v successfully.\n", req.Username)
+	}
+	return command
+}
+
+func login(svc app.AuthService, migrator *pkg.Migrator) *cobra.Command {
+	const prompt = "login [username] [password]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "auth", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	// overrides authenticate of the root command, an expired session must not stop the next login.
+	command.PersistentPreRunE = checkSchema(migrator)
+
+	command.Args = cobra.RangeArgs(1, 2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		password, err := readPassword(cmd, args, 1)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		req := app.LoginParams{
+			Username:  args[0],
+			Password:  password,
+			LoginTime: time.Now(),
+		}
+
+		session, err := svc.Login(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		err = writeSessionToken(session.Token)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Login %v successfully.\n", session.Username)
+	}
+	return command
+}
+
+func logout(svc app.AuthService, migrator *pkg.Migrator) *cobra.Command {
+	const prompt = "logout"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "auth", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	// overrides authenticate of the root command, an expired session is still removed.
+	command.PersistentPreRunE = checkSchema(migrator)
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		token, err := readSessionToken()
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(cmd.OutOrStdout(), "Warning: Nobody has logged in.\n")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			return
+		}
+
+		err = svc.Logout(cmd.Context(), token)
+		if err != nil && !errors.Is(err, app.ErrSessionNotExists) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		err = removeSessionToken()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Logout successfully.\n")
+	}
+	return command
+}
+
+func whoami() *cobra.Command {
+	const prompt = "whoami"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "auth", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		principal, ok := app.PrincipalFrom(cmd.Context())
+		if !ok {
+			fmt.Fprintf(cmd.OutOrStdout(), "Warning: Nobody has logged in.\n")
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%v\n", principal.Username)
+	}
+	return command
+}
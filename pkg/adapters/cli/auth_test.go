@@ -0,0 +1,119 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_login(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "logout the session of testdata",
+			request:      `logout`,
+			hasErr:       false,
+			wantResponse: "Logout successfully.\n",
+		},
+		{
+			name:         "Nobody acts on a file system without login.",
+			request:      `create-folder user1 folder4`,
+			hasErr:       true,
+			wantResponse: "Error: The user needs to login.\n",
+		},
+		{
+			name:         "Nobody can change the password without login.",
+			request:      `set-password user1 other-password`,
+			hasErr:       true,
+			wantResponse: "Error: The user needs to login.\n",
+		},
+		{
+			name:         "The password is incorrect.",
+			request:      `login user1 other-password`,
+			hasErr:       true,
+			wantResponse: "Error: The username or password is incorrect.\n",
+		},
+		{
+			name:         "The user doesn't exist.",
+			request:      `login user4 user1-password`,
+			hasErr:       true,
+			wantResponse: "Error: The username or password is incorrect.\n",
+		},
+		{
+			name:         "success",
+			request:      `login USER1 user1-password`,
+			hasErr:       false,
+			wantResponse: "Login user1 successfully.\n",
+		},
+		{
+			name:         "whoami",
+			request:      `whoami`,
+			hasErr:       false,
+			wantResponse: "user1\n",
+		},
+		{
+			name:         "The password is too short.",
+			request:      `set-password user1 123`,
+			hasErr:       true,
+			wantResponse: "Error: The password must have 8 to 72 bytes, it contain invalid chars.\n",
+		},
+		{
+			name:         "The principal changes its password.",
+			request:      `set-password user1 other-password`,
+			hasErr:       false,
+			wantResponse: "Set the password of user1 successfully.\n",
+		},
+		{
+			name:         "The principal acts on its file system.",
+			request:      `create-folder user1 folder4`,
+			hasErr:       false,
+			wantResponse: "Create folder4 successfully.\n",
+		},
+		{
+			name:         "The other user logs in.",
+			request:      `login user2 user2-password`,
+			hasErr:       false,
+			wantResponse: "Login user2 successfully.\n",
+		},
+		{
+			name:         "The principal can't manage other users.",
+			request:      `delete-user user1`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to manage user1.\n",
+		},
+		{
+			name:         "Only an admin sets the quota.",
+			request:      `set-quota user2 --max-files 100`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to set the quota of user2.\n",
+		},
+		{
+			name:         "Only an admin sets the role.",
+			request:      `set-role user2 admin`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to set the role of user2.\n",
+		},
+		{
+			name:         "logout",
+			request:      `logout`,
+			hasErr:       false,
+			wantResponse: "Logout successfully.\n",
+		},
+		{
+			name:         "Nobody has logged in.",
+			request:      `whoami`,
+			hasErr:       false,
+			wantResponse: "Warning: Nobody has logged in.\n",
+		},
+		{
+			name:         "logout again",
+			request:      `logout`,
+			hasErr:       false,
+			wantResponse: "Warning: Nobody has logged in.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
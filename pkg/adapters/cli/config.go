@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+const (
+	EnvDriver = "VFS_DRIVER"
+	EnvDsn    = "VFS_DSN"
+)
+
+const defaultDriver = "sqlite"
+
+// legacyDsn is the default before it moved to $XDG_DATA_HOME.
+const legacyDsn = "vFS.db"
+
+// Config is loaded before the database is opened,
+// the later source overrides the former:
+//
+//	default < $XDG_CONFIG_HOME/vFS/config.yaml < VFS_DRIVER, VFS_DSN < --driver, --db, --debug
+type Config struct {
+	// Driver is sqlite, postgres or mysql.
+	Driver string `yaml:"driver"`
+	Dsn    string `yaml:"dsn"`
+	Debug  bool   `yaml:"debug"`
+
+	// Path is the config file which has been read, empty means no config file.
+	Path string `yaml:"-"`
+}
+
+// LoadConfig reads the config file and the environment,
+// and picks the global flags out of args, the other args are left to the commands.
+func LoadConfig(args []string, getenv func(key string) string) (*Config, error) {
+	home := getenv("HOME")
+
+	defaultDsn := filepath.Join(xdgDir(getenv("XDG_DATA_HOME"), home, ".local/share"), "vFS", "vFS.db")
+	conf := &Config{Driver: defaultDriver, Dsn: defaultDsn}
+
+	path := filepath.Join(xdgDir(getenv("XDG_CONFIG_HOME"), home, ".config"), "vFS", "config.yaml")
+	err := conf.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	driver := getenv(EnvDriver)
+	if driver != "" {
+		conf.Driver = driver
+	}
+	dsn := getenv(EnvDsn)
+	if dsn != "" {
+		conf.Dsn = dsn
+	}
+
+	flags := pflag.NewFlagSet("vFS", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	flags.BoolP("help", "h", false, "")
+	addGlobalFlags(flags)
+	err = flags.Parse(args)
+	if err != nil {
+		return nil, fmt.Errorf("Error: The flag %w", err)
+	}
+	if flags.Changed("driver") {
+		conf.Driver, _ = flags.GetString("driver")
+	}
+	if flags.Changed("db") {
+		conf.Dsn, _ = flags.GetString("db")
+	}
+	if flags.Changed("debug") {
+		conf.Debug, _ = flags.GetBool("debug")
+	}
+
+	if conf.Dsn == defaultDsn && conf.Driver != defaultDriver {
+		return nil, fmt.Errorf("Error: The driver %v requires a dsn, please set %v or --db.", conf.Driver, EnvDsn)
+	}
+
+	// the default location is owned by vFS, the other locations are chosen by the user.
+	if conf.Dsn == defaultDsn {
+		// refuses to start on an empty database, while the data is still in the working directory.
+		_, err = os.Stat(legacyDsn)
+		if err == nil {
+			return nil, fmt.Errorf("Error: The database ./%v is found, but the default has moved to %v, please move the file or set %v or --db.", legacyDsn, defaultDsn, EnvDsn)
+		}
+		err = os.MkdirAll(filepath.Dir(defaultDsn), 0o755)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return conf, nil
+}
+
+func (conf *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	err = yaml.Unmarshal(data, conf)
+	if err != nil {
+		return fmt.Errorf("Error: The config file %v: %w", path, err)
+	}
+	conf.Path = path
+	return nil
+}
+
+func xdgDir(value, home, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return filepath.Join(home, fallback)
+}
+
+// addGlobalFlags declares the flags consumed by LoadConfig,
+// the root command declares them too, so every command accepts them.
+func addGlobalFlags(flags *pflag.FlagSet) {
+	flags.String("driver", "", "database driver: sqlite, postgres or mysql, overrides "+EnvDriver+" and the config file")
+	flags.String("db", "", "database dsn, overrides "+EnvDsn+" and the config file")
+	flags.Bool("debug", false, "print the sql statements")
+}
+
+func NewConfigCommand(conf *Config) *cobra.Command {
+	const prompt = "config"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "config", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	// overrides checkSchema of the root command, the config is useful to find out which database is outdated.
+	command.PersistentPreRunE = func(cmd *cobra.Command, args []string) error { return nil }
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		path := conf.Path
+		if path == "" {
+			path = "none"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "driver: %v\ndsn: %v\ndebug: %v\nconfig file: %v\n", conf.Driver, conf.Dsn, conf.Debug, path)
+	}
+	return command
+}
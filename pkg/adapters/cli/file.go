@@ -3,6 +3,8 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -45,7 +47,7 @@ func createFile(svc app.FileService) *cobra.Command {
 			"Create %v in %v/%v successfully.\n",
 			req.Filename,
 			username,
-			req.Foldername,
+			strings.TrimPrefix(req.Foldername, "/"),
 		)
 	}
 	return command
@@ -64,8 +66,9 @@ func deleteFile(svc app.FileService) *cobra.Command {
 	command.Run = func(cmd *cobra.Command, args []string) {
 		username := args[0]
 		req := app.DeleteFileParams{
-			Foldername: args[1],
-			Filename:   args[2],
+			Foldername:  args[1],
+			Filename:    args[2],
+			DeletedTime: time.Now(),
 		}
 
 		err := svc.DeleteFile(cmd.Context(), username, req)
@@ -77,14 +80,14 @@ func deleteFile(svc app.FileService) *cobra.Command {
 			"Delete %v in %v/%v successfully.\n",
 			req.Filename,
 			username,
-			req.Foldername,
+			strings.TrimPrefix(req.Foldername, "/"),
 		)
 	}
 	return command
 }
 
 func listFiles(svc app.FileService) *cobra.Command {
-	const prompt = "list-files [username] [foldername] [--sort-name|--sort-created] [asc|desc]"
+	const prompt = "list-files [username] [foldername] [--sort] [key:asc|desc,...] [--sort-name|--sort-created] [asc|desc] [--name-order] [natural|ignore-case] [--filter] [pattern] [--created-after|--created-before] [time] [--limit] [n] [--offset|--cursor] [n|id] [--output] [text|json|yaml|csv|table]"
 
 	command := &cobra.Command{
 		Use: prompt,
@@ -92,32 +95,56 @@ func listFiles(svc app.FileService) *cobra.Command {
 	pkg.CliSetUsage(command, "file", prompt)
 	pkg.CliSetActivePrompt(command, prompt)
 
-	sortByName := command.Flags().String("sort-name", "asc", "sort by file name [asc|desc]")
-	sortByCreated := command.Flags().String("sort-created", "", "sort by created [asc|desc]")
-	command.MarkFlagsMutuallyExclusive("sort-name", "sort-created")
+	sort := addSortFlags(command, "file", "name, created, size and description")
+	list := addListFlags(command)
+	output := addOutputFlag(command)
 
 	command.Args = cobra.ExactArgs(2)
 	command.Run = func(cmd *cobra.Command, args []string) {
 		username := args[0]
 		foldername := args[1]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		sortParams, err := sort.parse()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		filter, page, err := list.parse()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
 		req := app.ListFilesParams{
 			Foldername: foldername,
-			Sort: &app.FileSystemSortParams{
-				ByName:    pkg.SortKind(*sortByName),
-				ByCreated: pkg.SortKind(*sortByCreated),
-			},
+			Sort:       sortParams,
+			Filter:     filter,
+			Page:       page,
 		}
 
 		files, err := svc.ListFiles(cmd.Context(), username, req)
-		if err != nil {
-			if errors.Is(err, app.ErrListFileEmpty) {
-				fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
-				return
-			}
+		isEmpty := errors.Is(err, app.ErrListFileEmpty)
+		if err != nil && !isEmpty {
 			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
 			return
 		}
 
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toFileRecords(files))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
 		renderByText := func(file *app.ViewFile) {
 			if file.Description == "" {
 				fmt.Fprintf(cmd.OutOrStdout(),
@@ -143,6 +170,163 @@ func listFiles(svc app.FileService) *cobra.Command {
 		for _, folder := range files {
 			renderByText(&folder)
 		}
+		renderNextCursor(cmd.OutOrStdout(), page, len(files), files[len(files)-1].Id)
+	}
+	return command
+}
+
+func writeFile(svc app.FileService) *cobra.Command {
+	const prompt = "write-file [username] [foldername] [filename] < stdin"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "file", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(3)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		content, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			return
+		}
+		req := app.WriteFileParams{
+			Foldername:  args[1],
+			Filename:    args[2],
+			Content:     content,
+			UpdatedTime: time.Now(),
+		}
+
+		err = svc.WriteFile(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Write %v in %v/%v successfully.\n",
+			req.Filename,
+			username,
+			strings.TrimPrefix(req.Foldername, "/"),
+		)
+	}
+	return command
+}
+
+func catFile(svc app.FileService) *cobra.Command {
+	const prompt = "cat-file [username] [foldername] [filename]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "file", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(3)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.ReadFileParams{
+			Foldername: args[1],
+			Filename:   args[2],
+		}
+
+		content, err := svc.ReadFile(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		cmd.OutOrStdout().Write(content)
+	}
+	return command
+}
+
+func moveFile(svc app.FileService) *cobra.Command {
+	const prompt = "move-file [username] [src-folder] [filename] [dst-folder] [new-name]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "file", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.RangeArgs(4, 5)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.MoveFileParams{
+			SrcFoldername: args[1],
+			Filename:      args[2],
+			DstFoldername: args[3],
+			UpdatedTime:   time.Now(),
+		}
+		if len(args) >= 5 {
+			req.NewFilename = args[4]
+		}
+
+		err := svc.MoveFile(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		newFilename := req.NewFilename
+		if newFilename == "" {
+			newFilename = req.Filename
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Move %v to %v successfully.\n",
+			filePath(username, req.SrcFoldername, req.Filename),
+			filePath(username, req.DstFoldername, newFilename),
+		)
 	}
 	return command
 }
+
+func copyFile(svc app.FileService) *cobra.Command {
+	const prompt = "copy-file [username] [src-folder] [filename] [dst-folder] [new-name]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "file", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.RangeArgs(4, 5)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.CopyFileParams{
+			SrcFoldername: args[1],
+			Filename:      args[2],
+			DstFoldername: args[3],
+			CreatedTime:   time.Now(),
+		}
+		if len(args) >= 5 {
+			req.NewFilename = args[4]
+		}
+
+		err := svc.CopyFile(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		newFilename := req.NewFilename
+		if newFilename == "" {
+			newFilename = req.Filename
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Copy %v to %v successfully.\n",
+			filePath(username, req.SrcFoldername, req.Filename),
+			filePath(username, req.DstFoldername, newFilename),
+		)
+	}
+	return command
+}
+
+func filePath(username, foldername, filename string) string {
+	foldername = strings.Trim(foldername, "/")
+	if foldername == "" {
+		return username + "/" + filename
+	}
+	return username + "/" + foldername + "/" + filename
+}
@@ -1,7 +1,14 @@
 package cli_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
 )
 
 func Test_createFile(t *testing.T) {
@@ -23,12 +30,24 @@ func Test_createFile(t *testing.T) {
 			hasErr:       false,
 			wantResponse: "Create file5 in user1/folder1 successfully.\n",
 		},
+		{
+			name:         "nested folder",
+			request:      `create-file user1 /folder2/logs error.log`,
+			hasErr:       false,
+			wantResponse: "Create error.log in user1/folder2/logs successfully.\n",
+		},
 		{
 			name:         "The [filename] has already existed.",
 			request:      `create-file user1 folder1 file4`,
 			hasErr:       true,
 			wantResponse: "Error: The file4 has already existed.\n",
 		},
+		{
+			name:         "The [filename] is case-insensitive.",
+			request:      `create-file user1 FOLDER1 File4`,
+			hasErr:       true,
+			wantResponse: "Error: The File4 has already existed.\n",
+		},
 		{
 			name:         "The [username] doesn't exist.",
 			request:      `create-file user4 folder1 file4`,
@@ -116,13 +135,322 @@ file2 qa-file 2024-05-27 23:00:01 folder1 user1
 file1 2024-05-27 23:00:03 folder1 user1
 `,
 		},
+		{
+			name:    "by multiple keys",
+			request: `list-files user1 folder1 --sort description:desc,created:asc`,
+			hasErr:  false,
+			wantResponse: `file2 qa-file 2024-05-27 23:00:01 folder1 user1
+file3 2024-05-27 23:00:02 folder1 user1
+file1 2024-05-27 23:00:03 folder1 user1
+`,
+		},
+		{
+			name:    "by name in natural order",
+			request: `list-files user1 folder1 --sort name:desc --name-order natural,ignore-case`,
+			hasErr:  false,
+			wantResponse: `file3 2024-05-27 23:00:02 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+file1 2024-05-27 23:00:03 folder1 user1
+`,
+		},
+		{
+			name:         "The sort key is invalid.",
+			request:      `list-files user1 folder1 --sort owner:desc`,
+			hasErr:       true,
+			wantResponse: "Error: The sort owner contain invalid chars.\n",
+		},
+		{
+			name:         "The sort-name is invalid.",
+			request:      `list-files user1 folder1 --sort-name up`,
+			hasErr:       true,
+			wantResponse: "Error: The sort-name up contain invalid chars.\n",
+		},
+		{
+			name:         "The name order is invalid.",
+			request:      `list-files user1 folder1 --name-order random`,
+			hasErr:       true,
+			wantResponse: "Error: The name order random contain invalid chars.\n",
+		},
+		{
+			name:    "nested folder",
+			request: `list-files user1 /folder2/logs`,
+			hasErr:  false,
+			wantResponse: `app.log 2024-05-27 23:00:04 logs user1
+`,
+		},
+		{
+			name:    "filter by glob",
+			request: `list-files user1 folder1 --filter "FILE*2"`,
+			hasErr:  false,
+			wantResponse: `file2 qa-file 2024-05-27 23:00:01 folder1 user1
+`,
+		},
+		{
+			name:         "filter by substring",
+			request:      `list-files user1 folder1 --filter "gopher book"`,
+			hasErr:       false,
+			wantResponse: "Warning: The folder is empty.\n",
+		},
+		{
+			name:    "filter by created",
+			request: `list-files user1 folder1 --created-after 2024-05-27T23:00:01+08:00 --created-before 2024-05-27T23:00:03+08:00`,
+			hasErr:  false,
+			wantResponse: `file3 2024-05-27 23:00:02 folder1 user1
+`,
+		},
+		{
+			name:         "The created-after is invalid.",
+			request:      `list-files user1 folder1 --created-after yesterday`,
+			hasErr:       true,
+			wantResponse: "Error: The created-after yesterday contain invalid chars.\n",
+		},
+		{
+			name:    "limit",
+			request: `list-files user1 folder1 --limit 2`,
+			hasErr:  false,
+			wantResponse: `file1 2024-05-27 23:00:03 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+Next page: --cursor 01HYYMMTX8F4D2BESDCAD2YXS5
+`,
+		},
+		{
+			name:    "cursor",
+			request: `list-files user1 folder1 --limit 2 --cursor 01HYYMMTX8F4D2BESDCAD2YXS5`,
+			hasErr:  false,
+			wantResponse: `file3 2024-05-27 23:00:02 folder1 user1
+`,
+		},
+		{
+			name:    "offset",
+			request: `list-files user1 folder1 --sort-created desc --offset 1`,
+			hasErr:  false,
+			wantResponse: `file3 2024-05-27 23:00:02 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+`,
+		},
+		{
+			name:         "The cursor doesn't belong to the folder.",
+			request:      `list-files user1 folder1 --cursor 01HYYMP6QK7D3S9VW0R5TB8XEA`,
+			hasErr:       true,
+			wantResponse: "Error: The cursor 01HYYMP6QK7D3S9VW0R5TB8XEA contain invalid chars.\n",
+		},
 		{
 			name:         "The folder is empty.",
 			request:      `list-files user1 folder3 --sort-name asc`,
 			hasErr:       false,
 			wantResponse: "Warning: The folder is empty.\n",
 		},
+		{
+			name:    "output json",
+			request: `list-files user1 /folder2/logs --output json`,
+			hasErr:  false,
+			wantResponse: `[
+  {
+    "filename": "app.log",
+    "description": "",
+    "created_time": "2024-05-27T23:00:04+08:00",
+    "foldername": "logs",
+    "username": "user1"
+  }
+]
+`,
+		},
+		{
+			name:    "output csv",
+			request: `list-files user1 folder1 --sort-name desc --output csv`,
+			hasErr:  false,
+			wantResponse: `filename,description,created_time,foldername,username
+file3,,2024-05-27T23:00:02+08:00,folder1,user1
+file2,qa-file,2024-05-27T23:00:01+08:00,folder1,user1
+file1,,2024-05-27T23:00:03+08:00,folder1,user1
+`,
+		},
+		{
+			name:         "output yaml in empty folder",
+			request:      `list-files user1 folder3 --output yaml`,
+			hasErr:       false,
+			wantResponse: "[]\n",
+		},
 	}
 
 	fixture(t, testcase)
 }
+
+func Test_moveFile(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `move-file user1 folder1 file2 /folder2/logs`,
+			hasErr:       false,
+			wantResponse: "Move user1/folder1/file2 to user1/folder2/logs/file2 successfully.\n",
+		},
+		{
+			name:    "check move keeps created time and description",
+			request: `list-files user1 /folder2/logs`,
+			hasErr:  false,
+			wantResponse: `app.log 2024-05-27 23:00:04 logs user1
+file2 qa-file 2024-05-27 23:00:01 logs user1
+`,
+		},
+		{
+			name:         "with new name",
+			request:      `move-file user1 folder1 file3 folder3 file9`,
+			hasErr:       false,
+			wantResponse: "Move user1/folder1/file3 to user1/folder3/file9 successfully.\n",
+		},
+		{
+			name:         "The [new-name] has already existed.",
+			request:      `move-file user1 folder1 file1 /folder2/logs APP.log`,
+			hasErr:       true,
+			wantResponse: "Error: The APP.log has already existed.\n",
+		},
+		{
+			name:         "The [dst-folder] doesn't exist.",
+			request:      `move-file user1 folder1 file1 folder5`,
+			hasErr:       true,
+			wantResponse: "Error: The folder5 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_copyFile(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `copy-file user1 folder1 file2 folder1 file2-copy`,
+			hasErr:       false,
+			wantResponse: "Copy user1/folder1/file2 to user1/folder1/file2-copy successfully.\n",
+		},
+		{
+			name:         "The [filename] has already existed.",
+			request:      `copy-file user1 folder1 file2 folder1`,
+			hasErr:       true,
+			wantResponse: "Error: The file2 has already existed.\n",
+		},
+		{
+			name:         "The [filename] doesn't exist.",
+			request:      `copy-file user1 folder1 file5 folder3`,
+			hasErr:       true,
+			wantResponse: "Error: The file5 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_writeFile(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		stdin        string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `write-file user1 folder1 file1`,
+			stdin:        "hello gopher\n",
+			hasErr:       false,
+			wantResponse: "Write file1 in user1/folder1 successfully.\n",
+		},
+		{
+			name:         "check content",
+			request:      `cat-file user1 folder1 file1`,
+			hasErr:       false,
+			wantResponse: "hello gopher\n",
+		},
+		{
+			name:         "overwrite",
+			request:      `write-file user1 folder1 file1`,
+			stdin:        "bye",
+			hasErr:       false,
+			wantResponse: "Write file1 in user1/folder1 successfully.\n",
+		},
+		{
+			name:         "check overwrite content",
+			request:      `cat-file user1 folder1 file1`,
+			hasErr:       false,
+			wantResponse: "bye",
+		},
+		{
+			name:         "copy content",
+			request:      `copy-file user1 folder1 file1 folder3`,
+			hasErr:       false,
+			wantResponse: "Copy user1/folder1/file1 to user1/folder3/file1 successfully.\n",
+		},
+		{
+			name:         "check copy content",
+			request:      `cat-file user1 folder3 file1`,
+			hasErr:       false,
+			wantResponse: "bye",
+		},
+		{
+			name:         "move keeps content",
+			request:      `move-file user1 folder3 file1 folder2 file1.bak`,
+			hasErr:       false,
+			wantResponse: "Move user1/folder3/file1 to user1/folder2/file1.bak successfully.\n",
+		},
+		{
+			name:         "check move content",
+			request:      `cat-file user1 folder2 file1.bak`,
+			hasErr:       false,
+			wantResponse: "bye",
+		},
+		{
+			name:         "never written",
+			request:      `cat-file user1 folder1 file2`,
+			hasErr:       false,
+			wantResponse: "",
+		},
+		{
+			name:         "The [filename] doesn't exist.",
+			request:      `write-file user1 folder1 file5`,
+			stdin:        "hello",
+			hasErr:       true,
+			wantResponse: "Error: The file5 doesn't exist.\n",
+		},
+		{
+			name:         "The [foldername] doesn't exist.",
+			request:      `cat-file user1 folder5 file1`,
+			hasErr:       true,
+			wantResponse: "Error: The folder5 doesn't exist.\n",
+		},
+	}
+
+	setup()
+	defer teardown()
+
+	for _, tt := range testcase {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			spyStdout := &bytes.Buffer{}
+			spyStderr := &bytes.Buffer{}
+
+			root := inject.NewRootCommand(sut)
+			root.SetIn(strings.NewReader(tt.stdin))
+			root.SetOut(spyStdout)
+			root.SetErr(spyStderr)
+			root.SetArgs(pkg.CliParse(tt.request))
+
+			root.Execute()
+
+			actualResponse := spyStdout.String()
+			if tt.hasErr {
+				actualResponse = spyStderr.String()
+			}
+			require.Equal(t, tt.wantResponse, actualResponse)
+		})
+	}
+}
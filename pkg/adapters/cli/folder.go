@@ -57,7 +57,8 @@ func deleteFolder(svc app.FolderService) *cobra.Command {
 	command.Run = func(cmd *cobra.Command, args []string) {
 		username := args[0]
 		req := app.DeleteFolderParams{
-			Foldername: args[1],
+			Foldername:  args[1],
+			DeletedTime: time.Now(),
 		}
 
 		err := svc.DeleteFolder(cmd.Context(), username, req)
@@ -71,7 +72,7 @@ func deleteFolder(svc app.FolderService) *cobra.Command {
 }
 
 func listFolders(svc app.FolderService) *cobra.Command {
-	const prompt = "list-folders [username] [--sort-name|--sort-created] [asc|desc]"
+	const prompt = "list-folders [username] [foldername]? [--sort] [key:asc|desc,...] [--sort-name|--sort-created] [asc|desc] [--name-order] [natural|ignore-case] [--filter] [pattern] [--created-after|--created-before] [time] [--limit] [n] [--offset|--cursor] [n|id] [--output] [text|json|yaml|csv|table]"
 
 	command := &cobra.Command{
 		Use: prompt,
@@ -79,30 +80,59 @@ func listFolders(svc app.FolderService) *cobra.Command {
 	pkg.CliSetUsage(command, "folder", prompt)
 	pkg.CliSetActivePrompt(command, prompt)
 
-	sortByName := command.Flags().String("sort-name", "asc", "sort by folder name [asc|desc]")
-	sortByCreated := command.Flags().String("sort-created", "", "sort by created  [asc|desc]")
-	command.MarkFlagsMutuallyExclusive("sort-name", "sort-created")
+	sort := addSortFlags(command, "folder", "name, created and description")
+	list := addListFlags(command)
+	output := addOutputFlag(command)
 
-	command.Args = cobra.ExactArgs(1)
+	command.Args = cobra.RangeArgs(1, 2)
 	command.Run = func(cmd *cobra.Command, args []string) {
 		username := args[0]
+		foldername := "/"
+		if len(args) >= 2 {
+			foldername = args[1]
+		}
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		sortParams, err := sort.parse()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		filter, page, err := list.parse()
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
 		req := app.ListFoldersParams{
-			Sort: &app.FileSystemSortParams{
-				ByName:    pkg.SortKind(*sortByName),
-				ByCreated: pkg.SortKind(*sortByCreated),
-			},
+			Foldername: foldername,
+			Sort:       sortParams,
+			Filter:     filter,
+			Page:       page,
 		}
 
 		folders, err := svc.ListFolders(cmd.Context(), username, req)
-		if err != nil {
-			if errors.Is(err, app.ErrListFolderEmpty) {
-				fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
-				return
-			}
+		isEmpty := errors.Is(err, app.ErrListFolderEmpty)
+		if err != nil && !isEmpty {
 			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
 			return
 		}
 
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toFolderRecords(folders))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
 		renderByText := func(folder *app.ViewFolder) {
 			if folder.Description == "" {
 				fmt.Fprintf(cmd.OutOrStdout(),
@@ -126,6 +156,7 @@ func listFolders(svc app.FolderService) *cobra.Command {
 		for _, folder := range folders {
 			renderByText(&folder)
 		}
+		renderNextCursor(cmd.OutOrStdout(), page, len(folders), folders[len(folders)-1].Id)
 	}
 	return command
 }
@@ -145,6 +176,7 @@ func renameFolder(svc app.FolderService) *cobra.Command {
 		req := app.RenameFolderParams{
 			OldFolderName: args[1],
 			NewFolderName: args[2],
+			UpdatedTime:   time.Now(),
 		}
 
 		err := svc.RenameFolder(cmd.Context(), username, req)
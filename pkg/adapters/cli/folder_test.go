@@ -19,9 +19,21 @@ func Test_createFolder(t *testing.T) {
 		},
 		{
 			name:         "with whitespace char",
-			request:      `create-folder user2 folder2 "this-is-folder 2"`,
+			request:      `create-folder user1 folder6 "this-is-folder 6"`,
 			hasErr:       false,
-			wantResponse: "Create folder2 successfully.\n",
+			wantResponse: "Create folder6 successfully.\n",
+		},
+		{
+			name:         "nested folder",
+			request:      "create-folder user1 folder2/logs/2024",
+			hasErr:       false,
+			wantResponse: "Create folder2/logs/2024 successfully.\n",
+		},
+		{
+			name:         "The parent of [foldername] doesn't exist.",
+			request:      "create-folder user1 /folder5/logs",
+			hasErr:       true,
+			wantResponse: "Error: The /folder5 doesn't exist.\n",
 		},
 		{
 			name:         "The [foldername] has already existed.",
@@ -65,6 +77,18 @@ func Test_deleteFolder(t *testing.T) {
 			hasErr:       false,
 			wantResponse: "Create folder1 successfully.\n",
 		},
+		{
+			name:         "nested folder",
+			request:      `delete-folder user1 /folder2/logs`,
+			hasErr:       false,
+			wantResponse: "Delete /folder2/logs successfully.\n",
+		},
+		{
+			name:         "check delete nested folder",
+			request:      `list-folders user1 folder2`,
+			hasErr:       false,
+			wantResponse: "Warning: The folder2 doesn't have any folders.\n",
+		},
 		{
 			name:         "The [foldername] doesn't exist.",
 			request:      `delete-folder user1 folder4`,
@@ -116,12 +140,119 @@ folder2 qa-folder 2024-05-27 23:00:01 user1
 folder1 2024-05-27 23:00:03 user1
 `,
 		},
+		{
+			name:    "filter and limit",
+			request: `list-folders user1 --filter folder --sort-created asc --limit 2`,
+			hasErr:  false,
+			wantResponse: `folder2 qa-folder 2024-05-27 23:00:01 user1
+folder3 2024-05-27 23:00:02 user1
+Next page: --cursor 01HYXD0GV43XKBZ7Y1YDK7QDBQ
+`,
+		},
+		{
+			name:         "The filter matches nothing.",
+			request:      `list-folders user1 --filter "gopher book"`,
+			hasErr:       false,
+			wantResponse: "Warning: The user1 doesn't have any folders.\n",
+		},
+		{
+			name:         "The limit is negative.",
+			request:      `list-folders user1 --limit -1`,
+			hasErr:       true,
+			wantResponse: "Error: The limit -1 contain invalid chars.\n",
+		},
+		{
+			name:         "nested folder",
+			request:      `list-folders user1 /folder2`,
+			hasErr:       false,
+			wantResponse: "logs qa-logs 2024-05-27 23:00:04 user1\n",
+		},
+		{
+			name:         "The [foldername] doesn't exist.",
+			request:      `list-folders user1 /folder2/logs/2024`,
+			hasErr:       true,
+			wantResponse: "Error: The /folder2/logs/2024 doesn't exist.\n",
+		},
+		{
+			name:    "output json",
+			request: `list-folders user1 --sort-created desc --output json`,
+			hasErr:  false,
+			wantResponse: `[
+  {
+    "foldername": "folder1",
+    "description": "",
+    "created_time": "2024-05-27T23:00:03+08:00",
+    "username": "user1"
+  },
+  {
+    "foldername": "folder3",
+    "description": "",
+    "created_time": "2024-05-27T23:00:02+08:00",
+    "username": "user1"
+  },
+  {
+    "foldername": "folder2",
+    "description": "qa-folder",
+    "created_time": "2024-05-27T23:00:01+08:00",
+    "username": "user1"
+  }
+]
+`,
+		},
+		{
+			name:    "output yaml",
+			request: `list-folders user1 folder2 -o yaml`,
+			hasErr:  false,
+			wantResponse: `- foldername: logs
+  description: qa-logs
+  created_time: "2024-05-27T23:00:04+08:00"
+  username: user1
+`,
+		},
+		{
+			name:    "output csv",
+			request: `list-folders user1 --output=csv`,
+			hasErr:  false,
+			wantResponse: `foldername,description,created_time,username
+folder1,,2024-05-27T23:00:03+08:00,user1
+folder2,qa-folder,2024-05-27T23:00:01+08:00,user1
+folder3,,2024-05-27T23:00:02+08:00,user1
+`,
+		},
+		{
+			name:    "output table",
+			request: `list-folders user1 --output table`,
+			hasErr:  false,
+			wantResponse: `FOLDERNAME  DESCRIPTION  CREATED_TIME               USERNAME
+folder1                  2024-05-27T23:00:03+08:00  user1
+folder2     qa-folder    2024-05-27T23:00:01+08:00  user1
+folder3                  2024-05-27T23:00:02+08:00  user1
+`,
+		},
+		{
+			name:         "The [output] contain invalid chars.",
+			request:      `list-folders user1 --output xml`,
+			hasErr:       true,
+			wantResponse: "Error: The output xml contain invalid chars.\n",
+		},
+		{
+			name:         "user2 logs in.",
+			request:      `login user2 user2-password`,
+			hasErr:       false,
+			wantResponse: "Login user2 successfully.\n",
+		},
 		{
 			name:         "The [username] doesn't have any folders.",
 			request:      `list-folders user2 --sort-name asc`,
 			hasErr:       false,
 			wantResponse: "Warning: The user2 doesn't have any folders.\n",
 		},
+		{
+			name:         "output json without any folders",
+			request:      `list-folders user2 --output json`,
+			hasErr:       false,
+			wantResponse: "[]\n",
+		},
 	}
 
 	fixture(t, testcase)
@@ -156,6 +287,19 @@ folder2 qa-folder 2024-05-27 23:00:01 user1
 			wantResponse: `file1 2024-05-27 23:00:03 folder isCool user1
 file3 2024-05-27 23:00:02 folder isCool user1
 file2 qa-file 2024-05-27 23:00:01 folder isCool user1
+`,
+		},
+		{
+			name:         "nested folder",
+			request:      `rename-folder user1 folder2/logs archive`,
+			hasErr:       false,
+			wantResponse: "Rename folder2/logs to archive successfully.\n",
+		},
+		{
+			name:    "check rename for nested folder",
+			request: `list-files user1 folder2/archive`,
+			hasErr:  false,
+			wantResponse: `app.log 2024-05-27 23:00:04 archive user1
 `,
 		},
 		{
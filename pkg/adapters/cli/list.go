@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// sortFlags are shared by list-folders and list-files,
+// --sort-name and --sort-created are the shorthands of --sort with a single key.
+type sortFlags struct {
+	keys      *string
+	byName    *string
+	byCreated *string
+	nameOrder *string
+}
+
+func addSortFlags(command *cobra.Command, kind string, keys string) *sortFlags {
+	flags := &sortFlags{
+		keys:      command.Flags().String("sort", "", "sort by the keys one after another, such as created:desc,name:asc, the keys are "+keys),
+		byName:    command.Flags().String("sort-name", "", "sort by "+kind+" name [asc|desc], the default order is name asc"),
+		byCreated: command.Flags().String("sort-created", "", "sort by created [asc|desc]"),
+		nameOrder: command.Flags().String("name-order", "", "compare name and description [natural|ignore-case], or both separated by a comma, natural sorts file2 before file10"),
+	}
+	command.MarkFlagsMutuallyExclusive("sort", "sort-name", "sort-created")
+	return flags
+}
+
+func (flags *sortFlags) parse() (*app.FileSystemSortParams, error) {
+	keys := *flags.keys
+	for _, shorthand := range []struct {
+		flag  string
+		key   string
+		value string
+	}{
+		{"sort-name", app.SortKeyName, *flags.byName},
+		{"sort-created", app.SortKeyCreated, *flags.byCreated},
+	} {
+		if shorthand.value == "" {
+			continue
+		}
+		if pkg.SortKind(strings.ToLower(shorthand.value)).Validate() != nil {
+			return nil, fmt.Errorf("Error: The %v %v %w", shorthand.flag, shorthand.value, app.ErrInvalidParams)
+		}
+		keys = shorthand.key + ":" + shorthand.value
+	}
+	return app.ParseFileSystemSortParams(keys, *flags.nameOrder)
+}
+
+// listFlags are shared by list-folders and list-files.
+type listFlags struct {
+	filter        *string
+	createdAfter  *string
+	createdBefore *string
+	limit         *int
+	offset        *int
+	cursor        *string
+}
+
+func addListFlags(command *cobra.Command) *listFlags {
+	flags := &listFlags{
+		filter:        command.Flags().String("filter", "", "filter by name, a glob with * and ? or a substring, case-insensitive"),
+		createdAfter:  command.Flags().String("created-after", "", "filter by created after the time, such as 2024-05-27 or 2024-05-27T23:00:00+08:00"),
+		createdBefore: command.Flags().String("created-before", "", "filter by created before the time, such as 2024-05-27 or 2024-05-27T23:00:00+08:00"),
+		limit:         command.Flags().Int("limit", 0, "the max number of items, 0 means no limit"),
+		offset:        command.Flags().Int("offset", 0, "skip the number of items"),
+		cursor:        command.Flags().String("cursor", "", "start after the item with the id, which is printed below a full page"),
+	}
+	command.MarkFlagsMutuallyExclusive("offset", "cursor")
+	return flags
+}
+
+func (flags *listFlags) parse() (app.ListFilterParams, app.PageParams, error) {
+	filter := app.ListFilterParams{Name: *flags.filter}
+
+	var err error
+	if *flags.createdAfter != "" {
+		filter.CreatedAfter, err = pkg.ParseTime(*flags.createdAfter, time.Local)
+		if err != nil {
+			return filter, app.PageParams{}, fmt.Errorf("Error: The created-after %v %w", *flags.createdAfter, app.ErrInvalidParams)
+		}
+	}
+	if *flags.createdBefore != "" {
+		filter.CreatedBefore, err = pkg.ParseTime(*flags.createdBefore, time.Local)
+		if err != nil {
+			return filter, app.PageParams{}, fmt.Errorf("Error: The created-before %v %w", *flags.createdBefore, app.ErrInvalidParams)
+		}
+	}
+
+	page := app.PageParams{
+		Limit:  *flags.limit,
+		Offset: *flags.offset,
+		Cursor: *flags.cursor,
+	}
+	return filter, page, page.Validate()
+}
+
+// renderNextCursor tells how to fetch the next page when the page is full.
+func renderNextCursor(w io.Writer, page app.PageParams, count int, lastId string) {
+	if page.Limit > 0 && count == page.Limit {
+		fmt.Fprintf(w, "Next page: --cursor %v\n", lastId)
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+// checkSchema refuses to run the commands against a database
+// whose schema isn't the version this binary is built for.
+func checkSchema(migrator *pkg.Migrator) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := migrator.Init(cmd.Context())
+		if err != nil {
+			cmd.SilenceUsage = true
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func migrate(migrator *pkg.Migrator) *cobra.Command {
+	const prompt = "migrate [up|down|status]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "migrate", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	// overrides checkSchema of the root command, migrate is how an outdated schema gets upgraded.
+	command.PersistentPreRunE = func(cmd *cobra.Command, args []string) error { return nil }
+
+	command.ValidArgs = []string{"up", "down", "status"}
+	command.Args = cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		switch args[0] {
+		case "up":
+			migrations, err := migrator.Up(cmd.Context())
+			for _, migration := range migrations {
+				fmt.Fprintf(cmd.OutOrStdout(), "Apply %v successfully.\n", migration)
+			}
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+				return
+			}
+			if len(migrations) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Warning: The schema is up to date.\n")
+			}
+
+		case "down":
+			migration, err := migrator.Down(cmd.Context())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+				return
+			}
+			if migration == nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Warning: The schema doesn't have any migrations.\n")
+				return
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Revert %v successfully.\n", migration)
+
+		case "status":
+			status, err := migrator.Status(cmd.Context())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+				return
+			}
+			for _, s := range status {
+				if s.AppliedTime == nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "%v pending\n", s.Migration)
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(),
+					"%v applied %v\n",
+					s.Migration,
+					s.AppliedTime.Format("2006-01-02 15:04:05"),
+				)
+			}
+		}
+	}
+	return command
+}
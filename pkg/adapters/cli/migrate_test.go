@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_migrate(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "up to date",
+			request:      `migrate up`,
+			hasErr:       false,
+			wantResponse: "Warning: The schema is up to date.\n",
+		},
+		{
+			name:         "down",
+			request:      `migrate down`,
+			hasErr:       false,
+			wantResponse: "Revert 0014_outbox_retry successfully.\n",
+		},
+		{
+			name:         "The schema is outdated.",
+			request:      `list-folders user1`,
+			hasErr:       true,
+			wantResponse: "Error: The schema version 13 is outdated, please run `vFS migrate up`.\n",
+		},
+		{
+			name:         "up",
+			request:      `migrate up`,
+			hasErr:       false,
+			wantResponse: "Apply 0014_outbox_retry successfully.\n",
+		},
+		{
+			name:         "data is kept",
+			request:      `list-files user1 folder2/logs`,
+			hasErr:       false,
+			wantResponse: "app.log 2024-05-27 23:00:04 logs user1\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputJson  outputFormat = "json"
+	outputYaml  outputFormat = "yaml"
+	outputCsv   outputFormat = "csv"
+	outputTable outputFormat = "table"
+)
+
+func addOutputFlag(command *cobra.Command) *string {
+	return command.Flags().StringP("output", "o", string(outputText), "output format [text|json|yaml|csv|table]")
+}
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	format := outputFormat(strings.ToLower(value))
+	switch format {
+	case outputText, outputJson, outputYaml, outputCsv, outputTable:
+		return format, nil
+	}
+	return "", fmt.Errorf("Error: The output %v %w", value, app.ErrInvalidParams)
+}
+
+// record is the structured form of a view model.
+// Its field names are stable, because scripts depend on them.
+type record interface {
+	header() []string
+	row() []string
+}
+
+type userRecord struct {
+	Username    string `json:"username" yaml:"username"`
+	CreatedTime string `json:"created_time" yaml:"created_time"`
+}
+
+func toUserRecords(users []app.ViewUser) []userRecord {
+	records := make([]userRecord, len(users))
+	for i, user := range users {
+		records[i] = userRecord{
+			Username:    user.Username,
+			CreatedTime: user.CreatedTime.Format(time.RFC3339),
+		}
+	}
+	return records
+}
+
+func (userRecord) header() []string {
+	return []string{"username", "created_time"}
+}
+
+func (r userRecord) row() []string {
+	return []string{r.Username, r.CreatedTime}
+}
+
+type folderRecord struct {
+	Foldername  string `json:"foldername" yaml:"foldername"`
+	Description string `json:"description" yaml:"description"`
+	CreatedTime string `json:"created_time" yaml:"created_time"`
+	Username    string `json:"username" yaml:"username"`
+}
+
+func toFolderRecords(folders []app.ViewFolder) []folderRecord {
+	records := make([]folderRecord, len(folders))
+	for i, folder := range folders {
+		records[i] = folderRecord{
+			Foldername:  folder.Fodlername,
+			Description: folder.Description,
+			CreatedTime: folder.CreatedTime.Format(time.RFC3339),
+			Username:    folder.Username,
+		}
+	}
+	return records
+}
+
+func (folderRecord) header() []string {
+	return []string{"foldername", "description", "created_time", "username"}
+}
+
+func (r folderRecord) row() []string {
+	return []string{r.Foldername, r.Description, r.CreatedTime, r.Username}
+}
+
+type fileRecord struct {
+	Filename    string `json:"filename" yaml:"filename"`
+	Description string `json:"description" yaml:"description"`
+	CreatedTime string `json:"created_time" yaml:"created_time"`
+	Foldername  string `json:"foldername" yaml:"foldername"`
+	Username    string `json:"username" yaml:"username"`
+}
+
+func toFileRecords(files []app.ViewFile) []fileRecord {
+	records := make([]fileRecord, len(files))
+	for i, file := range files {
+		records[i] = fileRecord{
+			Filename:    file.Filename,
+			Description: file.Description,
+			CreatedTime: file.CreatedTime.Format(time.RFC3339),
+			Foldername:  file.Fodlername,
+			Username:    file.Username,
+		}
+	}
+	return records
+}
+
+func (fileRecord) header() []string {
+	return []string{"filename", "description", "created_time", "foldername", "username"}
+}
+
+func (r fileRecord) row() []string {
+	return []string{r.Filename, r.Description, r.CreatedTime, r.Foldername, r.Username}
+}
+
+type trashRecord struct {
+	Id          string `json:"id" yaml:"id"`
+	Kind        string `json:"kind" yaml:"kind"`
+	Path        string `json:"path" yaml:"path"`
+	DeletedTime string `json:"deleted_time" yaml:"deleted_time"`
+	Username    string `json:"username" yaml:"username"`
+}
+
+func toTrashRecords(items []app.ViewTrashItem) []trashRecord {
+	records := make([]trashRecord, len(items))
+	for i, item := range items {
+		records[i] = trashRecord{
+			Id:          item.Id,
+			Kind:        item.Kind,
+			Path:        item.Path,
+			DeletedTime: item.DeletedTime.Format(time.RFC3339),
+			Username:    item.Username,
+		}
+	}
+	return records
+}
+
+func (trashRecord) header() []string {
+	return []string{"id", "kind", "path", "deleted_time", "username"}
+}
+
+func (r trashRecord) row() []string {
+	return []string{r.Id, r.Kind, r.Path, r.DeletedTime, r.Username}
+}
+
+type sharedFolderRecord struct {
+	Owner      string `json:"owner" yaml:"owner"`
+	Path       string `json:"path" yaml:"path"`
+	Permission string `json:"permission" yaml:"permission"`
+	SharedTime string `json:"shared_time" yaml:"shared_time"`
+	Username   string `json:"username" yaml:"username"`
+}
+
+func toSharedFolderRecords(folders []app.ViewSharedFolder) []sharedFolderRecord {
+	records := make([]sharedFolderRecord, len(folders))
+	for i, folder := range folders {
+		records[i] = sharedFolderRecord{
+			Owner:      folder.Owner,
+			Path:       folder.Path,
+			Permission: folder.Permission,
+			SharedTime: folder.SharedTime.Format(time.RFC3339),
+			Username:   folder.Username,
+		}
+	}
+	return records
+}
+
+func (sharedFolderRecord) header() []string {
+	return []string{"owner", "path", "permission", "shared_time", "username"}
+}
+
+func (r sharedFolderRecord) row() []string {
+	return []string{r.Owner, r.Path, r.Permission, r.SharedTime, r.Username}
+}
+
+type auditRecord struct {
+	Id          string `json:"id" yaml:"id"`
+	CreatedTime string `json:"created_time" yaml:"created_time"`
+	Actor       string `json:"actor" yaml:"actor"`
+	Action      string `json:"action" yaml:"action"`
+	TargetId    string `json:"target_id" yaml:"target_id"`
+	ParentId    string `json:"parent_id" yaml:"parent_id"`
+	Before      string `json:"before" yaml:"before"`
+	After       string `json:"after" yaml:"after"`
+	Username    string `json:"username" yaml:"username"`
+}
+
+func toAuditRecords(entries []app.ViewAuditEntry) []auditRecord {
+	records := make([]auditRecord, len(entries))
+	for i, entry := range entries {
+		records[i] = auditRecord{
+			Id:          entry.Id,
+			CreatedTime: entry.CreatedTime.Format(time.RFC3339),
+			Actor:       auditActor(entry.Actor),
+			Action:      entry.Action,
+			TargetId:    entry.TargetId,
+			ParentId:    entry.ParentId,
+			Before:      entry.Before,
+			After:       entry.After,
+			Username:    entry.Username,
+		}
+	}
+	return records
+}
+
+func (auditRecord) header() []string {
+	return []string{"id", "created_time", "actor", "action", "target_id", "parent_id", "before", "after", "username"}
+}
+
+func (r auditRecord) row() []string {
+	return []string{r.Id, r.CreatedTime, r.Actor, r.Action, r.TargetId, r.ParentId, r.Before, r.After, r.Username}
+}
+
+type webhookRecord struct {
+	Id          string `json:"id" yaml:"id"`
+	Url         string `json:"url" yaml:"url"`
+	Events      string `json:"events" yaml:"events"`
+	CreatedTime string `json:"created_time" yaml:"created_time"`
+	Username    string `json:"username" yaml:"username"`
+}
+
+func toWebhookRecords(hooks []app.ViewWebhook) []webhookRecord {
+	records := make([]webhookRecord, len(hooks))
+	for i, hook := range hooks {
+		records[i] = webhookRecord{
+			Id:          hook.Id,
+			Url:         hook.Url,
+			Events:      webhookEvents(hook.Events),
+			CreatedTime: hook.CreatedTime.Format(time.RFC3339),
+			Username:    hook.Username,
+		}
+	}
+	return records
+}
+
+func (webhookRecord) header() []string {
+	return []string{"id", "url", "events", "created_time", "username"}
+}
+
+func (r webhookRecord) row() []string {
+	return []string{r.Id, r.Url, r.Events, r.CreatedTime, r.Username}
+}
+
+type deliveryRecord struct {
+	Id            string `json:"id" yaml:"id"`
+	WebhookId     string `json:"webhook_id" yaml:"webhook_id"`
+	EventId       string `json:"event_id" yaml:"event_id"`
+	Event         string `json:"event" yaml:"event"`
+	Url           string `json:"url" yaml:"url"`
+	Attempts      int    `json:"attempts" yaml:"attempts"`
+	StatusCode    int    `json:"status_code" yaml:"status_code"`
+	Error         string `json:"error" yaml:"error"`
+	DeliveredTime string `json:"delivered_time" yaml:"delivered_time"`
+}
+
+func toDeliveryRecords(deliveries []app.ViewWebhookDelivery) []deliveryRecord {
+	records := make([]deliveryRecord, len(deliveries))
+	for i, delivery := range deliveries {
+		records[i] = deliveryRecord{
+			Id:            delivery.Id,
+			WebhookId:     delivery.WebhookId,
+			EventId:       delivery.EventId,
+			Event:         delivery.Event,
+			Url:           delivery.Url,
+			Attempts:      delivery.Attempts,
+			StatusCode:    delivery.StatusCode,
+			Error:         delivery.Error,
+			DeliveredTime: delivery.DeliveredTime.Format(time.RFC3339),
+		}
+	}
+	return records
+}
+
+func (deliveryRecord) header() []string {
+	return []string{"id", "webhook_id", "event_id", "event", "url", "attempts", "status_code", "error", "delivered_time"}
+}
+
+func (r deliveryRecord) row() []string {
+	return []string{r.Id, r.WebhookId, r.EventId, r.Event, r.Url, strconv.Itoa(r.Attempts), strconv.Itoa(r.StatusCode), r.Error, r.DeliveredTime}
+}
+
+// renderRecords writes records in a structured format, it doesn't handle outputText.
+func renderRecords[T record](w io.Writer, format outputFormat, records []T) error {
+	switch format {
+	case outputJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+
+	case outputYaml:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		err := encoder.Encode(records)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+
+	case outputCsv:
+		writer := csv.NewWriter(w)
+		var zero T
+		writer.Write(zero.header())
+		for _, r := range records {
+			writer.Write(r.row())
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case outputTable:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		var zero T
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(zero.header(), "\t")))
+		for _, r := range records {
+			fmt.Fprintln(writer, strings.Join(r.row(), "\t"))
+		}
+		return writer.Flush()
+	}
+
+	return fmt.Errorf("Error: The output %v %w", format, app.ErrInvalidParams)
+}
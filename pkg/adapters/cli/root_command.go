@@ -2,38 +2,99 @@ package cli
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/KScaesar/IsCoolLab2024/pkg"
 	"github.com/KScaesar/IsCoolLab2024/pkg/app"
 )
 
-func NewRootCommand(svc *app.Service) *Command {
+func NewRootCommand(svc *app.Service, handler http.Handler, migrator *pkg.Migrator) *Command {
 	root := &cobra.Command{
 		Use:                "vFS",
 		Short:              "A Simple Virtual File System",
 		DisableSuggestions: true,
 		SilenceErrors:      true,
 	}
+	addGlobalFlags(root.PersistentFlags())
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		err := checkSchema(migrator)(cmd, args)
+		if err != nil {
+			return err
+		}
+		return authenticate(svc.AuthService)(cmd, args)
+	}
 	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
 		fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", cmd.UsageString())
 		return nil
 	})
 
 	// user
-	root.AddCommand(registerUser(svc.UserService))
+	root.AddCommand(withPassword(registerUser(svc.UserService), 1))
+	root.AddCommand(listUsers(svc.UserService))
+	root.AddCommand(deleteUser(svc.UserService))
+	root.AddCommand(renameUser(svc.UserService))
+	root.AddCommand(withCurrentUser(userInfo(svc.UserService)))
+	root.AddCommand(setQuota(svc.UserService))
+	root.AddCommand(withCurrentUser(showQuota(svc.UserService)))
+	root.AddCommand(setRole(svc.UserService))
+
+	// auth
+	root.AddCommand(withCurrentUser(withPassword(setPassword(svc.AuthService), 1)))
+	root.AddCommand(withCurrentUser(withPassword(login(svc.AuthService, migrator), 1)))
+	root.AddCommand(logout(svc.AuthService, migrator))
+	root.AddCommand(whoami())
 
 	// folder
-	root.AddCommand(createFolder(svc.FolderService))
-	root.AddCommand(deleteFolder(svc.FolderService))
-	root.AddCommand(listFolders(svc.FolderService))
-	root.AddCommand(renameFolder(svc.FolderService))
+	root.AddCommand(withCurrentUser(createFolder(svc.FolderService)))
+	root.AddCommand(withCurrentUser(deleteFolder(svc.FolderService)))
+	root.AddCommand(withCurrentUser(listFolders(svc.FolderService)))
+	root.AddCommand(withCurrentUser(renameFolder(svc.FolderService)))
 
 	// file
-	root.AddCommand(createFile(svc.FileService))
-	root.AddCommand(deleteFile(svc.FileService))
-	root.AddCommand(listFiles(svc.FileService))
+	root.AddCommand(withCurrentUser(createFile(svc.FileService)))
+	root.AddCommand(withCurrentUser(deleteFile(svc.FileService)))
+	root.AddCommand(withCurrentUser(listFiles(svc.FileService)))
+	root.AddCommand(withCurrentUser(writeFile(svc.FileService)))
+	root.AddCommand(withCurrentUser(catFile(svc.FileService)))
+	root.AddCommand(withCurrentUser(moveFile(svc.FileService)))
+	root.AddCommand(withCurrentUser(copyFile(svc.FileService)))
+
+	// trash
+	root.AddCommand(withCurrentUser(listTrash(svc.TrashService)))
+	root.AddCommand(withCurrentUser(restore(svc.TrashService)))
+	root.AddCommand(withCurrentUser(emptyTrash(svc.TrashService)))
+
+	// stat
+	root.AddCommand(withCurrentUser(stat(svc.StatService)))
+
+	// share
+	root.AddCommand(withCurrentUser(shareFolder(svc.ShareService)))
+	root.AddCommand(withCurrentUser(unshareFolder(svc.ShareService)))
+	root.AddCommand(withCurrentUser(listSharedWithMe(svc.ShareService)))
+
+	// audit
+	root.AddCommand(withCurrentUser(audit(svc.AuditService)))
+
+	// webhook
+	root.AddCommand(withCurrentUser(addWebhook(svc.WebhookService)))
+	root.AddCommand(withCurrentUser(listWebhooks(svc.WebhookService)))
+	root.AddCommand(withCurrentUser(removeWebhook(svc.WebhookService)))
+	root.AddCommand(withCurrentUser(listDeliveries(svc.WebhookService)))
+
+	// server
+	root.AddCommand(serve(handler, svc.Events))
+	root.AddCommand(relay(svc.Events))
+
+	// shell
+	root.AddCommand(shell(func() *Command {
+		return NewRootCommand(svc, handler, migrator)
+	}))
+
+	// migrate
+	root.AddCommand(migrate(migrator))
 
 	return &Command{root}
 }
@@ -33,7 +33,7 @@ func TestNewRootCommand(t *testing.T) {
 			name:         "unknown flag",
 			request:      `list-folders user1 --sort-filename asc`,
 			hasErr:       true,
-			wantResponse: "list-folders [username] [--sort-name|--sort-created] [asc|desc]\n",
+			wantResponse: "list-folders [username] [foldername]? [--sort] [key:asc|desc,...] [--sort-name|--sort-created] [asc|desc] [--name-order] [natural|ignore-case] [--filter] [pattern] [--created-after|--created-before] [time] [--limit] [n] [--offset|--cursor] [n|id] [--output] [text|json|yaml|csv|table]\n",
 		},
 	}
 
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// relayInterval is how often serve relays the events which are still pending in the outbox.
+const relayInterval = 5 * time.Second
+
+// serve relays the outbox in the background, so the requests don't wait for the handlers of the events.
+func serve(handler http.Handler, bus *app.EventBus) *cobra.Command {
+	const prompt = "serve [--addr]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "server", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	addr := command.Flags().String("addr", ":8080", "address the http server listens on")
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &http.Server{
+			Addr:              *addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		relayed := make(chan struct{})
+		go func() {
+			defer close(relayed)
+			bus.Run(ctx, relayInterval)
+		}()
+
+		shutdown := make(chan struct{})
+		go func() {
+			defer close(shutdown)
+			<-ctx.Done()
+			timeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(timeout)
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Listen on %v\n", server.Addr)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			stop()
+		}
+		<-shutdown
+		<-relayed
+	}
+	return command
+}
+
+// relay dispatches the due events of the outbox once,
+// so the webhooks are posted without serve, e.g. by cron.
+func relay(bus *app.EventBus) *cobra.Command {
+	const prompt = "relay"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "server", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		err := bus.Relay(cmd.Context())
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Relay the outbox successfully.\n")
+	}
+	return command
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func shareFolder(svc app.ShareService) *cobra.Command {
+	const prompt = "share-folder [owner] [foldername] [grantee] [read|write|admin]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "share", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(4)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		req := app.ShareFolderParams{
+			Owner:       args[0],
+			Foldername:  args[1],
+			Grantee:     args[2],
+			Permission:  args[3],
+			CreatedTime: time.Now(),
+		}
+
+		err := svc.ShareFolder(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Share %v/%v with %v as %v successfully.\n",
+			req.Owner,
+			req.Foldername,
+			req.Grantee,
+			req.Permission,
+		)
+	}
+	return command
+}
+
+func unshareFolder(svc app.ShareService) *cobra.Command {
+	const prompt = "unshare-folder [owner] [foldername] [grantee]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "share", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(3)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		req := app.UnshareFolderParams{
+			Owner:      args[0],
+			Foldername: args[1],
+			Grantee:    args[2],
+		}
+
+		err := svc.UnshareFolder(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Unshare %v/%v with %v successfully.\n",
+			req.Owner,
+			req.Foldername,
+			req.Grantee,
+		)
+	}
+	return command
+}
+
+func listSharedWithMe(svc app.ShareService) *cobra.Command {
+	const prompt = "list-shared-with-me [username] [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "share", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	output := addOutputFlag(command)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		folders, err := svc.ListSharedWithMe(cmd.Context(), username)
+		isEmpty := errors.Is(err, app.ErrListSharedEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toSharedFolderRecords(folders))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, folder := range folders {
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%v %v %v %v\n",
+				folder.Owner,
+				folder.Path,
+				folder.Permission,
+				folder.SharedTime.Format("2006-01-02 15:04:05"),
+			)
+		}
+	}
+	return command
+}
@@ -0,0 +1,110 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_shareFolder(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `share-folder user1 folder1 user2 read`,
+			hasErr:       false,
+			wantResponse: "Share user1/folder1 with user2 as read successfully.\n",
+		},
+		{
+			name:         "The [permission] is invalid.",
+			request:      `share-folder user1 folder1 user2 owner`,
+			hasErr:       true,
+			wantResponse: "Error: The permission owner contain invalid chars.\n",
+		},
+		{
+			name:         "The [grantee] doesn't exist.",
+			request:      `share-folder user1 folder1 user4 read`,
+			hasErr:       true,
+			wantResponse: "Error: The user4 doesn't exist.\n",
+		},
+		{
+			name:         "The owner can't be the grantee.",
+			request:      `share-folder user1 folder1 USER1 read`,
+			hasErr:       true,
+			wantResponse: "Error: The user1 contain invalid chars.\n",
+		},
+		{
+			name:         "The grantee logs in.",
+			request:      `login user2 user2-password`,
+			hasErr:       false,
+			wantResponse: "Login user2 successfully.\n",
+		},
+		{
+			name:    "The grantee reads the shared folder.",
+			request: `list-files user1 folder1 --sort-name asc`,
+			hasErr:  false,
+			wantResponse: `file1 2024-05-27 23:00:03 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+file3 2024-05-27 23:00:02 folder1 user1
+`,
+		},
+		{
+			name:         "The folder isn't shared.",
+			request:      `list-files user1 folder3`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to read /folder3.\n",
+		},
+		{
+			name:         "The read permission doesn't write.",
+			request:      `delete-file user1 folder1 file1`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to write /folder1.\n",
+		},
+		{
+			name:         "The read permission doesn't share.",
+			request:      `share-folder user1 folder1 user2 write`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to share /folder1.\n",
+		},
+		{
+			name:         "The trash belongs to the owner.",
+			request:      `list-trash user1`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't have the permission to manage /.\n",
+		},
+		{
+			name:         "The grantee leaves the shared folder.",
+			request:      `unshare-folder user1 folder1 user2`,
+			hasErr:       false,
+			wantResponse: "Unshare user1/folder1 with user2 successfully.\n",
+		},
+		{
+			name:         "empty",
+			request:      `list-shared-with-me user2`,
+			hasErr:       false,
+			wantResponse: "Warning: There are no shared folders.\n",
+		},
+		{
+			name:         "The share doesn't exist.",
+			request:      `unshare-folder user1 folder1 user2`,
+			hasErr:       true,
+			wantResponse: "Error: The share of /folder1 with user2 doesn't exist.\n",
+		},
+		{
+			name:         "The grantee logs out.",
+			request:      `logout`,
+			hasErr:       false,
+			wantResponse: "Logout successfully.\n",
+		},
+		{
+			name:         "Nobody can unshare without login.",
+			request:      `unshare-folder user1 folder1 user2`,
+			hasErr:       true,
+			wantResponse: "Error: The user needs to login.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+const (
+	annotationCurrentUser = "current-user"
+	annotationPassword    = "password"
+)
+
+// withCurrentUser marks a command whose first arg is [username],
+// so the shell can fill it with the user chosen by `use`.
+func withCurrentUser(command *cobra.Command) *cobra.Command {
+	if command.Annotations == nil {
+		command.Annotations = make(map[string]string)
+	}
+	command.Annotations[annotationCurrentUser] = "true"
+	return command
+}
+
+// withPassword marks a command whose arg i is [password]?,
+// so the shell reads an omitted password from the next line, and keeps a given one out of the history.
+func withPassword(command *cobra.Command, i int) *cobra.Command {
+	if command.Annotations == nil {
+		command.Annotations = make(map[string]string)
+	}
+	command.Annotations[annotationPassword] = strconv.Itoa(i)
+	return command
+}
+
+func shell(newRoot func() *Command) *cobra.Command {
+	const prompt = "shell"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "shell", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		session := &shellSession{
+			newRoot: newRoot,
+			scanner: bufio.NewScanner(cmd.InOrStdin()),
+			stdout:  cmd.OutOrStdout(),
+			stderr:  cmd.ErrOrStderr(),
+		}
+		session.run()
+	}
+	return command
+}
+
+type shellSession struct {
+	newRoot  func() *Command
+	scanner  *bufio.Scanner
+	stdout   io.Writer
+	stderr   io.Writer
+	username string
+	history  []string
+}
+
+func (s *shellSession) run() {
+	for {
+		fmt.Fprint(s.stdout, s.prompt())
+		if !s.scanner.Scan() {
+			fmt.Fprintln(s.stdout)
+			return
+		}
+
+		line := strings.TrimSpace(s.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			n, err := strconv.Atoi(line[1:])
+			if err != nil || n < 1 || n > len(s.history) {
+				fmt.Fprintf(s.stderr, "Error: The %v doesn't exist in history.\n", line)
+				continue
+			}
+			line = s.history[n-1]
+			fmt.Fprintln(s.stdout, line)
+		}
+
+		args := pkg.CliParse(line)
+		if !s.hasPassword(args) {
+			s.history = append(s.history, line)
+		}
+
+		if !s.eval(args) {
+			return
+		}
+	}
+}
+
+// hasPassword reports whether the line gives a password,
+// which must not be printed by `history` or replayed by `!N`.
+func (s *shellSession) hasPassword(args []string) bool {
+	sub, _, err := s.newRoot().Find(args)
+	if err != nil || sub.Annotations[annotationPassword] == "" {
+		return false
+	}
+	i, _ := strconv.Atoi(sub.Annotations[annotationPassword])
+	// the first arg is the name of the command
+	return len(s.fillCurrentUser(args)) > i+1
+}
+
+// fillCurrentUser puts the user chosen by `use` as [username] of the command,
+// only when the args are one short of the command, so an explicit username is kept.
+func (s *shellSession) fillCurrentUser(args []string) []string {
+	if s.username == "" {
+		return args
+	}
+
+	// a fresh root, because parsing the flags changes the command
+	sub, rest, err := s.newRoot().Find(args)
+	if err != nil || sub.Annotations[annotationCurrentUser] == "" {
+		return args
+	}
+	err = sub.ParseFlags(rest)
+	if err != nil {
+		return args
+	}
+	positional := sub.Flags().Args()
+	if sub.ValidateArgs(positional) == nil || sub.ValidateArgs(append([]string{s.username}, positional...)) != nil {
+		return args
+	}
+	return append([]string{args[0], s.username}, args[1:]...)
+}
+
+func (s *shellSession) prompt() string {
+	if s.username == "" {
+		return "vFS> "
+	}
+	return fmt.Sprintf("vFS(%v)> ", s.username)
+}
+
+// eval executes one line and reports whether the shell should keep reading.
+func (s *shellSession) eval(args []string) bool {
+	// a line of empty quotes has no args
+	if len(args) == 0 {
+		return true
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return false
+
+	case "history":
+		for i, line := range s.history {
+			fmt.Fprintf(s.stdout, "%5d  %v\n", i+1, line)
+		}
+		return true
+
+	case "use":
+		if len(args) < 2 {
+			s.username = ""
+			fmt.Fprintf(s.stdout, "Clear current user.\n")
+			return true
+		}
+		s.username = args[1]
+		fmt.Fprintf(s.stdout, "Use %v.\n", s.username)
+		return true
+
+	case "shell":
+		fmt.Fprintf(s.stderr, "Error: Already in shell\n")
+		return true
+	}
+
+	root := s.newRoot()
+	root.SetOut(s.stdout)
+	root.SetErr(s.stderr)
+	root.SetIn(strings.NewReader(""))
+
+	args = s.fillCurrentUser(args)
+	sub, _, err := root.Find(args)
+	if err == nil {
+		// write-file reads the content from the following lines until a single "."
+		if sub.Name() == "write-file" {
+			root.SetIn(strings.NewReader(s.readUntilDot()))
+		}
+
+		// an omitted password is read from the next line, after the command prompts for it
+		if sub.Annotations[annotationPassword] != "" {
+			root.SetIn(&lineReader{scanner: s.scanner})
+		}
+	}
+
+	root.SetArgs(args)
+	root.Execute()
+	return true
+}
+
+func (s *shellSession) readUntilDot() string {
+	var content strings.Builder
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "." {
+			break
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// lineReader reads the next line of the shell on the first Read,
+// so the line is taken only when the command asks for it.
+type lineReader struct {
+	scanner *bufio.Scanner
+	line    io.Reader
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	if r.line == nil {
+		if !r.scanner.Scan() {
+			return 0, io.EOF
+		}
+		r.line = strings.NewReader(r.scanner.Text() + "\n")
+	}
+	return r.line.Read(p)
+}
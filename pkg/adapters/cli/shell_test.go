@@ -0,0 +1,110 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
+)
+
+func Test_shell(t *testing.T) {
+	setup()
+	defer teardown()
+
+	stdin := strings.Join([]string{
+		`list-folders user1 --sort-name desc`,
+		`use user1`,
+		`create-folder user1 "folder isCool" "from shell"`,
+		`list-files folder1 --sort-created desc`,
+		`write-file folder1 file1`,
+		`hello`,
+		`gopher`,
+		`.`,
+		`cat-file folder1 file1`,
+		`history`,
+		`""`,
+		`!4`,
+		`register-user user2`,
+		`exit`,
+		`list-folders`,
+	}, "\n")
+
+	spyStdout := &bytes.Buffer{}
+	spyStderr := &bytes.Buffer{}
+
+	root := inject.NewRootCommand(sut)
+	root.SetIn(strings.NewReader(stdin))
+	root.SetOut(spyStdout)
+	root.SetErr(spyStderr)
+	root.SetArgs([]string{"shell"})
+
+	root.Execute()
+
+	wantStdout := `vFS> folder3 2024-05-27 23:00:02 user1
+folder2 qa-folder 2024-05-27 23:00:01 user1
+folder1 2024-05-27 23:00:03 user1
+vFS> Use user1.
+vFS(user1)> Create folder isCool successfully.
+vFS(user1)> file1 2024-05-27 23:00:03 folder1 user1
+file3 2024-05-27 23:00:02 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+vFS(user1)> Write file1 in user1/folder1 successfully.
+vFS(user1)> hello
+gopher
+vFS(user1)>     1  list-folders user1 --sort-name desc
+    2  use user1
+    3  create-folder user1 "folder isCool" "from shell"
+    4  list-files folder1 --sort-created desc
+    5  write-file folder1 file1
+    6  cat-file folder1 file1
+    7  history
+vFS(user1)> vFS(user1)> list-files folder1 --sort-created desc
+file1 2024-05-27 23:00:03 folder1 user1
+file3 2024-05-27 23:00:02 folder1 user1
+file2 qa-file 2024-05-27 23:00:01 folder1 user1
+vFS(user1)> vFS(user1)> `
+	require.Equal(t, wantStdout, spyStdout.String())
+	require.Equal(t, "Error: Unrecognized command\n", spyStderr.String())
+}
+
+func Test_shell_password(t *testing.T) {
+	setup()
+	defer teardown()
+
+	stdin := strings.Join([]string{
+		`register user9`,
+		`user9-password`,
+		`login user9 user9-password`,
+		`use user9`,
+		`set-password`,
+		`other-password`,
+		`history`,
+	}, "\n")
+
+	spyStdout := &bytes.Buffer{}
+	spyStderr := &bytes.Buffer{}
+
+	root := inject.NewRootCommand(sut)
+	root.SetIn(strings.NewReader(stdin))
+	root.SetOut(spyStdout)
+	root.SetErr(spyStderr)
+	root.SetArgs([]string{"shell"})
+
+	root.Execute()
+
+	wantStdout := `vFS> Add user9 successfully.
+vFS> Login user9 successfully.
+vFS> Use user9.
+vFS(user9)> Set the password of user9 successfully.
+vFS(user9)>     1  register user9
+    2  use user9
+    3  set-password
+    4  history
+vFS(user9)> 
+`
+	require.Equal(t, wantStdout, spyStdout.String())
+	require.Equal(t, "Password: Password: ", spyStderr.String())
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func stat(svc app.StatService) *cobra.Command {
+	const prompt = "stat [username] [path]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "stat", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.StatParams{
+			Path: args[1],
+		}
+
+		stat, err := svc.Stat(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "Path: %v\n", stat.Path)
+		fmt.Fprintf(w, "Kind: %v\n", stat.Kind)
+		fmt.Fprintf(w, "Description: %v\n", stat.Description)
+		if stat.Kind == app.StatKind_Folder {
+			fmt.Fprintf(w, "Size: %v bytes in %v folders %v files\n", stat.Size, stat.Folders, stat.Files)
+		} else {
+			fmt.Fprintf(w, "Size: %v bytes\n", stat.Size)
+			fmt.Fprintf(w, "Content-Type: %v\n", stat.ContentType)
+		}
+		fmt.Fprintf(w, "Created: %v\n", stat.CreatedTime.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(w, "Updated: %v\n", stat.UpdatedTime.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(w, "Username: %v\n", stat.Username)
+	}
+	return command
+}
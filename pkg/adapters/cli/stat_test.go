@@ -0,0 +1,69 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_stat(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:    "folder",
+			request: `stat user1 /folder2`,
+			hasErr:  false,
+			wantResponse: `Path: /folder2
+Kind: folder
+Description: qa-folder
+Size: 0 bytes in 1 folders 1 files
+Created: 2024-05-27 23:00:01
+Updated: 2024-05-27 23:00:01
+Username: user1
+`,
+		},
+		{
+			name:    "file",
+			request: `stat user1 folder2/LOGS/app.log`,
+			hasErr:  false,
+			wantResponse: `Path: /folder2/logs/app.log
+Kind: file
+Description: 
+Size: 0 bytes
+Content-Type: text/plain; charset=utf-8
+Created: 2024-05-27 23:00:04
+Updated: 2024-05-27 23:00:04
+Username: user1
+`,
+		},
+		{
+			name:    "root folder",
+			request: `stat user1 /`,
+			hasErr:  false,
+			wantResponse: `Path: /
+Kind: folder
+Description: 
+Size: 0 bytes in 4 folders 4 files
+Created: 2024-05-27 23:00:00
+Updated: 2024-05-27 23:00:00
+Username: user1
+`,
+		},
+		{
+			name:         "The [path] doesn't exist.",
+			request:      `stat user1 /folder2/app.log`,
+			hasErr:       true,
+			wantResponse: "Error: The /folder2/app.log doesn't exist.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `stat user3 /`,
+			hasErr:       true,
+			wantResponse: "Error: The user3 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
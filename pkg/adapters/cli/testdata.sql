@@ -1,4 +1,4 @@
-INSERT INTO users (username) VALUES ('user1');
+INSERT INTO users (username, password_hash, role, created_time) VALUES ('user1', '$2a$04$a5ogqt1DcaSWkcp1UnYBuugMHBOmpfgUTBHMjwVeRm3TFxJDiUhjK', 'admin', '2024-05-27 23:00:00+08:00');
 INSERT INTO file_systems (id, username) VALUES ('01HYXCC8AJ35Q5KKVACBGYDF5T', 'user1');
 INSERT INTO folders (id, parent_id, fs_id, name, description, created_time) VALUES ('01HYXCC8AJ35Q5KKVACDEC38G7', '', '01HYXCC8AJ35Q5KKVACBGYDF5T', '/', '', '2024-05-27 23:00:00+08:00');
 INSERT INTO folders (id, parent_id, fs_id, name, description, created_time) VALUES ('01HYXCD1CD3VFFRYB9BWV19TM8', '01HYXCC8AJ35Q5KKVACDEC38G7', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'folder1', '', '2024-05-27 23:00:03+08:00');
@@ -7,8 +7,14 @@ INSERT INTO folders (id, parent_id, fs_id, name, description, created_time) VALU
 INSERT INTO files (id, name, folder_id, fs_id, foldername, description, created_time) VALUES ('01HYYMFNZSFQ2FWPN1DYFTPADH', 'file1', '01HYXCD1CD3VFFRYB9BWV19TM8', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'folder1', '', '2024-05-27 23:00:03+08:00');
 INSERT INTO files (id, name, folder_id, fs_id, foldername, description, created_time) VALUES ('01HYYMMTX8F4D2BESDCAD2YXS5', 'file2', '01HYXCD1CD3VFFRYB9BWV19TM8', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'folder1', 'qa-file', '2024-05-27 23:00:01+08:00');
 INSERT INTO files (id, name, folder_id, fs_id, foldername, description, created_time) VALUES ('01HYYMN2H854NWJJ32HJRCQKC0', 'file3', '01HYXCD1CD3VFFRYB9BWV19TM8', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'folder1', '', '2024-05-27 23:00:02+08:00');
+INSERT INTO folders (id, parent_id, fs_id, name, description, created_time) VALUES ('01HYXE1V6W9T3C8JZ7Q4M2N5PA', '01HYXCD1CGB36V08CNRGJQMZHT', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'logs', 'qa-logs', '2024-05-27 23:00:04+08:00');
+INSERT INTO files (id, name, folder_id, fs_id, foldername, description, created_time) VALUES ('01HYYMP6QK7D3S9VW0R5TB8XEA', 'app.log', '01HYXE1V6W9T3C8JZ7Q4M2N5PA', '01HYXCC8AJ35Q5KKVACBGYDF5T', 'logs', '', '2024-05-27 23:00:04+08:00');
 
+UPDATE folders SET updated_time = created_time;
+UPDATE files SET updated_time = created_time, content_type = 'text/plain; charset=utf-8';
 
-INSERT INTO users (username) VALUES ('user2');
+INSERT INTO users (username, password_hash, created_time) VALUES ('user2', '$2a$04$jBh0Pa.If/FfcLgxaGEZLONAJ1x2q7E4RpiJV0qJMsc6yc2KqZCtm', '2024-05-27 23:00:00+08:00');
 INSERT INTO file_systems (id, username) VALUES ('01HYXD38S85V0H1JF9CMWYBMBW', 'user2');
 INSERT INTO folders (id, parent_id, fs_id, name, description, created_time) VALUES ('01HYXD4H3PPAWTFSEVTVSBKPMK', '', '01HYXD38S85V0H1JF9CMWYBMBW', '/', '', '2024-05-27 23:00:00+08:00');
+
+INSERT INTO sessions (token_hash, username, created_time, expired_time) VALUES ('83ae3de2fe84089ebfb5fdb3d61985edc244c248ecb641782de77f70bd503428', 'user1', '2024-05-27 23:00:00+08:00', '2124-05-27 23:00:00+08:00');
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	_ "embed"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/KScaesar/IsCoolLab2024/pkg"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/cli"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
 	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
 )
@@ -22,7 +24,16 @@ var (
 	sut *adapters.Infra
 )
 
+// user1Token is the session of user1 in testdata.sql.
+const user1Token = "user1-token"
+
 func setup() {
+	// every test starts with user1 logged in
+	err := os.WriteFile(os.Getenv(cli.EnvSession), []byte(user1Token+"\n"), 0o600)
+	if err != nil {
+		panic(err)
+	}
+
 	conf := &database.GormConfing{
 		// Dsn: "vFS.db",
 		Dsn:     ":memory:",
@@ -30,7 +41,6 @@ func setup() {
 		// Debug:   true,
 	}
 
-	var err error
 	sut, err = inject.NewInfra(conf)
 	if err != nil {
 		panic(err)
@@ -92,8 +102,16 @@ func fixture(
 }
 
 func TestMain(m *testing.M) {
+	// keeps the session of login away from the session of the developer
+	dir, err := os.MkdirTemp("", "vFS")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(cli.EnvSession, filepath.Join(dir, "session"))
+
 	// setup()
 	code := m.Run()
+	os.RemoveAll(dir)
 	// teardown()
 
 	const success = 0
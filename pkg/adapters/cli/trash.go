@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func listTrash(svc app.TrashService) *cobra.Command {
+	const prompt = "list-trash [username] [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "trash", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	output := addOutputFlag(command)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		items, err := svc.ListTrash(cmd.Context(), username)
+		isEmpty := errors.Is(err, app.ErrListTrashEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toTrashRecords(items))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, item := range items {
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%v %v %v %v %v\n",
+				item.Id,
+				item.Kind,
+				item.Path,
+				item.DeletedTime.Format("2006-01-02 15:04:05"),
+				item.Username,
+			)
+		}
+	}
+	return command
+}
+
+func restore(svc app.TrashService) *cobra.Command {
+	const prompt = "restore [username] [id|path]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "trash", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.RestoreTrashParams{
+			Target:       args[1],
+			RestoredTime: time.Now(),
+		}
+
+		item, err := svc.RestoreTrash(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Restore %v%v successfully.\n",
+			username,
+			item.Path,
+		)
+	}
+	return command
+}
+
+func emptyTrash(svc app.TrashService) *cobra.Command {
+	const prompt = "empty-trash [username] [--older-than] [duration]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "trash", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	olderThan := command.Flags().String("older-than", "", "only remove items deleted before the duration, such as 30d or 12h")
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.EmptyTrashParams{
+			Now: time.Now(),
+		}
+		if *olderThan != "" {
+			duration, err := pkg.ParseDuration(*olderThan)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: The older-than %v %v\n", *olderThan, app.ErrInvalidParams)
+				return
+			}
+			req.OlderThan = duration
+		}
+
+		count, err := svc.EmptyTrash(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Remove %v items from the trash of %v successfully.\n",
+			count,
+			username,
+		)
+	}
+	return command
+}
@@ -0,0 +1,125 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_restore(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "trash is empty",
+			request:      "list-trash user1",
+			hasErr:       false,
+			wantResponse: "Warning: The trash is empty.\n",
+		},
+		{
+			name:         "delete nested folder",
+			request:      "delete-folder user1 folder2",
+			hasErr:       false,
+			wantResponse: "Delete folder2 successfully.\n",
+		},
+		{
+			name:         "subtree is hidden",
+			request:      "list-files user1 /folder2/logs",
+			hasErr:       true,
+			wantResponse: "Error: The /folder2/logs doesn't exist.\n",
+		},
+		{
+			name:         "success by path",
+			request:      "restore user1 folder2",
+			hasErr:       false,
+			wantResponse: "Restore user1/folder2 successfully.\n",
+		},
+		{
+			name:         "subtree is restored",
+			request:      "list-files user1 /folder2/logs",
+			hasErr:       false,
+			wantResponse: "app.log 2024-05-27 23:00:04 logs user1\n",
+		},
+		{
+			name:         "The [id|path] doesn't exist.",
+			request:      "restore user1 folder2",
+			hasErr:       true,
+			wantResponse: "Error: The folder2 doesn't exist.\n",
+		},
+		{
+			name:         "delete folder",
+			request:      "delete-folder user1 folder3",
+			hasErr:       false,
+			wantResponse: "Delete folder3 successfully.\n",
+		},
+		{
+			name:         "create folder with the same name",
+			request:      "create-folder user1 Folder3",
+			hasErr:       false,
+			wantResponse: "Create Folder3 successfully.\n",
+		},
+		{
+			name:         "The [foldername] has already existed.",
+			request:      "restore user1 /folder3",
+			hasErr:       true,
+			wantResponse: "Error: The /folder3 has already existed.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_emptyTrash(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "delete file",
+			request:      "delete-file user1 folder1 file1",
+			hasErr:       false,
+			wantResponse: "Delete file1 in user1/folder1 successfully.\n",
+		},
+		{
+			name:         "delete folder",
+			request:      "delete-folder user1 folder1",
+			hasErr:       false,
+			wantResponse: "Delete folder1 successfully.\n",
+		},
+		{
+			name:         "keep recent items",
+			request:      "empty-trash user1 --older-than 30d",
+			hasErr:       false,
+			wantResponse: "Remove 0 items from the trash of user1 successfully.\n",
+		},
+		{
+			name:         "The [duration] is invalid.",
+			request:      "empty-trash user1 --older-than 30days",
+			hasErr:       true,
+			wantResponse: "Error: The older-than 30days contain invalid chars.\n",
+		},
+		{
+			name:         "success",
+			request:      "empty-trash user1",
+			hasErr:       false,
+			wantResponse: "Remove 2 items from the trash of user1 successfully.\n",
+		},
+		{
+			name:         "trash is empty",
+			request:      "list-trash user1",
+			hasErr:       false,
+			wantResponse: "Warning: The trash is empty.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      "empty-trash user4",
+			hasErr:       true,
+			wantResponse: "Error: The user4 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,7 +12,142 @@ import (
 )
 
 func registerUser(svc app.UserService) *cobra.Command {
-	const prompt = "register [username]"
+	const prompt = "register [username] [password]?"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.RangeArgs(1, 2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		password, err := readPassword(cmd, args, 1)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		req := app.RegisterParams{
+			Username:    args[0],
+			Password:    password,
+			CreatedTime: time.Now(),
+		}
+
+		err = svc.Register(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Add %v successfully.\n", req.Username)
+	}
+	return command
+}
+
+func listUsers(svc app.UserService) *cobra.Command {
+	const prompt = "list-users [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	output := addOutputFlag(command)
+
+	command.Args = cobra.NoArgs
+	command.Run = func(cmd *cobra.Command, args []string) {
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		users, err := svc.ListUsers(cmd.Context())
+		isEmpty := errors.Is(err, app.ErrListUserEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toUserRecords(users))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, user := range users {
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%v %v\n",
+				user.Username,
+				user.CreatedTime.Format("2006-01-02 15:04:05"),
+			)
+		}
+	}
+	return command
+}
+
+func deleteUser(svc app.UserService) *cobra.Command {
+	const prompt = "delete-user [username]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		err := svc.DeleteUser(cmd.Context(), username)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Delete %v successfully.\n", username)
+	}
+	return command
+}
+
+func renameUser(svc app.UserService) *cobra.Command {
+	const prompt = "rename-user [username] [new-username]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		req := app.RenameUserParams{
+			Username:    args[0],
+			NewUsername: args[1],
+		}
+
+		err := svc.RenameUser(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Rename %v to %v successfully.\n",
+			req.Username,
+			req.NewUsername,
+		)
+	}
+	return command
+}
+
+func userInfo(svc app.UserService) *cobra.Command {
+	const prompt = "user-info [username]"
 
 	command := &cobra.Command{
 		Use: prompt,
@@ -23,12 +159,122 @@ func registerUser(svc app.UserService) *cobra.Command {
 	command.Run = func(cmd *cobra.Command, args []string) {
 		username := args[0]
 
-		err := svc.Register(cmd.Context(), username, time.Now())
+		info, err := svc.GetUserInfo(cmd.Context(), username)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"%v %v folders %v files %v\n",
+			info.Username,
+			info.Folders,
+			info.Files,
+			info.CreatedTime.Format("2006-01-02 15:04:05"),
+		)
+	}
+	return command
+}
+
+func setQuota(svc app.UserService) *cobra.Command {
+	const prompt = "set-quota [username] [--max-folders] [n] [--max-files] [n] [--max-bytes] [size]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	maxFolders := command.Flags().Int("max-folders", 0, "the max number of folders, 0 is unlimited")
+	maxFiles := command.Flags().Int("max-files", 0, "the max number of files, 0 is unlimited")
+	maxBytes := command.Flags().String("max-bytes", "", "the max size of the contents such as 512, 64KB or 10MB, 0 is unlimited")
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		req := app.SetQuotaParams{
+			Username: args[0],
+		}
+		// the omitted limits are kept
+		if cmd.Flags().Changed("max-folders") {
+			req.MaxFolders = maxFolders
+		}
+		if cmd.Flags().Changed("max-files") {
+			req.MaxFiles = maxFiles
+		}
+		if cmd.Flags().Changed("max-bytes") {
+			size, err := pkg.ParseByteSize(*maxBytes)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: The max-bytes %v %v\n", *maxBytes, app.ErrInvalidParams)
+				return
+			}
+			req.MaxBytes = &size
+		}
+
+		err := svc.SetQuota(cmd.Context(), req)
 		if err != nil {
 			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
 			return
 		}
-		fmt.Fprintf(cmd.OutOrStdout(), "Add %v successfully.\n", username)
+		fmt.Fprintf(cmd.OutOrStdout(), "Set the quota of %v successfully.\n", req.Username)
+	}
+	return command
+}
+
+func setRole(svc app.UserService) *cobra.Command {
+	const prompt = "set-role [username] [admin|user]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		req := app.SetRoleParams{
+			Username: args[0],
+			Role:     args[1],
+		}
+
+		err := svc.SetRole(cmd.Context(), req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Set the role of %v to %v successfully.\n", req.Username, req.Role)
+	}
+	return command
+}
+
+func showQuota(svc app.UserService) *cobra.Command {
+	const prompt = "show-quota [username]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "user", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		quota, err := svc.GetQuota(cmd.Context(), username)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		limit := func(n int64) string {
+			if n == 0 {
+				return "unlimited"
+			}
+			return fmt.Sprint(n)
+		}
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "Username: %v\n", quota.Username)
+		fmt.Fprintf(w, "Folders: %v / %v\n", quota.Folders, limit(int64(quota.MaxFolders)))
+		fmt.Fprintf(w, "Files: %v / %v\n", quota.Files, limit(int64(quota.MaxFiles)))
+		fmt.Fprintf(w, "Bytes: %v / %v\n", quota.Size, limit(quota.MaxBytes))
 	}
 	return command
 }
@@ -13,16 +13,256 @@ func Test_registerUser(t *testing.T) {
 	}{
 		{
 			name:         "success",
-			request:      `register user3`,
+			request:      `register user3 user3-password`,
 			hasErr:       false,
 			wantResponse: "Add user3 successfully.\n",
 		},
+		{
+			name:         "The password is too short.",
+			request:      `register user4 1234`,
+			hasErr:       true,
+			wantResponse: "Error: The password must have 8 to 72 bytes, it contain invalid chars.\n",
+		},
 		{
 			name:         "The [username] has already existed.",
-			request:      `register user1`,
+			request:      `register user1 user1-password`,
 			hasErr:       true,
 			wantResponse: "Error: The user1 has already existed.\n",
 		},
+		{
+			name:         "The [username] is case-insensitive.",
+			request:      `register USER1 user1-password`,
+			hasErr:       true,
+			wantResponse: "Error: The USER1 has already existed.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_listUsers(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `list-users`,
+			hasErr:       false,
+			wantResponse: "user1 2024-05-27 23:00:00\nuser2 2024-05-27 23:00:00\n",
+		},
+		{
+			name:         "csv",
+			request:      `list-users --output csv`,
+			hasErr:       false,
+			wantResponse: "username,created_time\nuser1,2024-05-27T23:00:00+08:00\nuser2,2024-05-27T23:00:00+08:00\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_deleteUser(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "The admin deletes the other user.",
+			request:      `delete-user user2`,
+			hasErr:       false,
+			wantResponse: "Delete user2 successfully.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `delete-user user2`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 doesn't exist.\n",
+		},
+		{
+			name:         "The last admin is kept.",
+			request:      `delete-user user1`,
+			hasErr:       true,
+			wantResponse: "Error: The user1 is the last admin.\n",
+		},
+		{
+			name:         "register again",
+			request:      `register user2 user2-password`,
+			hasErr:       false,
+			wantResponse: "Add user2 successfully.\n",
+		},
+		{
+			name:         "login",
+			request:      `login user2 user2-password`,
+			hasErr:       false,
+			wantResponse: "Login user2 successfully.\n",
+		},
+		{
+			name:         "file system is recreated",
+			request:      `list-folders user2`,
+			hasErr:       false,
+			wantResponse: "Warning: The user2 doesn't have any folders.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_renameUser(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `rename-user user1 user3`,
+			hasErr:       false,
+			wantResponse: "Rename user1 to user3 successfully.\n",
+		},
+		{
+			name:         "file system follows the user",
+			request:      `list-files user3 folder2/logs`,
+			hasErr:       false,
+			wantResponse: "app.log 2024-05-27 23:00:04 logs user3\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `rename-user user1 user4`,
+			hasErr:       true,
+			wantResponse: "Error: The user1 doesn't exist.\n",
+		},
+		{
+			name:         "The [new-username] has already existed.",
+			request:      `rename-user user3 user2`,
+			hasErr:       true,
+			wantResponse: "Error: The user2 has already existed.\n",
+		},
+		{
+			name:         "change the letter case",
+			request:      `rename-user user3 User3`,
+			hasErr:       false,
+			wantResponse: "Rename user3 to User3 successfully.\n",
+		},
+		{
+			name:         "The [new-username] contain invalid chars.",
+			request:      `rename-user user3 user@3`,
+			hasErr:       true,
+			wantResponse: "Error: The user@3 contain invalid chars.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_userInfo(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `user-info user1`,
+			hasErr:       false,
+			wantResponse: "user1 4 folders 4 files 2024-05-27 23:00:00\n",
+		},
+		{
+			name:         "trash isn't counted",
+			request:      `delete-folder user1 folder2`,
+			hasErr:       false,
+			wantResponse: "Delete folder2 successfully.\n",
+		},
+		{
+			name:         "after delete",
+			request:      `user-info user1`,
+			hasErr:       false,
+			wantResponse: "user1 2 folders 3 files 2024-05-27 23:00:00\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `user-info user4`,
+			hasErr:       true,
+			wantResponse: "Error: The user4 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
+
+func Test_setQuota(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "success",
+			request:      `set-quota user1 --max-folders 5 --max-bytes 1KB`,
+			hasErr:       false,
+			wantResponse: "Set the quota of user1 successfully.\n",
+		},
+		{
+			name:         "keep the omitted limits",
+			request:      `set-quota user1 --max-files 6`,
+			hasErr:       false,
+			wantResponse: "Set the quota of user1 successfully.\n",
+		},
+		{
+			name:         "show",
+			request:      `show-quota user1`,
+			hasErr:       false,
+			wantResponse: "Username: user1\nFolders: 4 / 5\nFiles: 4 / 6\nBytes: 0 / 1024\n",
+		},
+		{
+			name:         "create under the quota",
+			request:      `create-folder user1 /folder5`,
+			hasErr:       false,
+			wantResponse: "Create /folder5 successfully.\n",
+		},
+		{
+			name:         "The [username] has exceeded the quota.",
+			request:      `create-folder user1 /folder6`,
+			hasErr:       true,
+			wantResponse: "Error: The user1 has exceeded the quota of 5 folders.\n",
+		},
+		{
+			name:         "unlimited",
+			request:      `set-quota user1 --max-folders 0`,
+			hasErr:       false,
+			wantResponse: "Set the quota of user1 successfully.\n",
+		},
+		{
+			name:         "create without the limit",
+			request:      `create-folder user1 /folder6`,
+			hasErr:       false,
+			wantResponse: "Create /folder6 successfully.\n",
+		},
+		{
+			name:         "The [max-bytes] contain invalid chars.",
+			request:      `set-quota user1 --max-bytes 1TB`,
+			hasErr:       true,
+			wantResponse: "Error: The max-bytes 1TB contain invalid chars.\n",
+		},
+		{
+			name:         "The [max-files] contain invalid chars.",
+			request:      `set-quota user1 --max-files -1`,
+			hasErr:       true,
+			wantResponse: "Error: The max-files -1 contain invalid chars.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `show-quota user3`,
+			hasErr:       true,
+			wantResponse: "Error: The user3 doesn't exist.\n",
+		},
 	}
 
 	fixture(t, testcase)
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func addWebhook(svc app.WebhookService) *cobra.Command {
+	const prompt = "add-webhook [username] [url] [events...]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "webhook", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.MinimumNArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		events, err := app.ParseEventNames(args[2:])
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		req := app.AddWebhookParams{
+			Url:         args[1],
+			Events:      events,
+			CreatedTime: time.Now(),
+		}
+
+		hook, err := svc.AddWebhook(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Add webhook %v successfully, the secret is %v.\n", hook.Id, hook.Secret)
+	}
+	return command
+}
+
+func listWebhooks(svc app.WebhookService) *cobra.Command {
+	const prompt = "list-webhooks [username] [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "webhook", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	output := addOutputFlag(command)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		hooks, err := svc.ListWebhooks(cmd.Context(), username)
+		isEmpty := errors.Is(err, app.ErrListWebhookEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toWebhookRecords(hooks))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, hook := range hooks {
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%v %v %v %v\n",
+				hook.Id,
+				hook.Url,
+				webhookEvents(hook.Events),
+				hook.CreatedTime.Format("2006-01-02 15:04:05"),
+			)
+		}
+	}
+	return command
+}
+
+func removeWebhook(svc app.WebhookService) *cobra.Command {
+	const prompt = "remove-webhook [username] [id]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "webhook", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	command.Args = cobra.ExactArgs(2)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		req := app.RemoveWebhookParams{
+			Id: args[1],
+		}
+
+		err := svc.RemoveWebhook(cmd.Context(), username, req)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Remove webhook %v successfully.\n", req.Id)
+	}
+	return command
+}
+
+func listDeliveries(svc app.WebhookService) *cobra.Command {
+	const prompt = "list-deliveries [username] [--output] [text|json|yaml|csv|table]"
+
+	command := &cobra.Command{
+		Use: prompt,
+	}
+	pkg.CliSetUsage(command, "webhook", prompt)
+	pkg.CliSetActivePrompt(command, prompt)
+
+	output := addOutputFlag(command)
+
+	command.Args = cobra.ExactArgs(1)
+	command.Run = func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		format, err := parseOutputFormat(*output)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		deliveries, err := svc.ListDeliveries(cmd.Context(), username)
+		isEmpty := errors.Is(err, app.ErrListDeliveryEmpty)
+		if err != nil && !isEmpty {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%v\n", err)
+			return
+		}
+
+		if format != outputText {
+			err = renderRecords(cmd.OutOrStdout(), format, toDeliveryRecords(deliveries))
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			}
+			return
+		}
+
+		if isEmpty {
+			fmt.Fprintf(cmd.OutOrStdout(), "%v\n", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			status := "ok"
+			if delivery.Error != "" {
+				status = delivery.Error
+			}
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%v %v %v %v attempts=%v %v\n",
+				delivery.DeliveredTime.Format("2006-01-02 15:04:05"),
+				delivery.WebhookId,
+				delivery.Event,
+				delivery.EventId,
+				delivery.Attempts,
+				status,
+			)
+		}
+	}
+	return command
+}
+
+// webhookEvents names the webhook which subscribes to all events.
+func webhookEvents(events []string) string {
+	if len(events) == 0 {
+		return "*"
+	}
+	return strings.Join(events, ",")
+}
@@ -0,0 +1,59 @@
+package cli_test
+
+import (
+	"testing"
+)
+
+func Test_webhook(t *testing.T) {
+	testcase := []struct {
+		name         string
+		request      string
+		hasErr       bool
+		wantResponse string
+	}{
+		{
+			name:         "empty",
+			request:      `list-webhooks user1`,
+			hasErr:       false,
+			wantResponse: "Warning: There are no webhooks.\n",
+		},
+		{
+			name:         "The [url] is invalid.",
+			request:      `add-webhook user1 ftp://localhost/hook`,
+			hasErr:       true,
+			wantResponse: "Error: The url ftp://localhost/hook contain invalid chars.\n",
+		},
+		{
+			name:         "The [events] is invalid.",
+			request:      `add-webhook user1 http://localhost/hook folder.created folder.moved`,
+			hasErr:       true,
+			wantResponse: "Error: The event folder.moved contain invalid chars.\n",
+		},
+		{
+			name:         "The [id] doesn't exist.",
+			request:      `remove-webhook user1 01HZ0000000000000000000000`,
+			hasErr:       true,
+			wantResponse: "Error: The webhook 01HZ0000000000000000000000 doesn't exist.\n",
+		},
+		{
+			name:         "no deliveries",
+			request:      `list-deliveries user1`,
+			hasErr:       false,
+			wantResponse: "Warning: There are no webhook deliveries.\n",
+		},
+		{
+			name:         "relay",
+			request:      `relay`,
+			hasErr:       false,
+			wantResponse: "Relay the outbox successfully.\n",
+		},
+		{
+			name:         "The [username] doesn't exist.",
+			request:      `add-webhook user4 http://localhost/hook`,
+			hasErr:       true,
+			wantResponse: "Error: The user4 doesn't exist.\n",
+		},
+	}
+
+	fixture(t, testcase)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+const (
+	AuditTable = "audit_log"
+)
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// AuditRepository only inserts and selects audit_log, so the table stays append-only.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func (repo *AuditRepository) AppendAudit(ctx context.Context, entry *app.AuditEntry) error {
+	err := getDB(ctx, repo.db).Table(AuditTable).
+		Create(entry).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *AuditRepository) ListAudit(ctx context.Context, fsId string, since time.Time) ([]*app.AuditEntry, error) {
+	var entries []*app.AuditEntry
+	err := getDB(ctx, repo.db).Table(AuditTable).
+		Where("fs_id = ? AND created_time >= ?", fsId, since).
+		Order("created_time, id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
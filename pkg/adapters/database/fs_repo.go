@@ -4,17 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/KScaesar/IsCoolLab2024/pkg/app"
 )
 
 const (
-	FileSystemTable = "file_systems"
-	FolderTable     = "folders"
-	FileTable       = "files"
+	FileSystemTable  = "file_systems"
+	FolderTable      = "folders"
+	FileTable        = "files"
+	FileContentTable = "file_contents"
+	TrashTable       = "trash_items"
+	GrantTable       = "folder_grants"
 )
 
 func NewFileSystemRepository(db *gorm.DB) *FileSystemRepository {
@@ -26,7 +33,7 @@ type FileSystemRepository struct {
 }
 
 func (repo *FileSystemRepository) CreateFileSystem(ctx context.Context, fs *app.FileSystem) error {
-	err := repo.db.WithContext(ctx).Table(FileSystemTable).
+	err := getDB(ctx, repo.db).Table(FileSystemTable).
 		Create(fs).Error
 	if err != nil {
 		return err
@@ -34,15 +41,106 @@ func (repo *FileSystemRepository) CreateFileSystem(ctx context.Context, fs *app.
 	return nil
 }
 
-func (repo *FileSystemRepository) GetFileSystemByUsername(ctx context.Context, username string) (*app.FileSystem, error) {
-	// https://gorm.io/zh_CN/docs/preload.html#%E9%A2%84%E5%8A%A0%E8%BD%BD%E5%85%A8%E9%83%A8
+func (repo *FileSystemRepository) DeleteFileSystem(ctx context.Context, fs *app.FileSystem) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(FileContentTable).
+		Where("file_id IN (?)", db.Table(FileTable).Select("id").Where("fs_id = ?", fs.Id)).
+		Delete(&app.FileContent{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(FileTable).
+		Delete(&app.File{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(FolderTable).
+		Delete(&app.Folder{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(TrashTable).
+		Delete(&app.TrashItem{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(GrantTable).
+		Delete(&app.Grant{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(WebhookTable).
+		Delete(&app.Webhook{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(WebhookDeliveryTable).
+		Delete(&app.WebhookDelivery{}, "fs_id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(FileSystemTable).
+		Delete(fs, "id = ?", fs.Id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// defaultLoadStrategy is the fastest strategy measured by BenchmarkFileSystemRepository_LoadFileSystem,
+// with sqlite over 10k folders and 100k files:
+//
+//	preload     1.77 s/op   118 MB/op
+//	join        2.38 s/op   220 MB/op
+//	recursive   2.03 s/op   206 MB/op
+const defaultLoadStrategy = app.LoadStrategy_Preload
+
+func (repo *FileSystemRepository) LoadFileSystem(ctx context.Context, username string, opts app.LoadFileSystemOptions) (*app.FileSystem, error) {
+	db := getDB(ctx, repo.db)
+
+	strategy := opts.Strategy
+	if strategy == app.LoadStrategy_Default {
+		strategy = defaultLoadStrategy
+	}
+
+	var load func(db *gorm.DB, fs *app.FileSystem) error
+	switch strategy {
+	case app.LoadStrategy_Preload:
+		load = loadByPreload
+	case app.LoadStrategy_Join:
+		load = loadByJoin
+	case app.LoadStrategy_Recursive:
+		load = loadByRecursive
+	default:
+		return nil, fmt.Errorf("Error: The load strategy %v %w", strategy, app.ErrInvalidParams)
+	}
+
+	fs, err := takeFileSystem(db, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = load(db, fs)
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// takeFileSystem returns the row of fs without the tree, the stored username is kept.
+func takeFileSystem(db *gorm.DB, username string) (*app.FileSystem, error) {
 	var fs app.FileSystem
-	err := repo.db.WithContext(ctx).Table(FileSystemTable).
-		Where("username = ?", username).
-		Preload("Root", "parent_id = ''"). // 取得 root 目錄本身
-		Preload("Root.Folders").           // 取得 root 目錄的 dir
-		Preload("Root.Folders.Files").     // 取得 dir 的 file
-		Preload("Root.Files").             // 取得 root 目錄的 file
+	err := db.Table(FileSystemTable).
+		Where("LOWER(username) = LOWER(?)", username).
 		Take(&fs).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -50,97 +148,159 @@ func (repo *FileSystemRepository) GetFileSystemByUsername(ctx context.Context, u
 		}
 		return nil, err
 	}
+	return &fs, nil
+}
 
-	// SELECT * FROM `file_systems`
-	// WHERE username = "user1"
-	// LIMIT 1;
-	//
-	// SELECT *
-	// FROM `folders`
-	// WHERE `folders`.`fs_id` = "01HYXCC8AJ35Q5KKVACBGYDF5T" AND parent_id = '';
-	//
-	// SELECT *
-	// FROM `folders`
-	// WHERE `folders`.`parent_id` = "01HYXCC8AJ35Q5KKVACDEC38G7";
-	//
-	// SELECT *
-	// FROM `files`
-	// WHERE `files`.`folder_id` IN (
-	//                              "01HYXCD1CD3VFFRYB9BWV19TM8",
-	//                              "01HYXCD1CGB36V08CNRGJQMZHT",
-	//                              "01HYXD0GV43XKBZ7Y1YDK7QDBQ"
-	//                             );
-	//
-	// SELECT *
-	// FROM `files`
-	// WHERE `files`.`folder_id` = "01HYXCC8AJ35Q5KKVACDEC38G7";
+func takeRootFolder(db *gorm.DB, fs *app.FileSystem) error {
+	err := db.Table(FolderTable).
+		Where("fs_id = ? AND parent_id = ''", fs.Id).
+		Take(&fs.Root).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Error: The %v %w", fs.Username, app.ErrUserNotExists)
+		}
+		return err
+	}
+	return nil
+}
 
-	return &fs, nil
+// preloadBatchSize keeps the number of parameters in a query under the limits of the drivers.
+const preloadBatchSize = 1000
+
+// loadByPreload queries the folders one level at a time, and then all files of fs.
+// The subtree of a folder in the trash carries the trash_id too,
+// so every live file belongs to a live folder.
+func loadByPreload(db *gorm.DB, fs *app.FileSystem) error {
+	err := takeRootFolder(db, fs)
+	if err != nil {
+		return err
+	}
+
+	folders := map[string]*app.Folder{fs.Root.Id: &fs.Root}
+	parents := []string{fs.Root.Id}
+	for len(parents) > 0 {
+		var children []string
+		for start := 0; start < len(parents); start += preloadBatchSize {
+			batch := parents[start:min(start+preloadBatchSize, len(parents))]
+
+			var rows []*app.Folder
+			err := db.Table(FolderTable).
+				Where("parent_id IN ? AND trash_id = ''", batch).
+				Find(&rows).Error
+			if err != nil {
+				return err
+			}
+
+			for _, folder := range rows {
+				parent := folders[folder.ParentFolderId]
+				parent.Folders = append(parent.Folders, folder)
+				folders[folder.Id] = folder
+				children = append(children, folder.Id)
+			}
+		}
+		parents = children
+	}
+
+	var files []*app.File
+	err = db.Table(FileTable).
+		Where("fs_id = ? AND trash_id = ''", fs.Id).
+		Find(&files).Error
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		folder, ok := folders[file.FolderId]
+		if ok {
+			folder.Files = append(folder.Files, file)
+		}
+	}
+
+	// SELECT * FROM `file_systems` WHERE LOWER(username) = LOWER("user1") LIMIT 1;
+	// SELECT * FROM `folders` WHERE fs_id = "01HYXCC8AJ35Q5KKVACBGYDF5T" AND parent_id = '' LIMIT 1;
+	// SELECT * FROM `folders` WHERE parent_id IN ("01HYXCC8AJ35Q5KKVACDEC38G7") AND trash_id = '';
+	// SELECT * FROM `folders` WHERE parent_id IN ("01HYXCD1CD3VFFRYB9BWV19TM8","01HYXCD1CGB36V08CNRGJQMZHT") AND trash_id = '';
+	// SELECT * FROM `files` WHERE fs_id = "01HYXCC8AJ35Q5KKVACBGYDF5T" AND trash_id = '';
+
+	return nil
 }
 
-func (repo *FileSystemRepository) GetFileSystemByUsernameV2(ctx context.Context, username string) (*app.FileSystem, error) {
+func loadByJoin(db *gorm.DB, fs *app.FileSystem) error {
 	type Mapper struct {
-		FsId              string     `gorm:"column:fs_id"`
 		FolderId          string     `gorm:"column:folder_id"`
 		ParentId          string     `gorm:"column:parent_id"`
 		FolderName        string     `gorm:"column:folder_name"`
 		FolderDescription string     `gorm:"column:folder_description"`
 		FolderCreatedTime time.Time  `gorm:"column:folder_created_time"`
+		FolderUpdatedTime time.Time  `gorm:"column:folder_updated_time"`
 		FileId            *string    `gorm:"column:file_id"`
 		FileName          *string    `gorm:"column:file_name"`
 		FileDescription   *string    `gorm:"column:file_description"`
 		FileCreatedTime   *time.Time `gorm:"column:file_created_time"`
+		FileUpdatedTime   *time.Time `gorm:"column:file_updated_time"`
+		FileSize          *int64     `gorm:"column:file_size"`
+		FileContentType   *string    `gorm:"column:file_content_type"`
 	}
 	var results []Mapper
 
-	err := repo.db.WithContext(ctx).Raw(`
-SELECT fs.id               AS fs_id,
-       folder.id           AS folder_id,
-       folder.parent_id    AS parent_id,
-       folder.name         AS folder_name,
-       folder.description  AS folder_description,
-       folder.created_time AS folder_created_time,
-       file.id             AS file_id,
-       file.name           AS file_name,
-       file.description    AS file_description,
-       file.created_time   AS file_created_time
-FROM file_systems fs
-JOIN folders folder ON folder.fs_id = fs.id AND fs.username = ? 
-LEFT JOIN files file ON file.folder_id = folder.id;`, username).
+	err := db.Raw(`
+SELECT d.id           AS folder_id,
+       d.parent_id    AS parent_id,
+       d.name         AS folder_name,
+       d.description  AS folder_description,
+       d.created_time AS folder_created_time,
+       d.updated_time AS folder_updated_time,
+       f.id           AS file_id,
+       f.name         AS file_name,
+       f.description  AS file_description,
+       f.created_time AS file_created_time,
+       f.updated_time AS file_updated_time,
+       f.size         AS file_size,
+       f.content_type AS file_content_type
+FROM folders d
+LEFT JOIN files f ON f.folder_id = d.id AND f.trash_id = ''
+WHERE d.fs_id = ? AND d.trash_id = '';`, fs.Id).
 		Scan(&results).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	folders := make(map[string]*app.Folder, len(results))
+	var ordered []*app.Folder
 
 	for _, r := range results {
-		folder, ok1 := folders[r.FolderId]
-		if !ok1 {
+		folder, ok := folders[r.FolderId]
+		if !ok {
 			folder = &app.Folder{
 				Id:             r.FolderId,
 				ParentFolderId: r.ParentId,
-				FsId:           r.FsId,
+				FsId:           fs.Id,
 				Name:           r.FolderName,
 				Description:    r.FolderDescription,
 				CreatedTime:    r.FolderCreatedTime,
+				UpdatedTime:    r.FolderUpdatedTime,
 			}
 			folders[r.FolderId] = folder
+			ordered = append(ordered, folder)
 		}
 		if r.FileId != nil {
 			folder.Files = append(folder.Files, &app.File{
 				Id:          *r.FileId,
-				Name:        *r.FileName,
 				FolderId:    r.FolderId,
+				FsId:        fs.Id,
+				Name:        *r.FileName,
 				Foldername:  r.FolderName,
 				Description: *r.FileDescription,
 				CreatedTime: *r.FileCreatedTime,
+				UpdatedTime: *r.FileUpdatedTime,
+				Size:        *r.FileSize,
+				ContentType: *r.FileContentType,
 			})
 		}
 	}
 
 	var root *app.Folder
-	for _, folder := range folders {
+	for _, folder := range ordered {
 		if folder.ParentFolderId == "" {
 			root = folder
 			continue
@@ -150,18 +310,13 @@ LEFT JOIN files file ON file.folder_id = folder.id;`, username).
 	}
 
 	if root == nil {
-		return nil, fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		return fmt.Errorf("Error: The %v %w", fs.Username, app.ErrUserNotExists)
 	}
-
-	fs := &app.FileSystem{
-		Id:       root.FsId,
-		Username: username,
-		Root:     *root,
-	}
-	return fs, nil
+	fs.Root = *root
+	return nil
 }
 
-func (repo *FileSystemRepository) GetFileSystemByUsernameV3(ctx context.Context, username string) (*app.FileSystem, error) {
+func loadByRecursive(db *gorm.DB, fs *app.FileSystem) error {
 	type Mapper struct {
 		Id          string    `gorm:"column:id"`
 		ParentID    string    `gorm:"column:parent_id"`
@@ -169,57 +324,68 @@ func (repo *FileSystemRepository) GetFileSystemByUsernameV3(ctx context.Context,
 		Name        string    `gorm:"column:name"`
 		Description string    `gorm:"column:description"`
 		CreatedTime time.Time `gorm:"column:created_time"`
+		UpdatedTime time.Time `gorm:"column:updated_time"`
+		Size        int64     `gorm:"column:size"`
+		ContentType string    `gorm:"column:content_type"`
 		Kind        string    `gorm:"column:kind"`
 		Level       int       `gorm:"column:level"`
 	}
 	var rows []Mapper
 
-	err := repo.db.WithContext(ctx).Raw(`
+	err := db.Raw(`
 WITH RECURSIVE hierarchy AS (
  -- Anchor member: select the root nodes
  SELECT
-  d.id AS id,
-  parent_id,
-  fs_id,
-  d.name,
-  d.description,
-  d.created_time,
-  'd' AS kind,
+  d.id,
   0 AS level
- FROM file_systems fs
- JOIN folders d ON d.fs_id = fs.id AND fs.username = ?
- WHERE parent_id = ''
+ FROM folders d
+ WHERE d.fs_id = ? AND d.parent_id = ''
 
  UNION ALL
- -- Recursive member: select children of the current level
- SELECT
-  f.id,
-  f.folder_id AS parent_id,
-  f.fs_id,
-  f.name,
-  f.description,
-  f.created_time,
-  'f' AS kind,
-  h.level + 1 AS level
- FROM files f
- JOIN hierarchy h ON f.folder_id = h.id
- UNION ALL
+ -- Recursive member: select child folders of the current level,
+ -- Postgres allows only one recursive reference, so the files are joined outside,
+ -- and only the ids are carried, so the columns keep the types of the tables.
  SELECT
   d.id,
-  d.parent_id,
-  d.fs_id,
-  d.name,
-  d.description,
-  d.created_time,
-  'd' AS kind,
   h.level + 1 AS level
  FROM folders d
  JOIN hierarchy h ON d.parent_id = h.id
+ WHERE d.trash_id = ''
 )
-SELECT * FROM hierarchy;`, username).
+SELECT
+ d.id,
+ d.parent_id,
+ d.fs_id,
+ d.name,
+ d.description,
+ d.created_time,
+ d.updated_time,
+ 0 AS size,
+ '' AS content_type,
+ 'd' AS kind,
+ h.level
+FROM hierarchy h
+JOIN folders d ON d.id = h.id
+UNION ALL
+SELECT
+ f.id,
+ f.folder_id AS parent_id,
+ f.fs_id,
+ f.name,
+ f.description,
+ f.created_time,
+ f.updated_time,
+ f.size,
+ f.content_type,
+ 'f' AS kind,
+ h.level + 1 AS level
+FROM files f
+JOIN hierarchy h ON f.folder_id = h.id
+WHERE f.trash_id = ''
+ORDER BY level;`, fs.Id).
 		Scan(&rows).Error
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	var root *app.Folder
@@ -231,11 +397,12 @@ SELECT * FROM hierarchy;`, username).
 			Name:           rows[0].Name,
 			Description:    rows[0].Description,
 			CreatedTime:    rows[0].CreatedTime,
+			UpdatedTime:    rows[0].UpdatedTime,
 		}
 	}
 
 	if root == nil {
-		return nil, fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		return fmt.Errorf("Error: The %v %w", fs.Username, app.ErrUserNotExists)
 	}
 
 	folders := make(map[string]*app.Folder)
@@ -251,6 +418,7 @@ SELECT * FROM hierarchy;`, username).
 					Name:           rows[i].Name,
 					Description:    rows[i].Description,
 					CreatedTime:    rows[i].CreatedTime,
+					UpdatedTime:    rows[i].UpdatedTime,
 				}
 				folders[folder.Id] = folder
 				parent := folders[folder.ParentFolderId]
@@ -266,6 +434,9 @@ SELECT * FROM hierarchy;`, username).
 					Foldername:  parent.Name,
 					Description: rows[i].Description,
 					CreatedTime: rows[i].CreatedTime,
+					UpdatedTime: rows[i].UpdatedTime,
+					Size:        rows[i].Size,
+					ContentType: rows[i].ContentType,
 				}
 				parent.Files = append(parent.Files, file)
 			}
@@ -274,11 +445,7 @@ SELECT * FROM hierarchy;`, username).
 		level++
 	}
 
-	fs := &app.FileSystem{
-		Id:       root.FsId,
-		Username: username,
-		Root:     *root,
-	}
+	fs.Root = *root
 
 	// [
 	//  {
@@ -352,55 +519,367 @@ SELECT * FROM hierarchy;`, username).
 	//    "level": 2
 	//  }
 	// ]
+	return nil
+}
+
+func (repo *FileSystemRepository) FindFileSystem(ctx context.Context, username string) (*app.FileSystem, error) {
+	db := getDB(ctx, repo.db)
+
+	fs, err := takeFileSystem(db, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = takeRootFolder(db, fs)
+	if err != nil {
+		return nil, err
+	}
 	return fs, nil
 }
 
+// FindChildFolder is served by the unique index idx_folders_name.
+func (repo *FileSystemRepository) FindChildFolder(ctx context.Context, parent *app.Folder, name string) (*app.Folder, error) {
+	var folder app.Folder
+	err := getDB(ctx, repo.db).Table(FolderTable).
+		Where("fs_id = ? AND parent_id = ? AND LOWER(name) = LOWER(?) AND trash_id = ''", parent.FsId, parent.Id, name).
+		Take(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Error: The %v %w", name, app.ErrFolderNotExists)
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// FindChildFile is served by the unique index idx_files_name.
+func (repo *FileSystemRepository) FindChildFile(ctx context.Context, folder *app.Folder, name string) (*app.File, error) {
+	var file app.File
+	err := getDB(ctx, repo.db).Table(FileTable).
+		Where("folder_id = ? AND LOWER(name) = LOWER(?) AND trash_id = ''", folder.Id, name).
+		Take(&file).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Error: The %v %w", name, app.ErrFileNotExists)
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (repo *FileSystemRepository) FindFolder(ctx context.Context, fsId string, id string) (*app.Folder, error) {
+	var folder app.Folder
+	err := getDB(ctx, repo.db).Table(FolderTable).
+		Where("fs_id = ? AND id = ? AND trash_id = ''", fsId, id).
+		Take(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Error: The folder %v %w", id, app.ErrFolderNotExists)
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (repo *FileSystemRepository) ListChildFolders(ctx context.Context, parent *app.Folder, opts app.ListChildrenOptions) ([]*app.Folder, error) {
+	query, err := listChildren(getDB(ctx, repo.db), FolderTable, "parent_id", parent.Id, false, sqlOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []*app.Folder
+	err = query.Find(&folders).Error
+	if err != nil {
+		return nil, err
+	}
+	if opts.Sort.Value().Natural {
+		return (&app.Folder{Id: parent.Id, Folders: folders}).ListFolders(opts)
+	}
+	return folders, nil
+}
+
+func (repo *FileSystemRepository) ListChildFiles(ctx context.Context, folder *app.Folder, opts app.ListChildrenOptions) ([]*app.File, error) {
+	query, err := listChildren(getDB(ctx, repo.db), FileTable, "folder_id", folder.Id, true, sqlOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*app.File
+	err = query.Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	if opts.Sort.Value().Natural {
+		return (&app.Folder{Id: folder.Id, Files: files}).ListFiles(opts)
+	}
+	return files, nil
+}
+
+func (repo *FileSystemRepository) SumFolder(ctx context.Context, folder *app.Folder) (app.FolderUsage, error) {
+	var usage app.FolderUsage
+	err := getDB(ctx, repo.db).Raw(`
+WITH RECURSIVE subtree AS (
+ SELECT d.id
+ FROM folders d
+ WHERE d.id = ?
+
+ UNION ALL
+ SELECT d.id
+ FROM folders d
+ JOIN subtree s ON d.parent_id = s.id
+ WHERE d.trash_id = ''
+)
+SELECT
+ COALESCE(SUM(f.size), 0) AS size,
+ (SELECT COUNT(*) - 1 FROM subtree) AS folders,
+ COUNT(f.id) AS files
+FROM subtree s
+LEFT JOIN files f ON f.folder_id = s.id AND f.trash_id = '';`, folder.Id).
+		Scan(&usage).Error
+	if err != nil {
+		return app.FolderUsage{}, err
+	}
+	return usage, nil
+}
+
+func (repo *FileSystemRepository) SumFileSystem(ctx context.Context, fs *app.FileSystem) (app.FolderUsage, error) {
+	var usage app.FolderUsage
+	err := getDB(ctx, repo.db).Raw(`
+SELECT
+ (SELECT COALESCE(SUM(f.size), 0) FROM files f WHERE f.fs_id = ?) AS size,
+ (SELECT COUNT(*) FROM folders d WHERE d.fs_id = ? AND d.parent_id <> '') AS folders,
+ (SELECT COUNT(*) FROM files f WHERE f.fs_id = ?) AS files;`, fs.Id, fs.Id, fs.Id).
+		Scan(&usage).Error
+	if err != nil {
+		return app.FolderUsage{}, err
+	}
+	return usage, nil
+}
+
+// sortColumns maps the keys of app.FileSystemSortParams to the columns.
+var sortColumns = map[string]string{
+	app.SortKeyName:        "name",
+	app.SortKeyCreated:     "created_time",
+	app.SortKeySize:        "size",
+	app.SortKeyDescription: "description",
+}
+
+// sqlOptions leaves the sort and the page to app when the names are sorted naturally,
+// since SQL has no portable natural collation, only the filter is pushed into SQL.
+func sqlOptions(opts app.ListChildrenOptions) app.ListChildrenOptions {
+	if !opts.Sort.Value().Natural {
+		return opts
+	}
+	return app.ListChildrenOptions{Filter: opts.Filter}
+}
+
+// maxLimit stands for no limit, sqlite and mysql don't accept OFFSET without LIMIT.
+const maxLimit = math.MaxInt32
+
+// listChildren queries the live children under parentId,
+// and applies opts as app.Folder.ListFolders does.
+func listChildren(db *gorm.DB, table string, parentColumn string, parentId string, isFile bool, opts app.ListChildrenOptions) (*gorm.DB, error) {
+	err := opts.Sort.Validate(isFile)
+	if err != nil {
+		return nil, err
+	}
+	err = opts.Page.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Table(table).
+		Where(parentColumn+" = ? AND trash_id = ''", parentId)
+
+	filter := opts.Filter
+	if filter.Name != "" {
+		query = query.Where("LOWER(name) LIKE LOWER(?) ESCAPE '!'", likePattern(filter))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where(timeColumn(db, "created_time")+" > "+timeColumn(db, "?"), filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where(timeColumn(db, "created_time")+" < "+timeColumn(db, "?"), filter.CreatedBefore)
+	}
+
+	type sortColumn struct {
+		name string
+		desc bool
+	}
+	var columns []sortColumn
+	sort := opts.Sort.Value()
+	for _, k := range sort.Keys {
+		column := sortColumns[k.Key]
+		switch {
+		case k.Key == app.SortKeyCreated:
+			column = timeColumn(db, column)
+		case sort.IgnoreCase && (k.Key == app.SortKeyName || k.Key == app.SortKeyDescription):
+			column = "LOWER(" + column + ")"
+		}
+		columns = append(columns, sortColumn{column, k.IsDesc()})
+	}
+	columns = append(columns, sortColumn{"id", false})
+
+	cursor := opts.Page.Cursor
+	if cursor != "" {
+		var count int64
+		err = db.Table(table).
+			Where("id = ? AND "+parentColumn+" = ? AND trash_id = ''", cursor, parentId).
+			Count(&count).Error
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("Error: The cursor %v %w", cursor, app.ErrInvalidParams)
+		}
+
+		// the rows sorted after the cursor:
+		// (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ... OR (c1 = v1 AND ... AND id > cursor)
+		var or []string
+		var vars []any
+		for i, column := range columns {
+			var and []string
+			for _, prev := range columns[:i] {
+				and = append(and, fmt.Sprintf("%v = (SELECT %v FROM %v WHERE id = ?)", prev.name, prev.name, table))
+				vars = append(vars, cursor)
+			}
+			op := ">"
+			if column.desc {
+				op = "<"
+			}
+			and = append(and, fmt.Sprintf("%v %v (SELECT %v FROM %v WHERE id = ?)", column.name, op, column.name, table))
+			vars = append(vars, cursor)
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+		query = query.Where(strings.Join(or, " OR "), vars...)
+	}
+
+	for _, column := range columns {
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: column.name, Raw: true},
+			Desc:   column.desc,
+		})
+	}
+
+	limit := opts.Page.Limit
+	if limit <= 0 && opts.Page.Offset > 0 {
+		limit = maxLimit
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if opts.Page.Offset > 0 {
+		query = query.Offset(opts.Page.Offset)
+	}
+	return query, nil
+}
+
+// likePattern converts the name filter into a LIKE pattern escaped by "!",
+// the escape character is the same in every driver, unlike the backslash.
+func likePattern(filter app.ListFilterParams) string {
+	escaper := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	if !filter.IsGlob() {
+		return "%" + escaper.Replace(filter.Name) + "%"
+	}
+
+	var pattern strings.Builder
+	for _, char := range filter.Name {
+		switch char {
+		case '*':
+			pattern.WriteString("%")
+		case '?':
+			pattern.WriteString("_")
+		default:
+			pattern.WriteString(escaper.Replace(string(char)))
+		}
+	}
+	return pattern.String()
+}
+
+// timeColumn compares the times by their values in sqlite,
+// which stores them as text, and the texts in different time zones aren't ordered.
+func timeColumn(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == DriverSqlite {
+		return "julianday(" + column + ")"
+	}
+	return column
+}
+
 func (repo *FileSystemRepository) CreateFolder(ctx context.Context, folder *app.Folder) error {
-	err := repo.db.WithContext(ctx).Table(FolderTable).
+	err := getDB(ctx, repo.db).Table(FolderTable).
 		Create(folder).Error
 	if err != nil {
-		return err
+		return translateError(err, folder.Name, app.ErrFolderExists)
 	}
 	return nil
 }
 
-func (repo *FileSystemRepository) DeleteFolder(ctx context.Context, folder *app.Folder) error {
-	db := repo.db.WithContext(ctx)
+// TrashFolder marks the folder and its subtree with item.Id,
+// the files already in the trash keep their own TrashId.
+// The subtree is queried level by level, because the children of folder may not be loaded.
+func (repo *FileSystemRepository) TrashFolder(ctx context.Context, folder *app.Folder, item *app.TrashItem) error {
+	db := getDB(ctx, repo.db)
 
-	err := db.Table(FolderTable).
-		Delete(folder, "id = ?", folder.Id).Error
+	err := db.Table(TrashTable).
+		Create(item).Error
 	if err != nil {
 		return err
 	}
 
-	err = db.Table(FileTable).
-		Delete(&app.File{}, "folder_id = ?", folder.Id).Error
-	if err != nil {
-		return err
+	parents := []string{folder.Id}
+	for len(parents) > 0 {
+		var children []string
+		for start := 0; start < len(parents); start += preloadBatchSize {
+			batch := parents[start:min(start+preloadBatchSize, len(parents))]
+
+			var ids []string
+			err = db.Table(FolderTable).
+				Where("parent_id IN ? AND trash_id = ''", batch).
+				Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+			children = append(children, ids...)
+
+			err = db.Table(FolderTable).
+				Where("id IN ?", batch).
+				Update("trash_id", item.Id).Error
+			if err != nil {
+				return err
+			}
+
+			err = db.Table(FileTable).
+				Where("folder_id IN ? AND trash_id = ''", batch).
+				Update("trash_id", item.Id).Error
+			if err != nil {
+				return err
+			}
+		}
+		parents = children
 	}
 
 	return nil
 }
 
 func (repo *FileSystemRepository) UpdateFolder(ctx context.Context, folder *app.Folder) error {
-	db := repo.db.WithContext(ctx)
+	db := getDB(ctx, repo.db)
 
 	err := db.Table(FolderTable).
 		Omit("Files").
 		Where("id = ?", folder.Id).
 		Updates(folder.ByUpdate.StdMap()).Error
 	if err != nil {
-		return err
+		return translateError(err, folder.Name, app.ErrFolderExists)
 	}
 
-	if len(folder.Files) == 0 {
+	// the files carry the name of their folder, and they may not be loaded.
+	name, ok := folder.ByUpdate.StdMap()["name"]
+	if !ok {
 		return nil
 	}
-
-	file := folder.Files[0]
 	err = db.Table(FileTable).
 		Where("folder_id = ?", folder.Id).
-		Updates(file.ByUpdate.StdMap()).Error
+		Update("foldername", name).Error
 	if err != nil {
 		return err
 	}
@@ -409,19 +888,332 @@ func (repo *FileSystemRepository) UpdateFolder(ctx context.Context, folder *app.
 }
 
 func (repo *FileSystemRepository) CreateFile(ctx context.Context, file *app.File) error {
-	err := repo.db.WithContext(ctx).Table(FileTable).
+	err := getDB(ctx, repo.db).Table(FileTable).
 		Create(file).Error
+	if err != nil {
+		return translateError(err, file.Name, app.ErrFileExists)
+	}
+	return nil
+}
+
+func (repo *FileSystemRepository) TrashFile(ctx context.Context, file *app.File, item *app.TrashItem) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(TrashTable).
+		Create(item).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(FileTable).
+		Where("id = ?", file.Id).
+		Update("trash_id", item.Id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *FileSystemRepository) UpdateFile(ctx context.Context, file *app.File) error {
+	err := getDB(ctx, repo.db).Table(FileTable).
+		Where("id = ?", file.Id).
+		Updates(file.ByUpdate.StdMap()).Error
+	if err != nil {
+		return translateError(err, file.Name, app.ErrFileExists)
+	}
+	return nil
+}
+
+func (repo *FileSystemRepository) CopyFile(ctx context.Context, src *app.File, dst *app.File) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(FileTable).
+		Create(dst).Error
+	if err != nil {
+		return translateError(err, dst.Name, app.ErrFileExists)
+	}
+
+	err = db.Exec(`
+INSERT INTO file_contents (file_id, data)
+SELECT ?, data FROM file_contents WHERE file_id = ?;`, dst.Id, src.Id).Error
 	if err != nil {
 		return err
 	}
+
 	return nil
 }
 
-func (repo *FileSystemRepository) DeleteFile(ctx context.Context, file *app.File) error {
-	err := repo.db.WithContext(ctx).Table(FileTable).
-		Delete(file, "id = ?", file.Id).Error
+func (repo *FileSystemRepository) SaveFileContent(ctx context.Context, content *app.FileContent) error {
+	err := getDB(ctx, repo.db).Table(FileContentTable).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "file_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"data"}),
+		}).
+		Create(content).Error
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (repo *FileSystemRepository) GetFileContent(ctx context.Context, fileId string) (*app.FileContent, error) {
+	var content app.FileContent
+	err := getDB(ctx, repo.db).Table(FileContentTable).
+		Where("file_id = ?", fileId).
+		Take(&content).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the file has been created but never written
+			return &app.FileContent{FileId: fileId, Data: []byte{}}, nil
+		}
+		return nil, err
+	}
+	return &content, nil
+}
+
+func (repo *FileSystemRepository) ListGrants(ctx context.Context, fsId string, grantee string) ([]*app.Grant, error) {
+	var grants []*app.Grant
+	err := getDB(ctx, repo.db).Table(GrantTable).
+		Where("fs_id = ? AND LOWER(grantee) = LOWER(?)", fsId, grantee).
+		Find(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (repo *FileSystemRepository) SaveGrant(ctx context.Context, grant *app.Grant) error {
+	err := getDB(ctx, repo.db).Table(GrantTable).
+		Save(grant).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *FileSystemRepository) DeleteGrant(ctx context.Context, grant *app.Grant) error {
+	err := getDB(ctx, repo.db).Table(GrantTable).
+		Delete(grant, "id = ?", grant.Id).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *FileSystemRepository) ListSharedFolders(ctx context.Context, grantee string) ([]*app.SharedFolder, error) {
+	db := getDB(ctx, repo.db)
+
+	var shared []*app.SharedFolder
+	err := db.Table(GrantTable+" g").
+		Select("g.*, fs.username AS owner").
+		Joins("JOIN "+FileSystemTable+" fs ON fs.id = g.fs_id").
+		Joins("JOIN "+FolderTable+" d ON d.id = g.folder_id AND d.trash_id = ''").
+		Where("LOWER(g.grantee) = LOWER(?)", grantee).
+		Scan(&shared).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range shared {
+		folder.Path, err = folderPath(db, folder.Grant.FolderId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shared, nil
+}
+
+// folderPath walks up from the folder to the root folder by a recursive common table expression.
+func folderPath(db *gorm.DB, folderId string) (string, error) {
+	var names []string
+	err := db.Raw(`
+WITH RECURSIVE ancestors AS (
+ SELECT d.id, d.parent_id, d.name, 0 AS depth
+ FROM folders d
+ WHERE d.id = ?
+
+ UNION ALL
+ SELECT d.id, d.parent_id, d.name, a.depth + 1
+ FROM folders d
+ JOIN ancestors a ON d.id = a.parent_id
+)
+SELECT name FROM ancestors ORDER BY depth DESC;`, folderId).
+		Scan(&names).Error
+	if err != nil {
+		return "", err
+	}
+
+	// the first one is the root folder
+	if len(names) > 0 {
+		names = names[1:]
+	}
+	return "/" + strings.Join(names, "/"), nil
+}
+
+func (repo *FileSystemRepository) ListTrashItems(ctx context.Context, fsId string) ([]*app.TrashItem, error) {
+	var items []*app.TrashItem
+	err := getDB(ctx, repo.db).Table(TrashTable).
+		Where("fs_id = ?", fsId).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *FileSystemRepository) RestoreTrashItem(ctx context.Context, item *app.TrashItem) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(FolderTable).
+		Where("trash_id = ?", item.Id).
+		Update("trash_id", "").Error
+	if err != nil {
+		return translateError(err, item.Path, app.ErrFolderExists)
+	}
+
+	err = db.Table(FileTable).
+		Where("trash_id = ?", item.Id).
+		Update("trash_id", "").Error
+	if err != nil {
+		return translateError(err, item.Path, app.ErrFileExists)
+	}
+
+	err = db.Table(TrashTable).
+		Delete(item, "id = ?", item.Id).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeTrashItems permanently deletes the items and everything marked with their ids.
+// A folder or a file trashed before its parent folder still lives under the parent,
+// so everything under the purged folders is deleted too, together with its own trash items.
+func (repo *FileSystemRepository) PurgeTrashItems(ctx context.Context, items []*app.TrashItem) error {
+	db := getDB(ctx, repo.db)
+
+	trashIds := make([]string, len(items))
+	for i, item := range items {
+		trashIds[i] = item.Id
+	}
+
+	var folderIds []string
+	err := db.Table(FolderTable).
+		Where("trash_id IN ?", trashIds).
+		Pluck("id", &folderIds).Error
+	if err != nil {
+		return err
+	}
+
+	// the folders trashed before their ancestors have other trash ids
+	for parents := folderIds; len(parents) > 0; {
+		var children []string
+		for start := 0; start < len(parents); start += preloadBatchSize {
+			batch := parents[start:min(start+preloadBatchSize, len(parents))]
+
+			var ids []string
+			err = db.Table(FolderTable).
+				Where("parent_id IN ? AND trash_id NOT IN ?", batch, trashIds).
+				Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+			children = append(children, ids...)
+		}
+		folderIds = append(folderIds, children...)
+		parents = children
+	}
+
+	purgedIds := slices.Clone(trashIds)
+	for start := 0; start < len(folderIds); start += preloadBatchSize {
+		batch := folderIds[start:min(start+preloadBatchSize, len(folderIds))]
+
+		var ids []string
+		err = db.Table(FolderTable).
+			Distinct("trash_id").
+			Where("id IN ?", batch).
+			Pluck("trash_id", &ids).Error
+		if err != nil {
+			return err
+		}
+		purgedIds = append(purgedIds, ids...)
+
+		err = db.Table(FileTable).
+			Distinct("trash_id").
+			Where("folder_id IN ?", batch).
+			Pluck("trash_id", &ids).Error
+		if err != nil {
+			return err
+		}
+		purgedIds = append(purgedIds, ids...)
+
+		err = db.Table(FileContentTable).
+			Where("file_id IN (?)", db.Table(FileTable).Select("id").Where("folder_id IN ?", batch)).
+			Delete(&app.FileContent{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = db.Table(FileTable).
+			Where("folder_id IN ?", batch).
+			Delete(&app.File{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = db.Table(GrantTable).
+			Where("folder_id IN ?", batch).
+			Delete(&app.Grant{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = db.Table(FolderTable).
+			Where("id IN ?", batch).
+			Delete(&app.Folder{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// the files trashed by themselves
+	err = db.Table(FileContentTable).
+		Where("file_id IN (?)", db.Table(FileTable).Select("id").Where("trash_id IN ?", trashIds)).
+		Delete(&app.FileContent{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(FileTable).
+		Where("trash_id IN ?", trashIds).
+		Delete(&app.File{}).Error
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(purgedIds)
+	purgedIds = slices.Compact(purgedIds)
+	for start := 0; start < len(purgedIds); start += preloadBatchSize {
+		batch := purgedIds[start:min(start+preloadBatchSize, len(purgedIds))]
+
+		err = db.Table(TrashTable).
+			Where("id IN ?", batch).
+			Delete(&app.TrashItem{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// translateError reports the unique constraint violation as exists,
+// it happens when another process has created the same name after the checks of app.
+func translateError(err error, name string, exists error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("Error: The %v %w", name, exists)
+	}
+	return err
+}
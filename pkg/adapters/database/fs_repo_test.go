@@ -0,0 +1,130 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// The unique indexes reject the duplicated names which bypass the checks of app,
+// such as two processes creating the same name at the same time.
+func TestFileSystemRepository_uniqueIndex(t *testing.T) {
+	db, err := database.NewGrom(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+
+	testUniqueIndex(t, db)
+}
+
+func testUniqueIndex(t *testing.T, db *gorm.DB) {
+	ctx := context.Background()
+	userRepo := database.NewUserRepository(db)
+	fsRepo := database.NewFileSystemRepository(db)
+	now := time.Now()
+
+	err := userRepo.CreateUser(ctx, &app.User{Username: "user1", CreatedTime: now})
+	require.NoError(t, err)
+	err = userRepo.CreateUser(ctx, &app.User{Username: "USER1", CreatedTime: now})
+	require.ErrorIs(t, err, app.ErrUserExists)
+
+	folder := &app.Folder{Id: "01HYXCD1CD3VFFRYB9BWV19TM8", ParentFolderId: "root", FsId: "fs", Name: "home", CreatedTime: now}
+	err = fsRepo.CreateFolder(ctx, folder)
+	require.NoError(t, err)
+	err = fsRepo.CreateFolder(ctx, &app.Folder{Id: "01HYXCD1CGB36V08CNRGJQMZHT", ParentFolderId: "root", FsId: "fs", Name: "HOME", CreatedTime: now})
+	require.ErrorIs(t, err, app.ErrFolderExists)
+
+	file := &app.File{Id: "01HYYMFNZSFQ2FWPN1DYFTPADH", FolderId: folder.Id, FsId: "fs", Name: "dev.conf", CreatedTime: now}
+	err = fsRepo.CreateFile(ctx, file)
+	require.NoError(t, err)
+	err = fsRepo.CreateFile(ctx, &app.File{Id: "01HYYMMTX8F4D2BESDCAD2YXS5", FolderId: folder.Id, FsId: "fs", Name: "Dev.Conf", CreatedTime: now})
+	require.ErrorIs(t, err, app.ErrFileExists)
+
+	// the same name is allowed once the file has been moved to the trash
+	err = fsRepo.TrashFile(ctx, file, &app.TrashItem{Id: "01HYYMN2H854NWJJ32HJRCQKC0", FsId: "fs", Kind: app.TrashKind_File, TargetId: file.Id, ParentId: folder.Id, Path: "/home/dev.conf", DeletedTime: now})
+	require.NoError(t, err)
+	err = fsRepo.CreateFile(ctx, &app.File{Id: "01HYYMMTX8F4D2BESDCAD2YXS5", FolderId: folder.Id, FsId: "fs", Name: "Dev.Conf", CreatedTime: now})
+	require.NoError(t, err)
+}
+
+// BenchmarkFileSystemRepository_LoadFileSystem compares the load strategies over a generated tree,
+// the fastest one is chosen as the default strategy.
+//
+//	go test ./pkg/adapters/database -run ^$ -bench LoadFileSystem -benchmem
+func BenchmarkFileSystemRepository_LoadFileSystem(b *testing.B) {
+	db, err := database.NewGrom(&database.GormConfing{
+		Dsn:     filepath.Join(b.TempDir(), "bench.db"),
+		Migrate: true,
+	})
+	require.NoError(b, err)
+
+	const (
+		folderCount = 10000
+		fileCount   = 100000
+		fanout      = 10
+	)
+	now := time.Now()
+	fsId := pkg.NewUlid()
+
+	err = db.Table(database.UserTable).Create(&app.User{Username: "user1", CreatedTime: now}).Error
+	require.NoError(b, err)
+	err = db.Table(database.FileSystemTable).Create(map[string]any{"id": fsId, "username": "user1"}).Error
+	require.NoError(b, err)
+
+	folders := make([]*app.Folder, folderCount+1)
+	folders[0] = &app.Folder{Id: pkg.NewUlid(), FsId: fsId, Name: "/", CreatedTime: now}
+	for i := 1; i <= folderCount; i++ {
+		folders[i] = &app.Folder{
+			Id:             pkg.NewUlid(),
+			ParentFolderId: folders[(i-1)/fanout].Id,
+			FsId:           fsId,
+			Name:           fmt.Sprintf("folder%v", i),
+			CreatedTime:    now,
+		}
+	}
+	err = db.Table(database.FolderTable).Omit("Files", "Folders").CreateInBatches(folders, 500).Error
+	require.NoError(b, err)
+
+	files := make([]*app.File, fileCount)
+	for i := range files {
+		folder := folders[i%len(folders)]
+		files[i] = &app.File{
+			Id:          pkg.NewUlid(),
+			FolderId:    folder.Id,
+			FsId:        fsId,
+			Name:        fmt.Sprintf("file%v", i),
+			Foldername:  folder.Name,
+			CreatedTime: now,
+		}
+	}
+	err = db.Table(database.FileTable).CreateInBatches(files, 500).Error
+	require.NoError(b, err)
+
+	repo := database.NewFileSystemRepository(db)
+	ctx := context.Background()
+
+	for _, strategy := range app.LoadStrategies {
+		b.Run(string(strategy), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fs, err := repo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{Strategy: strategy})
+				if err != nil {
+					b.Fatal(err)
+				}
+				folders, files := fs.Count()
+				if folders != folderCount || files != fileCount {
+					b.Fatalf("folders=%v files=%v", folders, files)
+				}
+			}
+		})
+	}
+}
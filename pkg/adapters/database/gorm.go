@@ -1,20 +1,58 @@
 package database
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
 	"gorm.io/gorm"
 
 	"github.com/KScaesar/IsCoolLab2024/pkg"
-	"github.com/KScaesar/IsCoolLab2024/pkg/app"
 )
 
+const (
+	DriverSqlite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMysql    = "mysql"
+)
+
+// MysqlMinVersion is the first mysql version which supports
+// the functional key parts of the case-insensitive unique indexes.
+const MysqlMinVersion = "8.0.13"
+
+var ErrMysqlVersion = errors.New("is not supported, please use MySQL " + MysqlMinVersion + " or later.")
+
 type GormConfing struct {
-	Dsn     string
+	// Driver is one of DriverSqlite, DriverPostgres and DriverMysql,
+	// empty means DriverSqlite.
+	Driver string
+	Dsn    string
+
+	// Migrate applies the pending migrations when the database is opened,
+	// otherwise the schema is only changed by `vFS migrate`.
 	Migrate bool
 	Debug   bool
 }
 
 func NewGrom(conf *GormConfing) (*gorm.DB, error) {
-	db, err := pkg.NewSqliteGorm(conf.Dsn, conf.Debug)
+	var db *gorm.DB
+	var err error
+	switch conf.Driver {
+	case "", DriverSqlite:
+		db, err = pkg.NewSqliteGorm(conf.Dsn, conf.Debug)
+	case DriverPostgres:
+		db, err = pkg.NewPostgresGorm(conf.Dsn, conf.Debug)
+	case DriverMysql:
+		db, err = pkg.NewMysqlGorm(conf.Dsn, conf.Debug)
+		if err == nil {
+			err = checkMysqlVersion(db)
+		}
+	default:
+		return nil, fmt.Errorf("Error: The driver %v is not supported, please use %v, %v or %v.", conf.Driver, DriverSqlite, DriverPostgres, DriverMysql)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -23,15 +61,49 @@ func NewGrom(conf *GormConfing) (*gorm.DB, error) {
 		return db, nil
 	}
 
-	err = db.AutoMigrate(
-		app.User{},
-		app.FileSystem{},
-		app.Folder{},
-		app.File{},
-	)
+	_, err = NewMigrator(db).Up(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
+
+func checkMysqlVersion(db *gorm.DB) error {
+	var version string
+	err := db.Raw("SELECT VERSION()").Scan(&version).Error
+	if err != nil {
+		return err
+	}
+	if !mysqlVersionSupported(version) {
+		return fmt.Errorf("Error: The mysql version %v %w", version, ErrMysqlVersion)
+	}
+	return nil
+}
+
+// mysqlVersionSupported compares a version like "8.0.36-log" with MysqlMinVersion,
+// MariaDB doesn't support the functional key parts at all.
+func mysqlVersionSupported(version string) bool {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+
+	parse := func(version string) []int {
+		version, _, _ = strings.Cut(version, "-")
+		var numbers []int
+		for _, part := range strings.Split(version, ".") {
+			number, err := strconv.Atoi(part)
+			if err != nil {
+				return nil
+			}
+			numbers = append(numbers, number)
+		}
+		return numbers
+	}
+
+	numbers := parse(version)
+	if len(numbers) != 3 {
+		return false
+	}
+	return slices.Compare(numbers, parse(MysqlMinVersion)) >= 0
+}
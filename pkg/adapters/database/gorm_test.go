@@ -0,0 +1,28 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mysqlVersionSupported(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "8.0.13", want: true},
+		{version: "8.0.36-log", want: true},
+		{version: "8.4.0", want: true},
+		{version: "9.0.1", want: true},
+		{version: "8.0.12", want: false},
+		{version: "5.7.44-log", want: false},
+		{version: "10.11.6-MariaDB", want: false},
+		{version: "unknown", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, mysqlVersionSupported(tt.version))
+		})
+	}
+}
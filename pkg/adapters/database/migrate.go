@@ -0,0 +1,25 @@
+package database
+
+import (
+	"embed"
+
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+var (
+	//go:embed migrations
+	migrationFiles embed.FS
+
+	// every driver has the same versions, only the sql dialect is different.
+	migrations = map[string][]pkg.Migration{
+		DriverSqlite:   pkg.MustLoadMigrations(migrationFiles, "migrations/sqlite"),
+		DriverPostgres: pkg.MustLoadMigrations(migrationFiles, "migrations/postgres"),
+		DriverMysql:    pkg.MustLoadMigrations(migrationFiles, "migrations/mysql"),
+	}
+)
+
+func NewMigrator(db *gorm.DB) *pkg.Migrator {
+	return pkg.NewMigrator(db, migrations[db.Dialector.Name()])
+}
@@ -0,0 +1,37 @@
+CREATE TABLE users (
+  username varchar(64) NOT NULL,
+  PRIMARY KEY (username)
+);
+
+CREATE TABLE file_systems (
+  id       char(26)    NOT NULL,
+  username varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_file_systems_username ON file_systems (username);
+
+-- datetime(3) keeps the milliseconds, the values are written in UTC by the driver.
+CREATE TABLE folders (
+  id           char(26)      NOT NULL,
+  parent_id    char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  name         varchar(256)  NOT NULL,
+  description  varchar(1024) NOT NULL,
+  created_time datetime(3)   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_folders_parent_folder_id ON folders (parent_id);
+CREATE INDEX idx_folders_fs_id ON folders (fs_id);
+
+CREATE TABLE files (
+  id           char(26)      NOT NULL,
+  folder_id    char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  name         varchar(256)  NOT NULL,
+  foldername   varchar(256)  NOT NULL,
+  description  varchar(1024) NOT NULL,
+  created_time datetime(3)   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_files_folder_id ON files (folder_id);
+CREATE INDEX idx_files_fs_id ON files (fs_id);
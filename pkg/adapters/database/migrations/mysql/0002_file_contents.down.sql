@@ -0,0 +1,3 @@
+DROP TABLE file_contents;
+
+ALTER TABLE files DROP COLUMN size;
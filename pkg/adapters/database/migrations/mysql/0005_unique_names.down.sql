@@ -0,0 +1,3 @@
+DROP INDEX idx_files_name ON files;
+DROP INDEX idx_folders_name ON folders;
+DROP INDEX idx_users_name ON users;
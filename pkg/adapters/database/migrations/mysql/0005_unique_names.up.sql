@@ -0,0 +1,7 @@
+-- Backs the case-insensitive uniqueness rules of app,
+-- so concurrent processes can't race past the checks in memory.
+-- The items in the trash have their own trash_id, so they don't conflict with the live items.
+-- The functional key parts require MySQL 8.0.13 or later.
+CREATE UNIQUE INDEX idx_users_name ON users ((LOWER(username)));
+CREATE UNIQUE INDEX idx_folders_name ON folders (fs_id, parent_id, (LOWER(name)), trash_id);
+CREATE UNIQUE INDEX idx_files_name ON files (folder_id, (LOWER(name)), trash_id);
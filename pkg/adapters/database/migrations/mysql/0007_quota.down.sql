@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN max_bytes;
+ALTER TABLE users DROP COLUMN max_files;
+ALTER TABLE users DROP COLUMN max_folders;
@@ -0,0 +1,13 @@
+-- A folder has at most one grant for each grantee, the subfolders inherit it.
+CREATE TABLE folder_grants (
+  id           char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  folder_id    char(26)      NOT NULL,
+  grantee      varchar(64)   NOT NULL,
+  permission   varchar(16)   NOT NULL,
+  created_time datetime(3)   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_folder_grants_fs_id ON folder_grants (fs_id);
+CREATE INDEX idx_folder_grants_grantee ON folder_grants (grantee);
+CREATE UNIQUE INDEX idx_folder_grants_folder ON folder_grants (folder_id, (LOWER(grantee)));
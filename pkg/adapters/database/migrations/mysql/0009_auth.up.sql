@@ -0,0 +1,12 @@
+-- An empty password_hash means the user doesn't have a password, so the existing users keep working until they set one.
+ALTER TABLE users ADD COLUMN password_hash varchar(72) NOT NULL DEFAULT '';
+
+-- Only the sha256 of the session token is stored.
+CREATE TABLE sessions (
+  token_hash   char(64)      NOT NULL,
+  username     varchar(64)   NOT NULL,
+  created_time datetime(3)   NOT NULL,
+  expired_time datetime(3)   NOT NULL,
+  PRIMARY KEY (token_hash)
+);
+CREATE INDEX idx_sessions_username ON sessions (username);
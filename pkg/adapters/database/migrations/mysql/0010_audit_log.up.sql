@@ -0,0 +1,16 @@
+-- Append-only: vFS only inserts and selects the rows, and keeps them after the user is deleted.
+-- An empty actor means the change was made without login.
+CREATE TABLE audit_log (
+  id           char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  username     varchar(64)   NOT NULL,
+  actor        varchar(64)   NOT NULL,
+  action       varchar(32)   NOT NULL,
+  target_id    char(26)      NOT NULL,
+  parent_id    char(26)      NOT NULL,
+  before_value varchar(4096) NOT NULL,
+  after_value  varchar(4096) NOT NULL,
+  created_time datetime(3)   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_audit_log_fs_id ON audit_log (fs_id, created_time);
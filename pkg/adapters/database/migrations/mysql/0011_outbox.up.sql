@@ -0,0 +1,17 @@
+-- The events are inserted in the transaction which raised them, and dispatched after the commit.
+-- An empty dispatched_time means the event is pending, it is given up after too many attempts.
+CREATE TABLE outbox (
+  id              char(26)      NOT NULL,
+  name            varchar(32)   NOT NULL,
+  fs_id           char(26)      NOT NULL,
+  target_id       char(26)      NOT NULL,
+  parent_id       char(26)      NOT NULL,
+  path            varchar(4096) NOT NULL,
+  old_path        varchar(4096) NOT NULL,
+  occurred_time   datetime(3)   NOT NULL,
+  attempts        integer       NOT NULL,
+  last_error      varchar(1024) NOT NULL,
+  dispatched_time datetime(3)   NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_outbox_dispatched_time ON outbox (dispatched_time, occurred_time);
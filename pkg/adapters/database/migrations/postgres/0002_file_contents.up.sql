@@ -0,0 +1,7 @@
+ALTER TABLE files ADD COLUMN size bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE file_contents (
+  file_id varchar(26) NOT NULL,
+  data    bytea       NOT NULL,
+  PRIMARY KEY (file_id)
+);
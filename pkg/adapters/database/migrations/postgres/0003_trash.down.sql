@@ -0,0 +1,11 @@
+-- The schema without trash can't hold the deleted items, so they are removed permanently.
+DELETE FROM file_contents WHERE file_id IN (SELECT id FROM files WHERE trash_id <> '');
+DELETE FROM files WHERE trash_id <> '';
+DELETE FROM folders WHERE trash_id <> '';
+DROP TABLE trash_items;
+
+DROP INDEX idx_files_trash_id;
+ALTER TABLE files DROP COLUMN trash_id;
+
+DROP INDEX idx_folders_trash_id;
+ALTER TABLE folders DROP COLUMN trash_id;
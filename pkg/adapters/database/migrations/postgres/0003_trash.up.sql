@@ -0,0 +1,18 @@
+ALTER TABLE folders ADD COLUMN trash_id varchar(26) NOT NULL DEFAULT '';
+CREATE INDEX idx_folders_trash_id ON folders (trash_id);
+
+ALTER TABLE files ADD COLUMN trash_id varchar(26) NOT NULL DEFAULT '';
+CREATE INDEX idx_files_trash_id ON files (trash_id);
+
+CREATE TABLE trash_items (
+  id           varchar(26)   NOT NULL,
+  fs_id        varchar(26)   NOT NULL,
+  kind         varchar(16)   NOT NULL,
+  target_id    varchar(26)   NOT NULL,
+  parent_id    varchar(26)   NOT NULL,
+  path         varchar(4096) NOT NULL,
+  deleted_time timestamptz   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_trash_items_fs_id ON trash_items (fs_id);
+CREATE INDEX idx_trash_items_deleted_time ON trash_items (deleted_time);
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN created_time timestamptz NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+
+-- The root folder is created together with the user.
+UPDATE users
+SET created_time = (
+  SELECT d.created_time
+  FROM file_systems fs
+  JOIN folders d ON d.fs_id = fs.id AND d.parent_id = ''
+  WHERE fs.username = users.username
+)
+WHERE EXISTS (SELECT 1 FROM file_systems fs WHERE fs.username = users.username);
@@ -0,0 +1,3 @@
+DROP INDEX idx_files_name;
+DROP INDEX idx_folders_name;
+DROP INDEX idx_users_name;
@@ -0,0 +1,8 @@
+ALTER TABLE folders ADD COLUMN updated_time timestamptz NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE folders SET updated_time = created_time;
+
+ALTER TABLE files ADD COLUMN updated_time timestamptz NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE files SET updated_time = created_time;
+
+-- The content of the existing files isn't sniffed, so their content type is unknown.
+ALTER TABLE files ADD COLUMN content_type varchar(256) NOT NULL DEFAULT 'application/octet-stream';
@@ -0,0 +1,4 @@
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
+ALTER TABLE outbox DROP COLUMN actor;
+ALTER TABLE outbox DROP COLUMN username;
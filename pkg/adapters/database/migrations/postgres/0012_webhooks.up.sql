@@ -0,0 +1,32 @@
+-- The username and the actor of an event are recorded by the use case, like audit_log.
+ALTER TABLE outbox ADD COLUMN username varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE outbox ADD COLUMN actor varchar(64) NOT NULL DEFAULT '';
+
+-- The secret signs the payloads, so it is stored as it is.
+CREATE TABLE webhooks (
+  id             varchar(26)   NOT NULL,
+  fs_id          varchar(26)   NOT NULL,
+  url            varchar(2048) NOT NULL,
+  events         varchar(512)  NOT NULL,
+  secret         varchar(64)   NOT NULL,
+  created_time   timestamptz   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_webhooks_fs_id ON webhooks (fs_id);
+
+-- An empty error_message means the event was delivered.
+CREATE TABLE webhook_deliveries (
+  id             varchar(26)   NOT NULL,
+  webhook_id     varchar(26)   NOT NULL,
+  fs_id          varchar(26)   NOT NULL,
+  event_id       varchar(26)   NOT NULL,
+  event_name     varchar(32)   NOT NULL,
+  url            varchar(2048) NOT NULL,
+  attempts       integer       NOT NULL,
+  status_code    integer       NOT NULL,
+  error_message  varchar(1024) NOT NULL,
+  delivered_time timestamptz   NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX idx_webhook_deliveries_fs_id ON webhook_deliveries (fs_id, delivered_time);
+CREATE INDEX idx_webhook_deliveries_event_id ON webhook_deliveries (webhook_id, event_id);
@@ -0,0 +1,2 @@
+-- The existing users are plain users, the next user registered while there are no admins becomes the admin.
+ALTER TABLE users ADD COLUMN role varchar(16) NOT NULL DEFAULT 'user';
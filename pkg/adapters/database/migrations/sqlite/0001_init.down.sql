@@ -0,0 +1,4 @@
+DROP TABLE files;
+DROP TABLE folders;
+DROP TABLE file_systems;
+DROP TABLE users;
@@ -0,0 +1,37 @@
+-- IF NOT EXISTS adopts the databases which were created by AutoMigrate before the migrations.
+CREATE TABLE IF NOT EXISTS users (
+  username varchar(64) NOT NULL,
+  PRIMARY KEY (username)
+);
+
+CREATE TABLE IF NOT EXISTS file_systems (
+  id       char(26)    NOT NULL,
+  username varchar(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_systems_username ON file_systems (username);
+
+CREATE TABLE IF NOT EXISTS folders (
+  id           char(26)      NOT NULL,
+  parent_id    char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  name         varchar(256)  NOT NULL,
+  description  varchar(1024) NOT NULL,
+  created_time datetime      NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_folders_parent_folder_id ON folders (parent_id);
+CREATE INDEX IF NOT EXISTS idx_folders_fs_id ON folders (fs_id);
+
+CREATE TABLE IF NOT EXISTS files (
+  id           char(26)      NOT NULL,
+  folder_id    char(26)      NOT NULL,
+  fs_id        char(26)      NOT NULL,
+  name         varchar(256)  NOT NULL,
+  foldername   varchar(256)  NOT NULL,
+  description  varchar(1024) NOT NULL,
+  created_time datetime      NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_files_folder_id ON files (folder_id);
+CREATE INDEX IF NOT EXISTS idx_files_fs_id ON files (fs_id);
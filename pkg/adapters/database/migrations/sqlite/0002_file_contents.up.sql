@@ -0,0 +1,7 @@
+ALTER TABLE files ADD COLUMN size integer NOT NULL DEFAULT 0;
+
+CREATE TABLE file_contents (
+  file_id char(26) NOT NULL,
+  data    blob     NOT NULL,
+  PRIMARY KEY (file_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN created_time;
@@ -0,0 +1,3 @@
+ALTER TABLE files DROP COLUMN content_type;
+ALTER TABLE files DROP COLUMN updated_time;
+ALTER TABLE folders DROP COLUMN updated_time;
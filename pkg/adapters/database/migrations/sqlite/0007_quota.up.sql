@@ -0,0 +1,4 @@
+-- A zero limit is unlimited, so the existing users keep no limits.
+ALTER TABLE users ADD COLUMN max_folders integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN max_files integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN max_bytes integer NOT NULL DEFAULT 0;
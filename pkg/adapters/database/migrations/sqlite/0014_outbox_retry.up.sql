@@ -0,0 +1,3 @@
+-- A failed event waits until next_attempt_time, so the relay retries it without sleeping.
+-- An empty next_attempt_time means the event is due.
+ALTER TABLE outbox ADD COLUMN next_attempt_time datetime NULL;
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+const (
+	OutboxTable = "outbox"
+)
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func (repo *OutboxRepository) AppendOutbox(ctx context.Context, events []app.Event) error {
+	rows := make([]*app.OutboxEvent, len(events))
+	for i, event := range events {
+		rows[i] = &app.OutboxEvent{Event: event}
+	}
+
+	err := getDB(ctx, repo.db).Table(OutboxTable).
+		Create(rows).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *OutboxRepository) ListPendingOutbox(ctx context.Context, maxAttempts int, now time.Time) ([]*app.OutboxEvent, error) {
+	var events []*app.OutboxEvent
+	err := getDB(ctx, repo.db).Table(OutboxTable).
+		Where("dispatched_time IS NULL AND attempts < ?", maxAttempts).
+		Where("next_attempt_time IS NULL OR next_attempt_time <= ?", now).
+		Order("occurred_time, id").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (repo *OutboxRepository) UpdateOutbox(ctx context.Context, event *app.OutboxEvent) error {
+	err := getDB(ctx, repo.db).Table(OutboxTable).
+		Where("id = ?", event.Id).
+		Updates(map[string]any{
+			"attempts":          event.Attempts,
+			"last_error":        event.LastError,
+			"dispatched_time":   event.DispatchedTime,
+			"next_attempt_time": event.NextAttemptTime,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// WithTx runs fn inside gorm.DB.Transaction.
+// A nested call joins the transaction which is already carried by ctx.
+func (uow *UnitOfWork) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if ok {
+		return fn(ctx)
+	}
+
+	return uow.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// getDB returns the transaction carried by ctx,
+// or db bound to ctx when the caller isn't inside WithTx.
+func getDB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
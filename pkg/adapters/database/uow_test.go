@@ -0,0 +1,47 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func TestUnitOfWork_WithTx(t *testing.T) {
+	db, err := database.NewGrom(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+
+	uow := database.NewUnitOfWork(db)
+	userRepo := database.NewUserRepository(db)
+	ctx := context.Background()
+
+	errRollback := errors.New("rollback")
+	err = uow.WithTx(ctx, func(ctx context.Context) error {
+		err := userRepo.CreateUser(ctx, &app.User{Username: "user1"})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	_, err = userRepo.QueryUserByName(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrUserNotExists, "rollback")
+
+	err = uow.WithTx(ctx, func(ctx context.Context) error {
+		return uow.WithTx(ctx, func(ctx context.Context) error {
+			return userRepo.CreateUser(ctx, &app.User{Username: "user1"})
+		})
+	})
+	require.NoError(t, err)
+
+	_, err = userRepo.QueryUserByName(ctx, "user1")
+	require.NoError(t, err, "commit")
+}
@@ -11,7 +11,8 @@ import (
 )
 
 const (
-	UserTable = "users"
+	UserTable    = "users"
+	SessionTable = "sessions"
 )
 
 func NewUserRepository(db *gorm.DB) *UserRepository {
@@ -23,18 +24,18 @@ type UserRepository struct {
 }
 
 func (repo *UserRepository) CreateUser(ctx context.Context, user *app.User) error {
-	err := repo.db.WithContext(ctx).Table(UserTable).
+	err := getDB(ctx, repo.db).Table(UserTable).
 		Create(user).Error
 	if err != nil {
-		return err
+		return translateError(err, user.Username, app.ErrUserExists)
 	}
 	return nil
 }
 
 func (repo *UserRepository) QueryUserByName(ctx context.Context, username string) (*app.User, error) {
 	var user app.User
-	err := repo.db.WithContext(ctx).Table(UserTable).
-		Where("username = ?", username).
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Where("LOWER(username) = LOWER(?)", username).
 		Take(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -44,3 +45,148 @@ func (repo *UserRepository) QueryUserByName(ctx context.Context, username string
 	}
 	return &user, nil
 }
+
+func (repo *UserRepository) ListUsers(ctx context.Context) ([]*app.User, error) {
+	var users []*app.User
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Order("username").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (repo *UserRepository) DeleteUser(ctx context.Context, user *app.User) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(UserTable).
+		Delete(user, "username = ?", user.Username).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(GrantTable).
+		Delete(&app.Grant{}, "grantee = ?", user.Username).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(SessionTable).
+		Delete(&app.Session{}, "username = ?", user.Username).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *UserRepository) RenameUser(ctx context.Context, user *app.User, newUsername string) error {
+	db := getDB(ctx, repo.db)
+
+	err := db.Table(UserTable).
+		Where("username = ?", user.Username).
+		Update("username", newUsername).Error
+	if err != nil {
+		return translateError(err, newUsername, app.ErrUserExists)
+	}
+
+	err = db.Table(FileSystemTable).
+		Where("username = ?", user.Username).
+		Update("username", newUsername).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(GrantTable).
+		Where("grantee = ?", user.Username).
+		Update("grantee", newUsername).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Table(SessionTable).
+		Where("username = ?", user.Username).
+		Update("username", newUsername).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo *UserRepository) UpdateQuota(ctx context.Context, user *app.User) error {
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Where("username = ?", user.Username).
+		Updates(map[string]any{
+			"max_folders": user.Quota.MaxFolders,
+			"max_files":   user.Quota.MaxFiles,
+			"max_bytes":   user.Quota.MaxBytes,
+		}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *UserRepository) UpdatePassword(ctx context.Context, user *app.User) error {
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Where("username = ?", user.Username).
+		Update("password_hash", user.PasswordHash).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *UserRepository) UpdateRole(ctx context.Context, user *app.User) error {
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Where("username = ?", user.Username).
+		Update("role", user.Role).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *UserRepository) CountAdmins(ctx context.Context) (int, error) {
+	var count int64
+	err := getDB(ctx, repo.db).Table(UserTable).
+		Where("role = ?", app.Role_Admin).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (repo *UserRepository) CreateSession(ctx context.Context, session *app.Session) error {
+	err := getDB(ctx, repo.db).Table(SessionTable).
+		Create(session).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *UserRepository) FindSession(ctx context.Context, tokenHash string) (*app.Session, error) {
+	var session app.Session
+	err := getDB(ctx, repo.db).Table(SessionTable).
+		Where("token_hash = ?", tokenHash).
+		Take(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Error: The session %w", app.ErrSessionNotExists)
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (repo *UserRepository) DeleteSession(ctx context.Context, session *app.Session) error {
+	err := getDB(ctx, repo.db).Table(SessionTable).
+		Delete(session, "token_hash = ?", session.TokenHash).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+const (
+	WebhookTable         = "webhooks"
+	WebhookDeliveryTable = "webhook_deliveries"
+)
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func (repo *WebhookRepository) CreateWebhook(ctx context.Context, hook *app.Webhook) error {
+	err := getDB(ctx, repo.db).Table(WebhookTable).
+		Create(hook).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) ListWebhooks(ctx context.Context, fsId string) ([]*app.Webhook, error) {
+	var hooks []*app.Webhook
+	err := getDB(ctx, repo.db).Table(WebhookTable).
+		Where("fs_id = ?", fsId).
+		Order("created_time, id").
+		Find(&hooks).Error
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (repo *WebhookRepository) DeleteWebhook(ctx context.Context, hook *app.Webhook) error {
+	err := getDB(ctx, repo.db).Table(WebhookTable).
+		Delete(hook, "id = ?", hook.Id).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) AppendDelivery(ctx context.Context, delivery *app.WebhookDelivery) error {
+	err := getDB(ctx, repo.db).Table(WebhookDeliveryTable).
+		Create(delivery).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repo *WebhookRepository) ListDeliveries(ctx context.Context, fsId string) ([]*app.WebhookDelivery, error) {
+	var deliveries []*app.WebhookDelivery
+	err := getDB(ctx, repo.db).Table(WebhookDeliveryTable).
+		Where("fs_id = ?", fsId).
+		Order("delivered_time, id").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (repo *WebhookRepository) CountDeliveries(ctx context.Context, webhookId string, eventId string) (int, bool, error) {
+	var result struct {
+		Attempts  int64
+		Delivered int64
+	}
+	err := getDB(ctx, repo.db).Table(WebhookDeliveryTable).
+		Select("COUNT(*) AS attempts, COUNT(CASE WHEN error_message = '' THEN 1 END) AS delivered").
+		Where("webhook_id = ? AND event_id = ?", webhookId, eventId).
+		Scan(&result).Error
+	if err != nil {
+		return 0, false, err
+	}
+	return int(result.Attempts), result.Delivered > 0, nil
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func (s *Server) routeAudit(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listAudit(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet)
+	}
+}
+
+// listAudit accepts since as a time, or a duration before now, such as 7d.
+func (s *Server) listAudit(w http.ResponseWriter, r *http.Request, username string) {
+	params := app.ListAuditParams{}
+	if value := r.URL.Query().Get("since"); value != "" {
+		duration, err := pkg.ParseDuration(value)
+		if err == nil {
+			params.Since = time.Now().Add(-duration)
+		} else {
+			params.Since, err = pkg.ParseTime(value, time.UTC)
+		}
+		if err != nil {
+			writeAppError(w, fmt.Errorf("Error: The since %v %w", value, app.ErrInvalidParams))
+			return
+		}
+	}
+
+	entries, err := s.svc.ListAudit(r.Context(), username, params)
+	if err != nil {
+		if errors.Is(err, app.ErrListAuditEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewAuditEntry{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// bearerToken returns the token of the header "Authorization: Bearer [token]".
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return header[len(prefix):], true
+}
+
+// isPublic reports the routes which don't need a session: register and login.
+func isPublic(r *http.Request) bool {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "sessions":
+		return r.Method == http.MethodPost
+	case path == "users":
+		return r.Method == http.MethodPost
+	default:
+		return false
+	}
+}
+
+// authenticate puts the app.Principal of the bearer token into the context of the request,
+// a request without a token gets 401 unless the route is public.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	token, ok := bearerToken(r)
+	if !ok {
+		if !isPublic(r) {
+			writeAppError(w, fmt.Errorf("Error: The user %w", app.ErrUnauthenticated))
+			return r, false
+		}
+		return r, true
+	}
+
+	principal, err := s.svc.Authenticate(r.Context(), token, time.Now())
+	if err != nil {
+		writeAppError(w, err)
+		return r, false
+	}
+	return r.WithContext(app.ContextWithPrincipal(r.Context(), principal)), true
+}
+
+func (s *Server) routeSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.login(w, r)
+	case http.MethodDelete:
+		s.logout(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodPost, http.MethodDelete)
+	}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.LoginParams{
+		Username:  req.Username,
+		Password:  req.Password,
+		LoginTime: time.Now(),
+	}
+	session, err := s.svc.Login(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	token, ok := bearerToken(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Error: The Authorization header is required.")
+		return
+	}
+
+	err := s.svc.Logout(r.Context(), token)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) routePassword(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodPut:
+		s.setPassword(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodPut)
+	}
+}
+
+type setPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+func (s *Server) setPassword(w http.ResponseWriter, r *http.Request, username string) {
+	var req setPasswordRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.SetPasswordParams{
+		Username: username,
+		Password: req.Password,
+	}
+	err := s.svc.SetPassword(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
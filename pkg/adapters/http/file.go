@@ -0,0 +1,179 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+type createFileRequest struct {
+	Foldername  string `json:"foldername"`
+	Filename    string `json:"filename"`
+	Description string `json:"description"`
+}
+
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request, username string) {
+	var req createFileRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.CreateFileParams{
+		Foldername:  req.Foldername,
+		Filename:    req.Filename,
+		Description: req.Description,
+		CreatedTime: time.Now(),
+	}
+
+	err := s.svc.CreateFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, r *http.Request, username string) {
+	query := r.URL.Query()
+	params := app.DeleteFileParams{
+		Foldername:  query.Get("folder"),
+		Filename:    query.Get("file"),
+		DeletedTime: time.Now(),
+	}
+
+	err := s.svc.DeleteFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request, username string) {
+	query := r.URL.Query()
+	sort, err := parseSort(query)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	filter, page, err := parseList(query)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	params := app.ListFilesParams{
+		Foldername: query.Get("folder"),
+		Sort:       sort,
+		Filter:     filter,
+		Page:       page,
+	}
+
+	files, err := s.svc.ListFiles(r.Context(), username, params)
+	if err != nil {
+		if errors.Is(err, app.ErrListFileEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewFile{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (s *Server) writeFile(w http.ResponseWriter, r *http.Request, username string) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.MaxFileSize))
+	if err != nil {
+		if writeTooLarge(w, err) {
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Error: "+err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	params := app.WriteFileParams{
+		Foldername:  query.Get("folder"),
+		Filename:    query.Get("file"),
+		Content:     content,
+		UpdatedTime: time.Now(),
+	}
+
+	err = s.svc.WriteFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) readFile(w http.ResponseWriter, r *http.Request, username string) {
+	query := r.URL.Query()
+	params := app.ReadFileParams{
+		Foldername: query.Get("folder"),
+		Filename:   query.Get("file"),
+	}
+
+	content, err := s.svc.ReadFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+type transferFileRequest struct {
+	Foldername    string `json:"foldername"`
+	Filename      string `json:"filename"`
+	DstFoldername string `json:"dst_foldername"`
+	NewFilename   string `json:"new_filename"`
+}
+
+func (s *Server) moveFile(w http.ResponseWriter, r *http.Request, username string) {
+	var req transferFileRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.MoveFileParams{
+		SrcFoldername: req.Foldername,
+		Filename:      req.Filename,
+		DstFoldername: req.DstFoldername,
+		NewFilename:   req.NewFilename,
+		UpdatedTime:   time.Now(),
+	}
+
+	err := s.svc.MoveFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) copyFile(w http.ResponseWriter, r *http.Request, username string) {
+	var req transferFileRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.CopyFileParams{
+		SrcFoldername: req.Foldername,
+		Filename:      req.Filename,
+		DstFoldername: req.DstFoldername,
+		NewFilename:   req.NewFilename,
+		CreatedTime:   time.Now(),
+	}
+
+	err := s.svc.CopyFile(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
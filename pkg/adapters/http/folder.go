@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+type createFolderRequest struct {
+	Foldername  string `json:"foldername"`
+	Description string `json:"description"`
+}
+
+func (s *Server) createFolder(w http.ResponseWriter, r *http.Request, username string) {
+	var req createFolderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.CreateFolderParams{
+		Foldername:  req.Foldername,
+		Description: req.Description,
+		CreatedTime: time.Now(),
+	}
+
+	err := s.svc.CreateFolder(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+func (s *Server) deleteFolder(w http.ResponseWriter, r *http.Request, username string) {
+	params := app.DeleteFolderParams{
+		Foldername:  r.URL.Query().Get("folder"),
+		DeletedTime: time.Now(),
+	}
+
+	err := s.svc.DeleteFolder(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listFolders(w http.ResponseWriter, r *http.Request, username string) {
+	query := r.URL.Query()
+	sort, err := parseSort(query)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	filter, page, err := parseList(query)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	params := app.ListFoldersParams{
+		Foldername: query.Get("folder"),
+		Sort:       sort,
+		Filter:     filter,
+		Page:       page,
+	}
+
+	folders, err := s.svc.ListFolders(r.Context(), username, params)
+	if err != nil {
+		if errors.Is(err, app.ErrListFolderEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewFolder{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, folders)
+}
+
+type renameFolderRequest struct {
+	Foldername    string `json:"foldername"`
+	NewFolderName string `json:"new_folder_name"`
+}
+
+func (s *Server) renameFolder(w http.ResponseWriter, r *http.Request, username string) {
+	var req renameFolderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.RenameFolderParams{
+		OldFolderName: req.Foldername,
+		NewFolderName: req.NewFolderName,
+		UpdatedTime:   time.Now(),
+	}
+
+	err := s.svc.RenameFolder(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
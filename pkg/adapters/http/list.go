@@ -0,0 +1,59 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// parseList converts the filter and page queries,
+// e.g. "filter=*.conf&created_after=2024-05-27&limit=10&cursor=01HYYMMTX8F4D2BESDCAD2YXS5".
+func parseList(query url.Values) (app.ListFilterParams, app.PageParams, error) {
+	filter := app.ListFilterParams{Name: query.Get("filter")}
+	var page app.PageParams
+
+	times := []struct {
+		key   string
+		value *time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	}
+	for _, t := range times {
+		value := query.Get(t.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := pkg.ParseTime(value, time.UTC)
+		if err != nil {
+			return filter, page, fmt.Errorf("Error: The %v %v %w", t.key, value, app.ErrInvalidParams)
+		}
+		*t.value = parsed
+	}
+
+	numbers := []struct {
+		key   string
+		value *int
+	}{
+		{"limit", &page.Limit},
+		{"offset", &page.Offset},
+	}
+	for _, n := range numbers {
+		value := query.Get(n.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, page, fmt.Errorf("Error: The %v %v %w", n.key, value, app.ErrInvalidParams)
+		}
+		*n.value = parsed
+	}
+
+	page.Cursor = query.Get("cursor")
+	return filter, page, page.Validate()
+}
@@ -0,0 +1,304 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// NewServer exposes app.Service as a JSON REST API.
+//
+//	POST   /sessions
+//	DELETE /sessions
+//	POST   /users
+//	GET    /users
+//	GET    /users/{username}
+//	PATCH  /users/{username}
+//	DELETE /users/{username}
+//	GET    /users/{username}/quota
+//	PUT    /users/{username}/quota
+//	PUT    /users/{username}/role
+//	PUT    /users/{username}/password
+//	GET    /users/{username}/folders?folder=/home&sort=created:desc
+//	POST   /users/{username}/folders
+//	PATCH  /users/{username}/folders
+//	DELETE /users/{username}/folders?folder=/home
+//	GET    /users/{username}/files?folder=/home&sort=name:asc
+//	POST   /users/{username}/files
+//	DELETE /users/{username}/files?folder=/home&file=dev.conf
+//	GET    /users/{username}/files/content?folder=/home&file=dev.conf
+//	PUT    /users/{username}/files/content?folder=/home&file=dev.conf
+//	POST   /users/{username}/files/move
+//	POST   /users/{username}/files/copy
+//	GET    /users/{username}/trash
+//	POST   /users/{username}/trash/restore
+//	DELETE /users/{username}/trash?older_than=30d
+//	GET    /users/{username}/stat?path=/home/dev.conf
+//	POST   /users/{username}/shares
+//	DELETE /users/{username}/shares?folder=/home&grantee=user2
+//	GET    /users/{username}/shared-with-me
+//	GET    /users/{username}/audit?since=7d
+//	GET    /users/{username}/webhooks
+//	POST   /users/{username}/webhooks
+//	DELETE /users/{username}/webhooks?id=[id]
+//	GET    /users/{username}/webhooks/deliveries
+//
+// POST /sessions returns the token of a session, the other requests send it by the header
+// "Authorization: Bearer [token]" to act as the user who has logged in.
+// Without the header, only POST /users, GET /users and POST /sessions are served, the others get 401.
+//
+// The lists of folders and files accept
+// filter, created_after, created_before, limit, offset and cursor, see parseList.
+//
+// A body larger than MaxFileSize, or a JSON body larger than maxJSONSize, gets 413,
+// so a request can't exhaust the memory before the quota is checked.
+func NewServer(svc *app.Service) *Server {
+	return &Server{
+		svc:         svc,
+		MaxFileSize: DefaultMaxFileSize,
+	}
+}
+
+// DefaultMaxFileSize is the largest content which PUT /users/{username}/files/content accepts.
+const DefaultMaxFileSize int64 = 32 << 20
+
+// maxJSONSize is the largest JSON body which the other routes accept.
+const maxJSONSize int64 = 1 << 20
+
+type Server struct {
+	svc *app.Service
+
+	MaxFileSize int64
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) == 1 && segments[0] == "sessions" {
+		s.routeSessions(w, r)
+		return
+	}
+	if segments[0] != "users" {
+		writeError(w, http.StatusNotFound, "Error: Unrecognized route")
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		s.routeUsers(w, r)
+	case len(segments) == 2:
+		s.routeUser(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "quota":
+		s.routeQuota(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "role":
+		s.routeRole(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "password":
+		s.routePassword(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "folders":
+		s.routeFolders(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "files":
+		s.routeFiles(w, r, segments[1])
+	case len(segments) == 4 && segments[2] == "files" && segments[3] == "content":
+		s.routeFileContent(w, r, segments[1])
+	case len(segments) == 4 && segments[2] == "files" && segments[3] == "move":
+		s.routeFileAction(w, r, segments[1], s.moveFile)
+	case len(segments) == 4 && segments[2] == "files" && segments[3] == "copy":
+		s.routeFileAction(w, r, segments[1], s.copyFile)
+	case len(segments) == 3 && segments[2] == "trash":
+		s.routeTrash(w, r, segments[1])
+	case len(segments) == 4 && segments[2] == "trash" && segments[3] == "restore":
+		s.routeFileAction(w, r, segments[1], s.restoreTrash)
+	case len(segments) == 3 && segments[2] == "stat":
+		s.routeStat(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "shares":
+		s.routeShares(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "shared-with-me":
+		s.routeSharedWithMe(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "audit":
+		s.routeAudit(w, r, segments[1])
+	case len(segments) == 3 && segments[2] == "webhooks":
+		s.routeWebhooks(w, r, segments[1])
+	case len(segments) == 4 && segments[2] == "webhooks" && segments[3] == "deliveries":
+		s.routeDeliveries(w, r, segments[1])
+	default:
+		writeError(w, http.StatusNotFound, "Error: Unrecognized route")
+	}
+}
+
+func (s *Server) routeUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listUsers(w, r)
+	case http.MethodPost:
+		s.registerUser(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) routeUser(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getUserInfo(w, r, username)
+	case http.MethodPatch:
+		s.renameUser(w, r, username)
+	case http.MethodDelete:
+		s.deleteUser(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func (s *Server) routeQuota(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getQuota(w, r, username)
+	case http.MethodPut:
+		s.setQuota(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut)
+	}
+}
+
+func (s *Server) routeRole(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodPut:
+		s.setRole(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodPut)
+	}
+}
+
+func (s *Server) routeFolders(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listFolders(w, r, username)
+	case http.MethodPost:
+		s.createFolder(w, r, username)
+	case http.MethodPatch:
+		s.renameFolder(w, r, username)
+	case http.MethodDelete:
+		s.deleteFolder(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func (s *Server) routeFiles(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listFiles(w, r, username)
+	case http.MethodPost:
+		s.createFile(w, r, username)
+	case http.MethodDelete:
+		s.deleteFile(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodDelete)
+	}
+}
+
+func (s *Server) routeFileContent(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.readFile(w, r, username)
+	case http.MethodPut:
+		s.writeFile(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPut)
+	}
+}
+
+func (s *Server) routeTrash(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listTrash(w, r, username)
+	case http.MethodDelete:
+		s.emptyTrash(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	}
+}
+
+func (s *Server) routeFileAction(w http.ResponseWriter, r *http.Request, username string, action func(w http.ResponseWriter, r *http.Request, username string)) {
+	switch r.Method {
+	case http.MethodPost:
+		action(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodPost)
+	}
+}
+
+// response
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "Error: Method not allowed")
+}
+
+func writeAppError(w http.ResponseWriter, err error) {
+	writeError(w, statusCode(err), err.Error())
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, app.ErrInvalidParams):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrNotExists):
+		return http.StatusNotFound
+	case errors.Is(err, app.ErrExists), errors.Is(err, app.ErrLastAdmin):
+		return http.StatusConflict
+	case errors.Is(err, app.ErrUnauthenticated), errors.Is(err, app.ErrPasswordNotSet),
+		errors.Is(err, app.ErrIncorrectPassword), errors.Is(err, app.ErrSessionNotExists):
+		return http.StatusUnauthorized
+	case errors.Is(err, app.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, app.ErrQuotaExceeded):
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, body any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONSize)).Decode(body)
+	if err != nil {
+		if writeTooLarge(w, err) {
+			return false
+		}
+		writeError(w, http.StatusBadRequest, "Error: Invalid JSON body")
+		return false
+	}
+	return true
+}
+
+// writeTooLarge responds 413 when err is returned by the reader of http.MaxBytesReader.
+func writeTooLarge(w http.ResponseWriter, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Error: The body is larger than %v bytes.", tooLarge.Limit))
+	return true
+}
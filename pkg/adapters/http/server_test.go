@@ -0,0 +1,733 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
+)
+
+// signup registers username with the password username-password,
+// and returns the token of its session.
+func signup(t *testing.T, handler http.Handler, username string) string {
+	t.Helper()
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/users", `{"username":"`+username+`","password":"`+username+`-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.Equal(t, `{"username":"`+username+`"}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodPost, "/sessions", `{"username":"`+username+`","password":"`+username+`-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var session app.ViewSession
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &session))
+	return session.Token
+}
+
+func TestServer(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	// the first user is the admin, who is kept while user1 is deleted
+	adminToken := signup(t, handler, "admin")
+	token := signup(t, handler, "user1")
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "The [username] has already existed.",
+			method:     http.MethodPost,
+			target:     "/users",
+			body:       `{"username":"user1","password":"user1-password"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"Error: The user1 has already existed."}`,
+		},
+		{
+			name:       "The [username] contain invalid chars.",
+			method:     http.MethodPost,
+			target:     "/users",
+			body:       `{"username":"user@1"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Error: The user@1 contain invalid chars."}`,
+		},
+		{
+			name:       "list empty folders",
+			method:     http.MethodGet,
+			target:     "/users/user1/folders",
+			wantStatus: http.StatusOK,
+			wantBody:   `[]`,
+		},
+		{
+			name:       "create folder",
+			method:     http.MethodPost,
+			target:     "/users/user1/folders",
+			body:       `{"foldername":"/home","description":"home folder"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"foldername":"/home","description":"home folder"}`,
+		},
+		{
+			name:       "create nested folder",
+			method:     http.MethodPost,
+			target:     "/users/user1/folders",
+			body:       `{"foldername":"/home/dev"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"foldername":"/home/dev","description":""}`,
+		},
+		{
+			name:       "The [username] doesn't exist.",
+			method:     http.MethodPost,
+			target:     "/users/user2/folders",
+			body:       `{"foldername":"/home"}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Error: The user2 doesn't exist."}`,
+		},
+		{
+			name:       "rename folder",
+			method:     http.MethodPatch,
+			target:     "/users/user1/folders",
+			body:       `{"foldername":"/home/dev","new_folder_name":"qa"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid sort",
+			method:     http.MethodGet,
+			target:     "/users/user1/folders?sort=size:desc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Error: The sort size contain invalid chars."}`,
+		},
+		{
+			name:       "invalid name order",
+			method:     http.MethodGet,
+			target:     "/users/user1/folders?sort=created:desc,name:asc&name_order=random",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Error: The name order random contain invalid chars."}`,
+		},
+		{
+			name:       "invalid limit",
+			method:     http.MethodGet,
+			target:     "/users/user1/folders?limit=ten",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Error: The limit ten contain invalid chars."}`,
+		},
+		{
+			name:       "create file",
+			method:     http.MethodPost,
+			target:     "/users/user1/files",
+			body:       `{"foldername":"/home/qa","filename":"qa.conf"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"foldername":"/home/qa","filename":"qa.conf","description":""}`,
+		},
+		{
+			name:       "write file",
+			method:     http.MethodPut,
+			target:     "/users/user1/files/content?folder=/home/qa&file=qa.conf",
+			body:       "port=8080",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "read file",
+			method:     http.MethodGet,
+			target:     "/users/user1/files/content?folder=/home/qa&file=qa.conf",
+			wantStatus: http.StatusOK,
+			wantBody:   "port=8080",
+		},
+		{
+			name:       "copy file",
+			method:     http.MethodPost,
+			target:     "/users/user1/files/copy",
+			body:       `{"foldername":"/home/qa","filename":"qa.conf","dst_foldername":"/home","new_filename":"qa.conf.bak"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "move file",
+			method:     http.MethodPost,
+			target:     "/users/user1/files/move",
+			body:       `{"foldername":"/home","filename":"qa.conf.bak","dst_foldername":"/home/qa"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "The [new_filename] has already existed.",
+			method:     http.MethodPost,
+			target:     "/users/user1/files/copy",
+			body:       `{"foldername":"/home/qa","filename":"qa.conf","dst_foldername":"/home/qa"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"Error: The qa.conf has already existed."}`,
+		},
+		{
+			name:       "read moved file",
+			method:     http.MethodGet,
+			target:     "/users/user1/files/content?folder=/home/qa&file=qa.conf.bak",
+			wantStatus: http.StatusOK,
+			wantBody:   "port=8080",
+		},
+		{
+			name:       "delete file",
+			method:     http.MethodDelete,
+			target:     "/users/user1/files?folder=/home/qa&file=qa.conf",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "The [filename] doesn't exist.",
+			method:     http.MethodDelete,
+			target:     "/users/user1/files?folder=/home/qa&file=qa.conf",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Error: The qa.conf doesn't exist."}`,
+		},
+		{
+			name:       "delete folder",
+			method:     http.MethodDelete,
+			target:     "/users/user1/folders?folder=/home",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "keep recent trash items",
+			method:     http.MethodDelete,
+			target:     "/users/user1/trash?older_than=30d",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"removed":0}`,
+		},
+		{
+			name:       "restore folder",
+			method:     http.MethodPost,
+			target:     "/users/user1/trash/restore",
+			body:       `{"target":"/home"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "read restored file",
+			method:     http.MethodGet,
+			target:     "/users/user1/files/content?folder=/home/qa&file=qa.conf.bak",
+			wantStatus: http.StatusOK,
+			wantBody:   "port=8080",
+		},
+		{
+			name:       "empty trash",
+			method:     http.MethodDelete,
+			target:     "/users/user1/trash",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"removed":1}`,
+		},
+		{
+			name:       "list empty trash",
+			method:     http.MethodGet,
+			target:     "/users/user1/trash",
+			wantStatus: http.StatusOK,
+			wantBody:   `[]`,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPut,
+			target:     "/users/user1/folders",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"Error: Method not allowed"}`,
+		},
+		{
+			name:       "unknown route",
+			method:     http.MethodGet,
+			target:     "/groups",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Error: Unrecognized route"}`,
+		},
+		{
+			name:       "rename user",
+			method:     http.MethodPatch,
+			target:     "/users/user1",
+			body:       `{"new_username":"user2"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete user",
+			method:     http.MethodDelete,
+			target:     "/users/user2",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "The session of the deleted user is gone.",
+			method:     http.MethodGet,
+			target:     "/users",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":"Error: The session doesn't exist or has expired, please login again."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.target, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			require.Equal(t, tt.wantBody, strings.TrimSpace(string(body)))
+		})
+	}
+
+	resp, err := server.Client().Get(server.URL + "/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/users", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err = server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var users []app.ViewUser
+	require.NoError(t, json.Unmarshal(body, &users))
+	require.Len(t, users, 1)
+	require.Equal(t, "admin", users[0].Username)
+}
+
+func TestServer_listFolders(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	serve(http.MethodPost, "/users/user1/folders", `{"foldername":"etc"}`)
+	serve(http.MethodPost, "/users/user1/folders", `{"foldername":"home"}`)
+
+	recorder := serve(http.MethodGet, "/users/user1/folders?sort=name:desc", "")
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `"foldername":"etc","description":"","created_time":`)
+	require.Less(t, strings.Index(body, `"home"`), strings.Index(body, `"etc"`), "sort by name desc")
+
+	recorder = serve(http.MethodGet, "/users/user1/folders?filter=E&limit=1", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var page []app.ViewFolder
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page, 1)
+	require.Equal(t, "etc", page[0].Fodlername)
+
+	recorder = serve(http.MethodGet, "/users/user1/folders?filter=E&limit=1&cursor="+page[0].Id, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page, 1)
+	require.Equal(t, "home", page[0].Fodlername)
+}
+
+func TestServer_stat(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	serve(http.MethodPost, "/users/user1/folders", `{"foldername":"/home"}`)
+	serve(http.MethodPost, "/users/user1/files", `{"foldername":"/home","filename":"data.json"}`)
+	serve(http.MethodPut, "/users/user1/files/content?folder=/home&file=data.json", `{"a":1}`)
+
+	recorder := serve(http.MethodGet, "/users/user1/stat?path=/home/data.json", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var stat app.ViewStat
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stat))
+	require.Equal(t, app.StatKind_File, stat.Kind)
+	require.Equal(t, "/home/data.json", stat.Path)
+	require.Equal(t, int64(7), stat.Size)
+	require.Equal(t, "application/json", stat.ContentType)
+
+	recorder = serve(http.MethodGet, "/users/user1/stat?path=/", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stat))
+	require.Equal(t, app.StatKind_Folder, stat.Kind)
+	require.Equal(t, int64(7), stat.Size)
+	require.Equal(t, 1, stat.Folders)
+	require.Equal(t, 1, stat.Files)
+
+	recorder = serve(http.MethodGet, "/users/user1/stat?path=/home/none", "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	require.Equal(t, `{"error":"Error: The /home/none doesn't exist."}`, strings.TrimSpace(recorder.Body.String()))
+}
+
+func TestServer_quota(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve(http.MethodPut, "/users/user1/quota", `{"max_files":1,"max_bytes":4}`)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(http.MethodPost, "/users/user1/files", `{"foldername":"/","filename":"a.txt"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	recorder = serve(http.MethodPost, "/users/user1/files", `{"foldername":"/","filename":"b.txt"}`)
+	require.Equal(t, http.StatusInsufficientStorage, recorder.Code)
+	require.Equal(t, `{"error":"Error: The user1 has exceeded the quota of 1 files."}`, strings.TrimSpace(recorder.Body.String()))
+
+	recorder = serve(http.MethodPut, "/users/user1/files/content?folder=/&file=a.txt", "hello")
+	require.Equal(t, http.StatusInsufficientStorage, recorder.Code)
+
+	recorder = serve(http.MethodGet, "/users/user1/quota", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t,
+		`{"username":"user1","max_folders":0,"max_files":1,"max_bytes":4,"folders":0,"files":1,"size":0}`,
+		strings.TrimSpace(recorder.Body.String()),
+	)
+
+	recorder = serve(http.MethodPut, "/users/user1/quota", `{"max_folders":-1}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestServer_bodyLimit(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	handler.MaxFileSize = 5
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	serve(http.MethodPost, "/users/user1/files", `{"foldername":"/","filename":"a.txt"}`)
+
+	recorder := serve(http.MethodPut, "/users/user1/files/content?folder=/&file=a.txt", "hello")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(http.MethodPut, "/users/user1/files/content?folder=/&file=a.txt", "hello!")
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	require.Equal(t, `{"error":"Error: The body is larger than 5 bytes."}`, strings.TrimSpace(recorder.Body.String()))
+
+	recorder = serve(http.MethodGet, "/users/user1/files/content?folder=/&file=a.txt", "")
+	require.Equal(t, "hello", recorder.Body.String())
+
+	recorder = serve(http.MethodPost, "/users/user1/folders", `{"foldername":"/docs","description":"`+strings.Repeat("a", 1<<20)+`"}`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestServer_share(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	serve := func(method, target, token, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	user1 := signup(t, handler, "user1")
+	user2 := signup(t, handler, "user2")
+	serve(http.MethodPost, "/users/user1/folders", user1, `{"foldername":"/docs"}`)
+
+	recorder := serve(http.MethodPost, "/users/user1/files", user2, `{"foldername":"/docs","filename":"a.txt"}`)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Equal(t, `{"error":"Error: The user2 doesn't have the permission to write /docs."}`, strings.TrimSpace(recorder.Body.String()))
+
+	recorder = serve(http.MethodPost, "/users/user1/shares", user1, `{"foldername":"/docs","grantee":"user2","permission":"write"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = serve(http.MethodPost, "/users/user1/files", user2, `{"foldername":"/docs","filename":"a.txt"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = serve(http.MethodGet, "/users/user2/shared-with-me", user2, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var shared []app.ViewSharedFolder
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &shared))
+	require.Len(t, shared, 1)
+	require.Equal(t, "/docs", shared[0].Path)
+	require.Equal(t, "write", shared[0].Permission)
+
+	recorder = serve(http.MethodDelete, "/users/user1/shares?folder=/docs&grantee=user2", user1, "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(http.MethodGet, "/users/user2/shared-with-me", user2, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "[]", strings.TrimSpace(recorder.Body.String()))
+}
+
+func TestServer_auth(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	serve := func(method, target, token, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/users", "", `{"username":"user1"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, `{"error":"Error: The password must have 8 to 72 bytes, it contain invalid chars."}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodPost, "/users", "", `{"username":"user1","password":"user1-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.Equal(t, `{"username":"user1"}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodPost, "/users", "", `{"username":"user2","password":"user2-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	// every route but register and login needs the token
+	for _, route := range [][2]string{
+		{http.MethodGet, "/users"},
+		{http.MethodPut, "/users/user1/password"},
+		{http.MethodGet, "/users/user1"},
+		{http.MethodPatch, "/users/user1"},
+		{http.MethodDelete, "/users/user1"},
+		{http.MethodPut, "/users/user1/quota"},
+		{http.MethodPost, "/users/user1/folders"},
+		{http.MethodDelete, "/sessions"},
+	} {
+		recorder = serve(route[0], route[1], "", `{"password":"other-password"}`)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code, route)
+		require.Equal(t, `{"error":"Error: The user needs to login."}`, strings.TrimSpace(recorder.Body.String()), route)
+	}
+	recorder = serve(http.MethodPost, "/sessions", "", `{"username":"user1","password":"wrong-password"}`)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	recorder = serve(http.MethodPost, "/sessions", "", `{"username":"user1","password":"user1-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var session app.ViewSession
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &session))
+	require.Equal(t, "user1", session.Username)
+	require.Len(t, session.Token, 64)
+
+	recorder = serve(http.MethodPost, "/users/user1/folders", session.Token, `{"foldername":"/docs"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	// user1 is the first user, so the admin
+	recorder = serve(http.MethodPut, "/users/user2/quota", session.Token, `{"max_files":1}`)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(http.MethodPost, "/sessions", "", `{"username":"user2","password":"user2-password"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var user2 app.ViewSession
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &user2))
+	recorder = serve(http.MethodDelete, "/users/user1", user2.Token, "")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Equal(t, `{"error":"Error: The user2 doesn't have the permission to manage user1."}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodPut, "/users/user2/quota", user2.Token, `{"max_files":100}`)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Equal(t, `{"error":"Error: The user2 doesn't have the permission to set the quota of user2."}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodDelete, "/users/user1", session.Token, "")
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Equal(t, `{"error":"Error: The user1 is the last admin."}`, strings.TrimSpace(recorder.Body.String()))
+	recorder = serve(http.MethodPut, "/users/user2/role", session.Token, `{"role":"admin"}`)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	recorder = serve(http.MethodPut, "/users/user2/quota", user2.Token, `{"max_files":100}`)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = serve(http.MethodDelete, "/sessions", session.Token, "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	recorder = serve(http.MethodGet, "/users/user1/folders", session.Token, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Equal(t, `{"error":"Error: The session doesn't exist or has expired, please login again."}`, strings.TrimSpace(recorder.Body.String()))
+}
+
+func TestServer_audit(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	handler := inject.NewHttpServer(infra)
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	serve(http.MethodPost, "/users/user1/folders", `{"foldername":"/docs"}`)
+	serve(http.MethodPatch, "/users/user1/folders", `{"foldername":"/docs","new_folder_name":"notes"}`)
+
+	recorder := serve(http.MethodGet, "/users/user1/audit?since=1d", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var entries []app.ViewAuditEntry
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+	require.Len(t, entries, 3)
+	require.Equal(t, "register", entries[0].Action)
+	require.Equal(t, "create-folder", entries[1].Action)
+	require.Equal(t, "rename-folder", entries[2].Action)
+	require.Equal(t, "/docs", entries[2].Before)
+	require.Equal(t, "/notes", entries[2].After)
+
+	recorder = serve(http.MethodGet, "/users/user1/audit?since=2999-01-01", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "[]", strings.TrimSpace(recorder.Body.String()))
+
+	recorder = serve(http.MethodGet, "/users/user1/audit?since=yesterday", "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestServer_webhook(t *testing.T) {
+	infra, err := inject.NewInfra(&database.GormConfing{
+		Dsn:     ":memory:",
+		Migrate: true,
+	})
+	require.NoError(t, err)
+	defer infra.Cleanup()
+
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	// the events wait in the outbox until another process relays them, as `vFS relay` does
+	var deliveries []received
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries = append(deliveries, received{header: r.Header, body: body})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	handler := inject.NewHttpServer(infra)
+	token := signup(t, handler, "user1")
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/users/user1/webhooks", `{"url":"`+receiver.URL+`","events":["folder.created"]}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var hook app.ViewWebhook
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &hook))
+	require.NotEmpty(t, hook.Secret)
+	require.Equal(t, []string{"folder.created"}, hook.Events)
+
+	recorder = serve(http.MethodPost, "/users/user1/webhooks", `{"url":"`+receiver.URL+`","events":["folder.moved"]}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	serve(http.MethodPost, "/users/user1/folders", `{"foldername":"/docs"}`)
+	serve(http.MethodPatch, "/users/user1/folders", `{"foldername":"/docs","new_folder_name":"notes"}`)
+	require.Len(t, deliveries, 0)
+	require.NoError(t, inject.NewAppService(infra).Events.Relay(context.Background()))
+
+	// folder.renamed isn't subscribed
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	require.Equal(t, "folder.created", delivery.header.Get(app.WebhookEventHeader))
+	require.True(t, app.VerifyWebhook(hook.Secret, delivery.body, delivery.header.Get(app.WebhookSignatureHeader)))
+	require.False(t, app.VerifyWebhook(hook.Secret, append(delivery.body, ' '), delivery.header.Get(app.WebhookSignatureHeader)))
+	var payload app.WebhookPayload
+	require.NoError(t, json.Unmarshal(delivery.body, &payload))
+	require.Equal(t, app.EventName_FolderCreated, payload.Event)
+	require.Equal(t, "user1", payload.Username)
+	require.Equal(t, "/docs", payload.Path)
+
+	recorder = serve(http.MethodGet, "/users/user1/webhooks/deliveries", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var logs []app.ViewWebhookDelivery
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &logs))
+	require.Len(t, logs, 1)
+	require.Equal(t, hook.Id, logs[0].WebhookId)
+	require.Equal(t, payload.Id, logs[0].EventId)
+	require.Equal(t, delivery.header.Get(app.WebhookDeliveryHeader), logs[0].Id)
+	require.Equal(t, http.StatusNoContent, logs[0].StatusCode)
+	require.Equal(t, 1, logs[0].Attempts)
+	require.Empty(t, logs[0].Error)
+
+	recorder = serve(http.MethodGet, "/users/user1/webhooks", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), hook.Secret)
+
+	recorder = serve(http.MethodDelete, "/users/user1/webhooks?id="+hook.Id, "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	recorder = serve(http.MethodGet, "/users/user1/webhooks", "")
+	require.Equal(t, "[]", strings.TrimSpace(recorder.Body.String()))
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func (s *Server) routeShares(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodPost:
+		s.shareFolder(w, r, username)
+	case http.MethodDelete:
+		s.unshareFolder(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodPost, http.MethodDelete)
+	}
+}
+
+type shareFolderRequest struct {
+	Foldername string `json:"foldername"`
+	Grantee    string `json:"grantee"`
+	Permission string `json:"permission"`
+}
+
+func (s *Server) shareFolder(w http.ResponseWriter, r *http.Request, username string) {
+	var req shareFolderRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.ShareFolderParams{
+		Owner:       username,
+		Foldername:  req.Foldername,
+		Grantee:     req.Grantee,
+		Permission:  req.Permission,
+		CreatedTime: time.Now(),
+	}
+	err := s.svc.ShareFolder(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, req)
+}
+
+func (s *Server) unshareFolder(w http.ResponseWriter, r *http.Request, username string) {
+	query := r.URL.Query()
+	params := app.UnshareFolderParams{
+		Owner:      username,
+		Foldername: query.Get("folder"),
+		Grantee:    query.Get("grantee"),
+	}
+
+	err := s.svc.UnshareFolder(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) routeSharedWithMe(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listSharedWithMe(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet)
+	}
+}
+
+func (s *Server) listSharedWithMe(w http.ResponseWriter, r *http.Request, username string) {
+	folders, err := s.svc.ListSharedWithMe(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, app.ErrListSharedEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewSharedFolder{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, folders)
+}
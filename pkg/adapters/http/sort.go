@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/url"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// parseSort converts the sort queries, e.g. "sort=created:desc,name:asc&name_order=natural,ignore-case",
+// into app.FileSystemSortParams. Empty queries mean the default order of app.
+func parseSort(query url.Values) (*app.FileSystemSortParams, error) {
+	params, err := app.ParseFileSystemSortParams(query.Get("sort"), query.Get("name_order"))
+	if err != nil {
+		return nil, err
+	}
+	if params.IsZero() {
+		return nil, nil
+	}
+	return params, nil
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func (s *Server) routeStat(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.stat(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet)
+	}
+}
+
+func (s *Server) stat(w http.ResponseWriter, r *http.Request, username string) {
+	params := app.StatParams{
+		Path: r.URL.Query().Get("path"),
+	}
+
+	stat, err := s.svc.Stat(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stat)
+}
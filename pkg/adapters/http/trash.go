@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func (s *Server) listTrash(w http.ResponseWriter, r *http.Request, username string) {
+	items, err := s.svc.ListTrash(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, app.ErrListTrashEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewTrashItem{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+type restoreTrashRequest struct {
+	// Target is the id or the original path of a trash item.
+	Target string `json:"target"`
+}
+
+func (s *Server) restoreTrash(w http.ResponseWriter, r *http.Request, username string) {
+	var req restoreTrashRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.RestoreTrashParams{
+		Target:       req.Target,
+		RestoredTime: time.Now(),
+	}
+
+	_, err := s.svc.RestoreTrash(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type emptyTrashResponse struct {
+	Removed int `json:"removed"`
+}
+
+func (s *Server) emptyTrash(w http.ResponseWriter, r *http.Request, username string) {
+	params := app.EmptyTrashParams{
+		Now: time.Now(),
+	}
+	if value := r.URL.Query().Get("older_than"); value != "" {
+		duration, err := pkg.ParseDuration(value)
+		if err != nil {
+			writeAppError(w, fmt.Errorf("Error: The older_than %v %w", value, app.ErrInvalidParams))
+			return
+		}
+		params.OlderThan = duration
+	}
+
+	count, err := s.svc.EmptyTrash(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, emptyTrashResponse{Removed: count})
+}
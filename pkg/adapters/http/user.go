@@ -0,0 +1,149 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+type registerUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type registerUserResponse struct {
+	Username string `json:"username"`
+}
+
+func (s *Server) registerUser(w http.ResponseWriter, r *http.Request) {
+	var req registerUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.RegisterParams{
+		Username:    req.Username,
+		Password:    req.Password,
+		CreatedTime: time.Now(),
+	}
+	err := s.svc.Register(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, registerUserResponse{Username: req.Username})
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.svc.ListUsers(r.Context())
+	if err != nil {
+		if errors.Is(err, app.ErrListUserEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewUser{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) getUserInfo(w http.ResponseWriter, r *http.Request, username string) {
+	info, err := s.svc.GetUserInfo(r.Context(), username)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+type renameUserRequest struct {
+	NewUsername string `json:"new_username"`
+}
+
+func (s *Server) renameUser(w http.ResponseWriter, r *http.Request, username string) {
+	var req renameUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.RenameUserParams{
+		Username:    username,
+		NewUsername: req.NewUsername,
+	}
+
+	err := s.svc.RenameUser(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request, username string) {
+	err := s.svc.DeleteUser(r.Context(), username)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getQuota(w http.ResponseWriter, r *http.Request, username string) {
+	quota, err := s.svc.GetQuota(r.Context(), username)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, quota)
+}
+
+// setQuotaRequest keeps the omitted limits.
+type setQuotaRequest struct {
+	MaxFolders *int   `json:"max_folders"`
+	MaxFiles   *int   `json:"max_files"`
+	MaxBytes   *int64 `json:"max_bytes"`
+}
+
+func (s *Server) setQuota(w http.ResponseWriter, r *http.Request, username string) {
+	var req setQuotaRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.SetQuotaParams{
+		Username:   username,
+		MaxFolders: req.MaxFolders,
+		MaxFiles:   req.MaxFiles,
+		MaxBytes:   req.MaxBytes,
+	}
+	err := s.svc.SetQuota(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type setRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func (s *Server) setRole(w http.ResponseWriter, r *http.Request, username string) {
+	var req setRoleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := app.SetRoleParams{
+		Username: username,
+		Role:     req.Role,
+	}
+	err := s.svc.SetRole(r.Context(), params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func (s *Server) routeWebhooks(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listWebhooks(w, r, username)
+	case http.MethodPost:
+		s.addWebhook(w, r, username)
+	case http.MethodDelete:
+		s.removeWebhook(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodDelete)
+	}
+}
+
+type addWebhookRequest struct {
+	Url    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// addWebhook responds the secret of the webhook, which isn't returned again.
+func (s *Server) addWebhook(w http.ResponseWriter, r *http.Request, username string) {
+	var req addWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	events, err := app.ParseEventNames(req.Events)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	params := app.AddWebhookParams{
+		Url:         req.Url,
+		Events:      events,
+		CreatedTime: time.Now(),
+	}
+
+	hook, err := s.svc.AddWebhook(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) listWebhooks(w http.ResponseWriter, r *http.Request, username string) {
+	hooks, err := s.svc.ListWebhooks(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, app.ErrListWebhookEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewWebhook{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hooks)
+}
+
+func (s *Server) removeWebhook(w http.ResponseWriter, r *http.Request, username string) {
+	params := app.RemoveWebhookParams{
+		Id: r.URL.Query().Get("id"),
+	}
+
+	err := s.svc.RemoveWebhook(r.Context(), username, params)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) routeDeliveries(w http.ResponseWriter, r *http.Request, username string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listDeliveries(w, r, username)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet)
+	}
+}
+
+func (s *Server) listDeliveries(w http.ResponseWriter, r *http.Request, username string) {
+	deliveries, err := s.svc.ListDeliveries(r.Context(), username)
+	if err != nil {
+		if errors.Is(err, app.ErrListDeliveryEmpty) {
+			writeJSON(w, http.StatusOK, []app.ViewWebhookDelivery{})
+			return
+		}
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WebhookSender posts the payloads of app.WebhookUseCase.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func (sender *WebhookSender) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := sender.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
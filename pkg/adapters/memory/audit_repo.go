@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+type AuditRepository struct {
+	store *Store
+}
+
+func (repo *AuditRepository) AppendAudit(ctx context.Context, entry *app.AuditEntry) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.auditLog, entry.Id, *entry)
+		return nil
+	})
+}
+
+func (repo *AuditRepository) ListAudit(ctx context.Context, fsId string, since time.Time) ([]*app.AuditEntry, error) {
+	var entries []*app.AuditEntry
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.auditLog {
+			if row.FsId == fsId && !row.CreatedTime.Before(since) {
+				entry := row
+				entries = append(entries, &entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedTime.Equal(entries[j].CreatedTime) {
+			return entries[i].CreatedTime.Before(entries[j].CreatedTime)
+		}
+		return entries[i].Id < entries[j].Id
+	})
+	return entries, nil
+}
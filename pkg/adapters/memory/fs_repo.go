@@ -0,0 +1,715 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewFileSystemRepository(store *Store) *FileSystemRepository {
+	return &FileSystemRepository{store: store}
+}
+
+type FileSystemRepository struct {
+	store *Store
+}
+
+// CreateFileSystem stores fs with the folders and files of its tree,
+// as gorm creates the associations of fs.
+func (repo *FileSystemRepository) CreateFileSystem(ctx context.Context, fs *app.FileSystem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		row := *fs
+		row.Root = app.Folder{}
+		put(tx, repo.store.fileSystems, fs.Id, row)
+
+		var err error
+		fs.Root.Walk(func(folder *app.Folder) {
+			if err == nil {
+				err = repo.createFolder(tx, folder)
+			}
+			for _, file := range folder.Files {
+				if err == nil {
+					err = repo.createFile(tx, file)
+				}
+			}
+		})
+		return err
+	})
+}
+
+func (repo *FileSystemRepository) DeleteFileSystem(ctx context.Context, fs *app.FileSystem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		for id, file := range repo.store.files {
+			if file.FsId == fs.Id {
+				remove(tx, repo.store.contents, id)
+				remove(tx, repo.store.files, id)
+			}
+		}
+		for id, folder := range repo.store.folders {
+			if folder.FsId == fs.Id {
+				remove(tx, repo.store.folders, id)
+			}
+		}
+		for id, item := range repo.store.trashItems {
+			if item.FsId == fs.Id {
+				remove(tx, repo.store.trashItems, id)
+			}
+		}
+		for id, grant := range repo.store.grants {
+			if grant.FsId == fs.Id {
+				remove(tx, repo.store.grants, id)
+			}
+		}
+		for id, hook := range repo.store.webhooks {
+			if hook.FsId == fs.Id {
+				remove(tx, repo.store.webhooks, id)
+			}
+		}
+		for id, delivery := range repo.store.deliveries {
+			if delivery.FsId == fs.Id {
+				remove(tx, repo.store.deliveries, id)
+			}
+		}
+		remove(tx, repo.store.fileSystems, fs.Id)
+		return nil
+	})
+}
+
+// LoadFileSystem builds the tree from the maps, every strategy shares the same way.
+func (repo *FileSystemRepository) LoadFileSystem(ctx context.Context, username string, opts app.LoadFileSystemOptions) (*app.FileSystem, error) {
+	switch opts.Strategy {
+	case app.LoadStrategy_Default, app.LoadStrategy_Preload, app.LoadStrategy_Join, app.LoadStrategy_Recursive:
+	default:
+		return nil, fmt.Errorf("Error: The load strategy %v %w", opts.Strategy, app.ErrInvalidParams)
+	}
+
+	var fs *app.FileSystem
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.fileSystems {
+			if strings.EqualFold(row.Username, username) {
+				fs = &app.FileSystem{Id: row.Id, Username: row.Username}
+				break
+			}
+		}
+		if fs == nil {
+			return fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		}
+
+		folders := make(map[string]*app.Folder)
+		for _, row := range repo.store.folders {
+			if row.FsId == fs.Id && row.TrashId == "" {
+				folder := row
+				folders[folder.Id] = &folder
+			}
+		}
+
+		var root *app.Folder
+		for _, folder := range sortedFolders(folders) {
+			if folder.ParentFolderId == "" {
+				root = folder
+				continue
+			}
+			// the parent in the trash hides its subtree
+			parent, ok := folders[folder.ParentFolderId]
+			if ok {
+				parent.Folders = append(parent.Folders, folder)
+			}
+		}
+		if root == nil {
+			return fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		}
+
+		var files []*app.File
+		for _, row := range repo.store.files {
+			if row.FsId == fs.Id && row.TrashId == "" {
+				file := row
+				files = append(files, &file)
+			}
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Id < files[j].Id
+		})
+		for _, file := range files {
+			folder, ok := folders[file.FolderId]
+			if ok {
+				folder.Files = append(folder.Files, file)
+			}
+		}
+
+		fs.Root = *root
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func (repo *FileSystemRepository) FindFileSystem(ctx context.Context, username string) (*app.FileSystem, error) {
+	var fs *app.FileSystem
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.fileSystems {
+			if strings.EqualFold(row.Username, username) {
+				fs = &app.FileSystem{Id: row.Id, Username: row.Username}
+				break
+			}
+		}
+		if fs == nil {
+			return fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		}
+
+		for _, row := range repo.store.folders {
+			if row.FsId == fs.Id && row.ParentFolderId == "" {
+				fs.Root = row
+				return nil
+			}
+		}
+		return fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func (repo *FileSystemRepository) FindChildFolder(ctx context.Context, parent *app.Folder, name string) (*app.Folder, error) {
+	var folder *app.Folder
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.folders {
+			if row.ParentFolderId == parent.Id && row.TrashId == "" && strings.EqualFold(row.Name, name) {
+				folder = &row
+				return nil
+			}
+		}
+		return fmt.Errorf("Error: The %v %w", name, app.ErrFolderNotExists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (repo *FileSystemRepository) FindChildFile(ctx context.Context, folder *app.Folder, name string) (*app.File, error) {
+	var file *app.File
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.files {
+			if row.FolderId == folder.Id && row.TrashId == "" && strings.EqualFold(row.Name, name) {
+				file = &row
+				return nil
+			}
+		}
+		return fmt.Errorf("Error: The %v %w", name, app.ErrFileNotExists)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (repo *FileSystemRepository) FindFolder(ctx context.Context, fsId string, id string) (*app.Folder, error) {
+	var folder *app.Folder
+	err := repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.folders[id]
+		if !ok || row.FsId != fsId || row.TrashId != "" {
+			return fmt.Errorf("Error: The folder %v %w", id, app.ErrFolderNotExists)
+		}
+		folder = &row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (repo *FileSystemRepository) ListChildFolders(ctx context.Context, parent *app.Folder, opts app.ListChildrenOptions) ([]*app.Folder, error) {
+	var folders []*app.Folder
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.folders {
+			if row.ParentFolderId == parent.Id && row.TrashId == "" {
+				folder := row
+				folders = append(folders, &folder)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dir := app.Folder{Id: parent.Id, Folders: folders}
+	return dir.ListFolders(opts)
+}
+
+func (repo *FileSystemRepository) ListChildFiles(ctx context.Context, folder *app.Folder, opts app.ListChildrenOptions) ([]*app.File, error) {
+	var files []*app.File
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.files {
+			if row.FolderId == folder.Id && row.TrashId == "" {
+				file := row
+				files = append(files, &file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dir := app.Folder{Id: folder.Id, Files: files}
+	return dir.ListFiles(opts)
+}
+
+// sortedFolders orders the folders by id, so the tree is built in the same order every time.
+func sortedFolders(folders map[string]*app.Folder) []*app.Folder {
+	list := make([]*app.Folder, 0, len(folders))
+	for _, folder := range folders {
+		list = append(list, folder)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
+	return list
+}
+
+func (repo *FileSystemRepository) CreateFolder(ctx context.Context, folder *app.Folder) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		return repo.createFolder(tx, folder)
+	})
+}
+
+func (repo *FileSystemRepository) createFolder(tx *tx, folder *app.Folder) error {
+	row := *folder
+	row.Files = nil
+	row.Folders = nil
+	row.ByUpdate = nil
+
+	err := repo.checkFolderName(row)
+	if err != nil {
+		return err
+	}
+	put(tx, repo.store.folders, row.Id, row)
+	return nil
+}
+
+// checkFolderName plays the role of the unique index on folders.
+func (repo *FileSystemRepository) checkFolderName(folder app.Folder) error {
+	for id, row := range repo.store.folders {
+		if id != folder.Id &&
+			row.FsId == folder.FsId &&
+			row.ParentFolderId == folder.ParentFolderId &&
+			row.TrashId == folder.TrashId &&
+			strings.EqualFold(row.Name, folder.Name) {
+			return fmt.Errorf("Error: The %v %w", folder.Name, app.ErrFolderExists)
+		}
+	}
+	return nil
+}
+
+// TrashFolder marks the folder and its subtree with item.Id,
+// the files already in the trash keep their own TrashId.
+// The subtree is looked up in the store, because the children of folder may not be loaded.
+func (repo *FileSystemRepository) TrashFolder(ctx context.Context, folder *app.Folder, item *app.TrashItem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.trashItems, item.Id, *item)
+
+		folderIds := repo.subtree(folder.Id)
+		for id, row := range repo.store.folders {
+			if folderIds[id] {
+				row.TrashId = item.Id
+				put(tx, repo.store.folders, id, row)
+			}
+		}
+		for id, row := range repo.store.files {
+			if folderIds[row.FolderId] && row.TrashId == "" {
+				row.TrashId = item.Id
+				put(tx, repo.store.files, id, row)
+			}
+		}
+		return nil
+	})
+}
+
+// subtree returns the ids of the folder and its live descendants.
+func (repo *FileSystemRepository) subtree(folderId string) map[string]bool {
+	folderIds := map[string]bool{folderId: true}
+	parents := []string{folderId}
+	for len(parents) > 0 {
+		var children []string
+		for id, row := range repo.store.folders {
+			if row.TrashId == "" && slices.Contains(parents, row.ParentFolderId) {
+				folderIds[id] = true
+				children = append(children, id)
+			}
+		}
+		parents = children
+	}
+	return folderIds
+}
+
+func (repo *FileSystemRepository) SumFolder(ctx context.Context, folder *app.Folder) (app.FolderUsage, error) {
+	var usage app.FolderUsage
+	err := repo.store.run(ctx, func(tx *tx) error {
+		folderIds := repo.subtree(folder.Id)
+		usage.Folders = len(folderIds) - 1
+		for _, row := range repo.store.files {
+			if folderIds[row.FolderId] && row.TrashId == "" {
+				usage.Size += row.Size
+				usage.Files++
+			}
+		}
+		return nil
+	})
+	return usage, err
+}
+
+func (repo *FileSystemRepository) SumFileSystem(ctx context.Context, fs *app.FileSystem) (app.FolderUsage, error) {
+	var usage app.FolderUsage
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.folders {
+			if row.FsId == fs.Id && row.ParentFolderId != "" {
+				usage.Folders++
+			}
+		}
+		for _, row := range repo.store.files {
+			if row.FsId == fs.Id {
+				usage.Size += row.Size
+				usage.Files++
+			}
+		}
+		return nil
+	})
+	return usage, err
+}
+
+func (repo *FileSystemRepository) UpdateFolder(ctx context.Context, folder *app.Folder) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.folders[folder.Id]
+		if !ok {
+			return nil
+		}
+
+		err := updateFolder(&row, folder.ByUpdate.StdMap())
+		if err != nil {
+			return err
+		}
+		err = repo.checkFolderName(row)
+		if err != nil {
+			return err
+		}
+		put(tx, repo.store.folders, row.Id, row)
+
+		// the files carry the name of their folder, and they may not be loaded.
+		for id, file := range repo.store.files {
+			if file.FolderId == folder.Id {
+				file.Foldername = row.Name
+				put(tx, repo.store.files, id, file)
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) CreateFile(ctx context.Context, file *app.File) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		return repo.createFile(tx, file)
+	})
+}
+
+func (repo *FileSystemRepository) createFile(tx *tx, file *app.File) error {
+	row := *file
+	row.ByUpdate = nil
+
+	err := repo.checkFileName(row)
+	if err != nil {
+		return err
+	}
+	put(tx, repo.store.files, row.Id, row)
+	return nil
+}
+
+// checkFileName plays the role of the unique index on files.
+func (repo *FileSystemRepository) checkFileName(file app.File) error {
+	for id, row := range repo.store.files {
+		if id != file.Id &&
+			row.FolderId == file.FolderId &&
+			row.TrashId == file.TrashId &&
+			strings.EqualFold(row.Name, file.Name) {
+			return fmt.Errorf("Error: The %v %w", file.Name, app.ErrFileExists)
+		}
+	}
+	return nil
+}
+
+func (repo *FileSystemRepository) TrashFile(ctx context.Context, file *app.File, item *app.TrashItem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.trashItems, item.Id, *item)
+
+		row, ok := repo.store.files[file.Id]
+		if ok {
+			row.TrashId = item.Id
+			put(tx, repo.store.files, row.Id, row)
+		}
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) UpdateFile(ctx context.Context, file *app.File) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.files[file.Id]
+		if !ok {
+			return nil
+		}
+
+		err := updateFile(&row, file.ByUpdate.StdMap())
+		if err != nil {
+			return err
+		}
+		err = repo.checkFileName(row)
+		if err != nil {
+			return err
+		}
+		put(tx, repo.store.files, row.Id, row)
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) CopyFile(ctx context.Context, src *app.File, dst *app.File) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		err := repo.createFile(tx, dst)
+		if err != nil {
+			return err
+		}
+
+		data, ok := repo.store.contents[src.Id]
+		if ok {
+			put(tx, repo.store.contents, dst.Id, data)
+		}
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) SaveFileContent(ctx context.Context, content *app.FileContent) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.contents, content.FileId, append([]byte{}, content.Data...))
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) GetFileContent(ctx context.Context, fileId string) (*app.FileContent, error) {
+	content := &app.FileContent{FileId: fileId, Data: []byte{}}
+	err := repo.store.run(ctx, func(tx *tx) error {
+		// the file which has been created but never written has no content
+		data, ok := repo.store.contents[fileId]
+		if ok {
+			content.Data = append(content.Data, data...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (repo *FileSystemRepository) ListGrants(ctx context.Context, fsId string, grantee string) ([]*app.Grant, error) {
+	var grants []*app.Grant
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.grants {
+			if row.FsId == fsId && strings.EqualFold(row.Grantee, grantee) {
+				grant := row
+				grants = append(grants, &grant)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (repo *FileSystemRepository) SaveGrant(ctx context.Context, grant *app.Grant) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.grants, grant.Id, *grant)
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) DeleteGrant(ctx context.Context, grant *app.Grant) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		remove(tx, repo.store.grants, grant.Id)
+		return nil
+	})
+}
+
+func (repo *FileSystemRepository) ListSharedFolders(ctx context.Context, grantee string) ([]*app.SharedFolder, error) {
+	var shared []*app.SharedFolder
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.grants {
+			folder, ok := repo.store.folders[row.FolderId]
+			if !strings.EqualFold(row.Grantee, grantee) || !ok || folder.TrashId != "" {
+				continue
+			}
+
+			// walk up to the root folder
+			var names []string
+			for folder.ParentFolderId != "" {
+				names = append([]string{folder.Name}, names...)
+				folder = repo.store.folders[folder.ParentFolderId]
+			}
+			shared = append(shared, &app.SharedFolder{
+				Grant: row,
+				Owner: repo.store.fileSystems[row.FsId].Username,
+				Path:  "/" + strings.Join(names, "/"),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shared, nil
+}
+
+func (repo *FileSystemRepository) ListTrashItems(ctx context.Context, fsId string) ([]*app.TrashItem, error) {
+	var items []*app.TrashItem
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.trashItems {
+			if row.FsId == fsId {
+				item := row
+				items = append(items, &item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (repo *FileSystemRepository) RestoreTrashItem(ctx context.Context, item *app.TrashItem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		for id, row := range repo.store.folders {
+			if row.TrashId != item.Id {
+				continue
+			}
+			row.TrashId = ""
+			if repo.checkFolderName(row) != nil {
+				return fmt.Errorf("Error: The %v %w", item.Path, app.ErrFolderExists)
+			}
+			put(tx, repo.store.folders, id, row)
+		}
+
+		for id, row := range repo.store.files {
+			if row.TrashId != item.Id {
+				continue
+			}
+			row.TrashId = ""
+			if repo.checkFileName(row) != nil {
+				return fmt.Errorf("Error: The %v %w", item.Path, app.ErrFileExists)
+			}
+			put(tx, repo.store.files, id, row)
+		}
+
+		remove(tx, repo.store.trashItems, item.Id)
+		return nil
+	})
+}
+
+// PurgeTrashItems permanently deletes the items and everything marked with their ids.
+// A folder or a file trashed before its parent folder still lives under the parent,
+// so everything under the purged folders is deleted too, together with its own trash items.
+func (repo *FileSystemRepository) PurgeTrashItems(ctx context.Context, items []*app.TrashItem) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		trashIds := make(map[string]bool, len(items))
+		for _, item := range items {
+			trashIds[item.Id] = true
+		}
+
+		folderIds := make(map[string]bool)
+		for id, row := range repo.store.folders {
+			if trashIds[row.TrashId] {
+				folderIds[id] = true
+			}
+		}
+
+		// the folders trashed before their ancestors have other trash ids
+		for grown := true; grown; {
+			grown = false
+			for id, row := range repo.store.folders {
+				if !folderIds[id] && folderIds[row.ParentFolderId] {
+					folderIds[id] = true
+					grown = true
+				}
+			}
+		}
+
+		purgedIds := maps.Clone(trashIds)
+		for id, row := range repo.store.files {
+			if trashIds[row.TrashId] || folderIds[row.FolderId] {
+				purgedIds[row.TrashId] = true
+				remove(tx, repo.store.contents, id)
+				remove(tx, repo.store.files, id)
+			}
+		}
+		for id, row := range repo.store.grants {
+			if folderIds[row.FolderId] {
+				remove(tx, repo.store.grants, id)
+			}
+		}
+		for id := range folderIds {
+			purgedIds[repo.store.folders[id].TrashId] = true
+			remove(tx, repo.store.folders, id)
+		}
+		for id := range purgedIds {
+			remove(tx, repo.store.trashItems, id)
+		}
+		return nil
+	})
+}
+
+// updateFolder applies the columns set by app.Folder.ByUpdate.
+func updateFolder(folder *app.Folder, data map[string]any) error {
+	for column, value := range data {
+		switch column {
+		case "name":
+			folder.Name = value.(string)
+		case "updated_time":
+			folder.UpdatedTime = value.(time.Time)
+		default:
+			return fmt.Errorf("memory: The column %v of folders is not supported", column)
+		}
+	}
+	return nil
+}
+
+// updateFile applies the columns set by app.File.ByUpdate.
+func updateFile(file *app.File, data map[string]any) error {
+	for column, value := range data {
+		switch column {
+		case "name":
+			file.Name = value.(string)
+		case "foldername":
+			file.Foldername = value.(string)
+		case "folder_id":
+			file.FolderId = value.(string)
+		case "size":
+			file.Size = value.(int64)
+		case "content_type":
+			file.ContentType = value.(string)
+		case "updated_time":
+			file.UpdatedTime = value.(time.Time)
+		default:
+			return fmt.Errorf("memory: The column %v of files is not supported", column)
+		}
+	}
+	return nil
+}
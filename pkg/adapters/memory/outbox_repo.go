@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+type OutboxRepository struct {
+	store *Store
+}
+
+func (repo *OutboxRepository) AppendOutbox(ctx context.Context, events []app.Event) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		for _, event := range events {
+			put(tx, repo.store.outbox, event.Id, app.OutboxEvent{Event: event})
+		}
+		return nil
+	})
+}
+
+func (repo *OutboxRepository) ListPendingOutbox(ctx context.Context, maxAttempts int, now time.Time) ([]*app.OutboxEvent, error) {
+	var events []*app.OutboxEvent
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.outbox {
+			isDue := row.NextAttemptTime == nil || !row.NextAttemptTime.After(now)
+			if row.DispatchedTime == nil && row.Attempts < maxAttempts && isDue {
+				event := row
+				events = append(events, &event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].OccurredTime.Equal(events[j].OccurredTime) {
+			return events[i].OccurredTime.Before(events[j].OccurredTime)
+		}
+		return events[i].Id < events[j].Id
+	})
+	return events, nil
+}
+
+func (repo *OutboxRepository) UpdateOutbox(ctx context.Context, event *app.OutboxEvent) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.outbox[event.Id]
+		if !ok {
+			return nil
+		}
+		row.Attempts = event.Attempts
+		row.LastError = event.LastError
+		row.DispatchedTime = event.DispatchedTime
+		row.NextAttemptTime = event.NextAttemptTime
+		put(tx, repo.store.outbox, event.Id, row)
+		return nil
+	})
+}
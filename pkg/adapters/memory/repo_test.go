@@ -0,0 +1,67 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/memory"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app/apptest"
+	"github.com/KScaesar/IsCoolLab2024/pkg/inject"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	apptest.RepositoryContract(t, func(t *testing.T) apptest.Repositories {
+		store := memory.NewStore()
+		return apptest.Repositories{
+			Uow:         memory.NewUnitOfWork(store),
+			UserRepo:    memory.NewUserRepository(store),
+			FsRepo:      memory.NewFileSystemRepository(store),
+			AuditRepo:   memory.NewAuditRepository(store),
+			OutboxRepo:  memory.NewOutboxRepository(store),
+			WebhookRepo: memory.NewWebhookRepository(store),
+		}
+	})
+}
+
+func TestStore_concurrent(t *testing.T) {
+	svc := inject.NewMemoryAppService()
+	ctx := app.ContextWithPrincipal(context.Background(), app.Principal{Username: "user1"})
+
+	err := svc.Register(ctx, app.RegisterParams{Username: "user1", Password: "user1-password", CreatedTime: time.Now()})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// half of the goroutines create the same folder
+			errs <- svc.CreateFolder(ctx, "user1", app.CreateFolderParams{
+				Foldername:  fmt.Sprintf("/folder%v", i%10),
+				CreatedTime: time.Now(),
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, app.ErrFolderExists)
+			failed++
+		}
+	}
+	require.Equal(t, 10, failed)
+
+	info, err := svc.GetUserInfo(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, 10, info.Folders)
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewStore() *Store {
+	return &Store{
+		users:       make(map[string]app.User),
+		fileSystems: make(map[string]app.FileSystem),
+		folders:     make(map[string]app.Folder),
+		files:       make(map[string]app.File),
+		contents:    make(map[string][]byte),
+		trashItems:  make(map[string]app.TrashItem),
+		grants:      make(map[string]app.Grant),
+		sessions:    make(map[string]app.Session),
+		auditLog:    make(map[string]app.AuditEntry),
+		outbox:      make(map[string]app.OutboxEvent),
+		webhooks:    make(map[string]app.Webhook),
+		deliveries:  make(map[string]app.WebhookDelivery),
+	}
+}
+
+// Store keeps the rows of the repositories in maps, it plays the role of the database.
+// The rows are copied in and out, so the entities held by app never alias the stored ones.
+//
+// A transaction holds the lock of Store until it ends,
+// its changes are recorded in an undo log, and reverted when it fails.
+type Store struct {
+	mu sync.Mutex
+
+	users       map[string]app.User // key is the lower case username
+	fileSystems map[string]app.FileSystem
+	folders     map[string]app.Folder
+	files       map[string]app.File
+	contents    map[string][]byte
+	trashItems  map[string]app.TrashItem
+	grants      map[string]app.Grant
+	sessions    map[string]app.Session // key is the token hash
+	auditLog    map[string]app.AuditEntry
+	outbox      map[string]app.OutboxEvent
+	webhooks    map[string]app.Webhook
+	deliveries  map[string]app.WebhookDelivery
+}
+
+type txKey struct{}
+
+type tx struct {
+	store *Store
+	undo  []func()
+}
+
+func (tx *tx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+}
+
+// run executes fn in the transaction carried by ctx,
+// or in a new transaction which only covers fn.
+func (store *Store) run(ctx context.Context, fn func(tx *tx) error) error {
+	current, ok := ctx.Value(txKey{}).(*tx)
+	if ok && current.store == store {
+		return fn(current)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	current = &tx{store: store}
+	err := fn(current)
+	if err != nil {
+		current.rollback()
+		return err
+	}
+	return nil
+}
+
+// put inserts or replaces the row of table, and records how to revert it.
+func put[T any](tx *tx, table map[string]T, key string, row T) {
+	old, ok := table[key]
+	tx.undo = append(tx.undo, func() {
+		if ok {
+			table[key] = old
+		} else {
+			delete(table, key)
+		}
+	})
+	table[key] = row
+}
+
+// remove deletes the row of table, and records how to revert it.
+func remove[T any](tx *tx, table map[string]T, key string) {
+	old, ok := table[key]
+	if !ok {
+		return
+	}
+	tx.undo = append(tx.undo, func() {
+		table[key] = old
+	})
+	delete(table, key)
+}
+
+func NewUnitOfWork(store *Store) *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+type UnitOfWork struct {
+	store *Store
+}
+
+// WithTx runs fn while holding the lock of Store.
+// A nested call joins the transaction which is already carried by ctx.
+func (uow *UnitOfWork) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return uow.store.run(ctx, func(tx *tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
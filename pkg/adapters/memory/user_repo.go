@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+type UserRepository struct {
+	store *Store
+}
+
+func (repo *UserRepository) CreateUser(ctx context.Context, user *app.User) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		_, ok := repo.store.users[key]
+		if ok {
+			return fmt.Errorf("Error: The %v %w", user.Username, app.ErrUserExists)
+		}
+
+		put(tx, repo.store.users, key, *user)
+		return nil
+	})
+}
+
+func (repo *UserRepository) QueryUserByName(ctx context.Context, username string) (*app.User, error) {
+	var user app.User
+	err := repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.users[strings.ToLower(username)]
+		if !ok {
+			return fmt.Errorf("Error: The %v %w", username, app.ErrUserNotExists)
+		}
+		user = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (repo *UserRepository) ListUsers(ctx context.Context) ([]*app.User, error) {
+	var users []*app.User
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.users {
+			user := row
+			users = append(users, &user)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
+
+func (repo *UserRepository) DeleteUser(ctx context.Context, user *app.User) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		row, ok := repo.store.users[key]
+		if ok && row.Username == user.Username {
+			remove(tx, repo.store.users, key)
+		}
+		for id, grant := range repo.store.grants {
+			if grant.Grantee == user.Username {
+				remove(tx, repo.store.grants, id)
+			}
+		}
+		for hash, session := range repo.store.sessions {
+			if session.Username == user.Username {
+				remove(tx, repo.store.sessions, hash)
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *UserRepository) RenameUser(ctx context.Context, user *app.User, newUsername string) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		row, ok := repo.store.users[key]
+		if !ok || row.Username != user.Username {
+			return nil
+		}
+
+		newKey := strings.ToLower(newUsername)
+		_, ok = repo.store.users[newKey]
+		if ok && newKey != key {
+			return fmt.Errorf("Error: The %v %w", newUsername, app.ErrUserExists)
+		}
+
+		remove(tx, repo.store.users, key)
+		row.Username = newUsername
+		put(tx, repo.store.users, newKey, row)
+
+		for id, fs := range repo.store.fileSystems {
+			if fs.Username == user.Username {
+				fs.Username = newUsername
+				put(tx, repo.store.fileSystems, id, fs)
+			}
+		}
+		for id, grant := range repo.store.grants {
+			if grant.Grantee == user.Username {
+				grant.Grantee = newUsername
+				put(tx, repo.store.grants, id, grant)
+			}
+		}
+		for hash, session := range repo.store.sessions {
+			if session.Username == user.Username {
+				session.Username = newUsername
+				put(tx, repo.store.sessions, hash, session)
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *UserRepository) UpdateQuota(ctx context.Context, user *app.User) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		row, ok := repo.store.users[key]
+		if !ok || row.Username != user.Username {
+			return nil
+		}
+
+		row.Quota = user.Quota
+		put(tx, repo.store.users, key, row)
+		return nil
+	})
+}
+
+func (repo *UserRepository) UpdatePassword(ctx context.Context, user *app.User) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		row, ok := repo.store.users[key]
+		if !ok || row.Username != user.Username {
+			return nil
+		}
+
+		row.PasswordHash = user.PasswordHash
+		put(tx, repo.store.users, key, row)
+		return nil
+	})
+}
+
+func (repo *UserRepository) UpdateRole(ctx context.Context, user *app.User) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		key := strings.ToLower(user.Username)
+		row, ok := repo.store.users[key]
+		if !ok || row.Username != user.Username {
+			return nil
+		}
+
+		row.Role = user.Role
+		put(tx, repo.store.users, key, row)
+		return nil
+	})
+}
+
+func (repo *UserRepository) CountAdmins(ctx context.Context) (int, error) {
+	var count int
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.users {
+			if row.IsAdmin() {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (repo *UserRepository) CreateSession(ctx context.Context, session *app.Session) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.sessions, session.TokenHash, *session)
+		return nil
+	})
+}
+
+func (repo *UserRepository) FindSession(ctx context.Context, tokenHash string) (*app.Session, error) {
+	var session app.Session
+	err := repo.store.run(ctx, func(tx *tx) error {
+		row, ok := repo.store.sessions[tokenHash]
+		if !ok {
+			return fmt.Errorf("Error: The session %w", app.ErrSessionNotExists)
+		}
+		session = row
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (repo *UserRepository) DeleteSession(ctx context.Context, session *app.Session) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		remove(tx, repo.store.sessions, session.TokenHash)
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+type WebhookRepository struct {
+	store *Store
+}
+
+func (repo *WebhookRepository) CreateWebhook(ctx context.Context, hook *app.Webhook) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.webhooks, hook.Id, *hook)
+		return nil
+	})
+}
+
+func (repo *WebhookRepository) ListWebhooks(ctx context.Context, fsId string) ([]*app.Webhook, error) {
+	var hooks []*app.Webhook
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.webhooks {
+			if row.FsId == fsId {
+				hook := row
+				hooks = append(hooks, &hook)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		if !hooks[i].CreatedTime.Equal(hooks[j].CreatedTime) {
+			return hooks[i].CreatedTime.Before(hooks[j].CreatedTime)
+		}
+		return hooks[i].Id < hooks[j].Id
+	})
+	return hooks, nil
+}
+
+func (repo *WebhookRepository) DeleteWebhook(ctx context.Context, hook *app.Webhook) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		remove(tx, repo.store.webhooks, hook.Id)
+		return nil
+	})
+}
+
+func (repo *WebhookRepository) AppendDelivery(ctx context.Context, delivery *app.WebhookDelivery) error {
+	return repo.store.run(ctx, func(tx *tx) error {
+		put(tx, repo.store.deliveries, delivery.Id, *delivery)
+		return nil
+	})
+}
+
+func (repo *WebhookRepository) ListDeliveries(ctx context.Context, fsId string) ([]*app.WebhookDelivery, error) {
+	var deliveries []*app.WebhookDelivery
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.deliveries {
+			if row.FsId == fsId {
+				delivery := row
+				deliveries = append(deliveries, &delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].DeliveredTime.Equal(deliveries[j].DeliveredTime) {
+			return deliveries[i].DeliveredTime.Before(deliveries[j].DeliveredTime)
+		}
+		return deliveries[i].Id < deliveries[j].Id
+	})
+	return deliveries, nil
+}
+
+func (repo *WebhookRepository) CountDeliveries(ctx context.Context, webhookId string, eventId string) (int, bool, error) {
+	var attempts int
+	var delivered bool
+	err := repo.store.run(ctx, func(tx *tx) error {
+		for _, row := range repo.store.deliveries {
+			if row.WebhookId == webhookId && row.EventId == eventId {
+				attempts++
+				delivered = delivered || row.Error == ""
+			}
+		}
+		return nil
+	})
+	return attempts, delivered, err
+}
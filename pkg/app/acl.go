@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+// Permission is what a user may do in a shared folder and its subfolders,
+// a permission includes the weaker ones.
+type Permission string
+
+const (
+	// Permission_Read lists the folders and files, and reads the files.
+	Permission_Read Permission = "read"
+
+	// Permission_Write also creates, renames, writes, moves and deletes the children.
+	Permission_Write Permission = "write"
+
+	// Permission_Admin also shares the folder with other users.
+	Permission_Admin Permission = "admin"
+
+	// Permission_Owner belongs to the owner of the FileSystem only, it can't be granted.
+	// The owner also manages the trash.
+	Permission_Owner Permission = "owner"
+)
+
+// ParsePermission accepts the permissions which can be granted.
+func ParsePermission(s string) (Permission, error) {
+	permission := Permission(strings.ToLower(s))
+	switch permission {
+	case Permission_Read, Permission_Write, Permission_Admin:
+		return permission, nil
+	}
+	return "", fmt.Errorf("Error: The permission %v %w", s, ErrInvalidParams)
+}
+
+func (p Permission) rank() int {
+	switch p {
+	case Permission_Read:
+		return 1
+	case Permission_Write:
+		return 2
+	case Permission_Admin:
+		return 3
+	case Permission_Owner:
+		return 4
+	}
+	return 0
+}
+
+// Allows reports whether p includes want.
+func (p Permission) Allows(want Permission) bool {
+	return p.rank() >= want.rank()
+}
+
+func (p Permission) verb() string {
+	switch p {
+	case Permission_Admin:
+		return "share"
+	case Permission_Owner:
+		return "manage"
+	}
+	return string(p)
+}
+
+func newGrant(fs *FileSystem, folder *Folder, grantee string, permission Permission, createdTime time.Time) *Grant {
+	return &Grant{
+		Id:          pkg.NewUlid(),
+		FsId:        fs.Id,
+		FolderId:    folder.Id,
+		Grantee:     grantee,
+		Permission:  permission,
+		CreatedTime: createdTime,
+	}
+}
+
+// Grant shares a folder of a FileSystem with the grantee,
+// the subfolders inherit it, and the strongest grant on the path wins.
+// A folder has at most one grant for each grantee.
+type Grant struct {
+	Id          string     `gorm:"column:id;type:char(26);not null;primaryKey"`
+	FsId        string     `gorm:"column:fs_id;type:char(26);not null;index"`
+	FolderId    string     `gorm:"column:folder_id;type:char(26);not null"`
+	Grantee     string     `gorm:"column:grantee;type:varchar(64);not null;index"`
+	Permission  Permission `gorm:"column:permission;type:varchar(16);not null"`
+	CreatedTime time.Time  `gorm:"column:created_time;not null"`
+}
+
+// SharedFolder is a live folder shared with a user, Path is where the folder is in the FileSystem of Owner.
+type SharedFolder struct {
+	Grant Grant  `gorm:"embedded"`
+	Owner string `gorm:"column:owner"`
+	Path  string `gorm:"-"`
+}
+
+func (fs *FileSystem) IsOwner(username string) bool {
+	return strings.EqualFold(fs.Username, username)
+}
+
+// Permission returns what actor may do on path, by the grants of actor on the folders along path.
+// The folders on path must be loaded, path stops at the first folder which doesn't exist,
+// so a missing path is judged by its existing ancestors.
+func (fs *FileSystem) Permission(actor string, path string, grants []*Grant) Permission {
+	if fs.IsOwner(actor) {
+		return Permission_Owner
+	}
+
+	granted := make(map[string]Permission, len(grants))
+	for _, grant := range grants {
+		granted[grant.FolderId] = grant.Permission
+	}
+
+	var permission Permission
+	folder := &fs.Root
+	for i, segments := 0, splitFolderSegments(path); ; i++ {
+		if granted[folder.Id].rank() > permission.rank() {
+			permission = granted[folder.Id]
+		}
+		if i == len(segments) {
+			break
+		}
+
+		child, ok := folder.findChildFolder(segments[i])
+		if !ok {
+			break
+		}
+		folder = child
+	}
+	return permission
+}
+
+// Authorize reports ErrPermissionDenied when actor may not do want on path.
+func (fs *FileSystem) Authorize(actor string, path string, grants []*Grant, want Permission) error {
+	if fs.Permission(actor, path, grants).Allows(want) {
+		return nil
+	}
+	return fmt.Errorf("Error: The %v %w to %v %v.", actor, ErrPermissionDenied, want.verb(), cleanPath(path))
+}
+
+// Share grants permission on the folder on path to grantee, or changes the former grant.
+func (fs *FileSystem) Share(path string, grantee string, permission Permission, grants []*Grant, createdTime time.Time) (*Grant, error) {
+	if fs.IsOwner(grantee) {
+		return nil, fmt.Errorf("Error: The %v %w", grantee, ErrInvalidParams)
+	}
+
+	folder, err := fs.Root.findFolder(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grant := range grants {
+		if grant.FolderId == folder.Id && strings.EqualFold(grant.Grantee, grantee) {
+			grant.Permission = permission
+			return grant, nil
+		}
+	}
+	return newGrant(fs, folder, grantee, permission, createdTime), nil
+}
+
+// Unshare finds the grant of grantee on the folder on path.
+func (fs *FileSystem) Unshare(path string, grantee string, grants []*Grant) (*Grant, error) {
+	folder, err := fs.Root.findFolder(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grant := range grants {
+		if grant.FolderId == folder.Id && strings.EqualFold(grant.Grantee, grantee) {
+			return grant, nil
+		}
+	}
+	return nil, fmt.Errorf("Error: The share of %v with %v %w", cleanPath(path), grantee, ErrGrantNotExists)
+}
+
+// authorize checks the principal of ctx may do want on path of fs,
+// the folders on path must be loaded.
+// Without a principal, it reports ErrUnauthenticated.
+func authorize(ctx context.Context, fsRepo FileSystemRepository, fs *FileSystem, path string, want Permission) error {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if fs.IsOwner(principal.Username) {
+		return nil
+	}
+
+	grants, err := fsRepo.ListGrants(ctx, fs.Id, principal.Username)
+	if err != nil {
+		return err
+	}
+	return fs.Authorize(principal.Username, path, grants, want)
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+type ShareService interface {
+	ShareFolder(ctx context.Context, params ShareFolderParams) error
+	UnshareFolder(ctx context.Context, params UnshareFolderParams) error
+	ListSharedWithMe(ctx context.Context, username string) ([]ViewSharedFolder, error)
+}
+
+func NewShareUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *ShareUseCase {
+	return &ShareUseCase{
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
+		Time:      pkg.NewTimeFunc(),
+	}
+}
+
+type ShareUseCase struct {
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
+
+	// Time stamps the audit entries of unshare, which doesn't take a time.
+	Time pkg.TimeFunc
+}
+
+func (uc *ShareUseCase) ShareFolder(ctx context.Context, params ShareFolderParams) error {
+	permission, err := ParsePermission(params.Permission)
+	if err != nil {
+		return err
+	}
+
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, params.Owner)
+		if err != nil {
+			return err
+		}
+
+		_, err = loadFolder(ctx, uc.FsRepo, &fs.Root, params.Foldername)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Admin)
+		if err != nil {
+			return err
+		}
+
+		grantee, err := uc.UserRepo.QueryUserByName(ctx, params.Grantee)
+		if err != nil {
+			return err
+		}
+
+		grants, err := uc.FsRepo.ListGrants(ctx, fs.Id, grantee.Username)
+		if err != nil {
+			return err
+		}
+
+		// the permission before sharing, Share changes the existing grant
+		previous := make(map[string]Permission, len(grants))
+		for _, grant := range grants {
+			previous[grant.Id] = grant.Permission
+		}
+
+		grant, err := fs.Share(params.Foldername, grantee.Username, permission, grants, params.CreatedTime)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.SaveGrant(ctx, grant)
+		if err != nil {
+			return err
+		}
+
+		path := fs.Root.storedPath(params.Foldername)
+		before := ""
+		if old, ok := previous[grant.Id]; ok {
+			before = auditGrant(path, grant.Grantee, old)
+		}
+		after := auditGrant(path, grant.Grantee, grant.Permission)
+		entry := newAuditEntry(ctx, fs, AuditAction_ShareFolder, grant.Id, grant.FolderId, before, after, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *ShareUseCase) UnshareFolder(ctx context.Context, params UnshareFolderParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, params.Owner)
+		if err != nil {
+			return err
+		}
+
+		_, err = loadFolder(ctx, uc.FsRepo, &fs.Root, params.Foldername)
+		if err != nil {
+			return err
+		}
+
+		principal, err := requirePrincipal(ctx)
+		if err != nil {
+			return err
+		}
+
+		// the grantee can leave the folder by itself
+		if !strings.EqualFold(principal.Username, params.Grantee) {
+			err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Admin)
+			if err != nil {
+				return err
+			}
+		}
+
+		grants, err := uc.FsRepo.ListGrants(ctx, fs.Id, params.Grantee)
+		if err != nil {
+			return err
+		}
+
+		grant, err := fs.Unshare(params.Foldername, params.Grantee, grants)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.DeleteGrant(ctx, grant)
+		if err != nil {
+			return err
+		}
+
+		before := auditGrant(fs.Root.storedPath(params.Foldername), grant.Grantee, grant.Permission)
+		entry := newAuditEntry(ctx, fs, AuditAction_UnshareFolder, grant.Id, grant.FolderId, before, "", uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *ShareUseCase) ListSharedWithMe(ctx context.Context, username string) ([]ViewSharedFolder, error) {
+	var response []ViewSharedFolder
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		principal, err := requirePrincipal(ctx)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(principal.Username, user.Username) {
+			return fmt.Errorf("Error: The %v %w to list the folders shared with %v.", principal.Username, ErrPermissionDenied, user.Username)
+		}
+
+		shared, err := uc.FsRepo.ListSharedFolders(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+		if len(shared) == 0 {
+			return ErrListSharedEmpty
+		}
+		slices.SortFunc(shared, func(a, b *SharedFolder) int {
+			if a.Owner != b.Owner {
+				return strings.Compare(a.Owner, b.Owner)
+			}
+			return strings.Compare(a.Path, b.Path)
+		})
+
+		response = make([]ViewSharedFolder, len(shared))
+		for i, folder := range shared {
+			response[i] = ToViewSharedFolder(folder, user.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
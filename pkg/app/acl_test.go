@@ -0,0 +1,132 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+func TestFileSystem_Permission(t *testing.T) {
+	createdTime := pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now()
+	fs := testFileSystem()
+	home, _ := fs.Root.findFolder("/home")
+	dev, _ := fs.Root.findFolder("/home/dev")
+	grants := []*Grant{
+		newGrant(fs, home, "alice", Permission_Read, createdTime),
+		newGrant(fs, dev, "alice", Permission_Write, createdTime),
+	}
+
+	tests := []struct {
+		name  string
+		actor string
+		path  string
+		want  Permission
+	}{
+		{
+			name:  "owner",
+			actor: "CAESAR",
+			path:  "/tmp",
+			want:  Permission_Owner,
+		},
+		{
+			name:  "granted folder",
+			actor: "alice",
+			path:  "/home",
+			want:  Permission_Read,
+		},
+		{
+			name:  "the stronger grant of a subfolder",
+			actor: "alice",
+			path:  "/HOME/dev",
+			want:  Permission_Write,
+		},
+		{
+			name:  "inherited by a missing path",
+			actor: "alice",
+			path:  "/home/prod/app",
+			want:  Permission_Read,
+		},
+		{
+			name:  "not shared",
+			actor: "alice",
+			path:  "/tmp",
+			want:  "",
+		},
+		{
+			name:  "the parent isn't shared",
+			actor: "alice",
+			path:  "/",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := fs.Permission(tt.actor, tt.path, grants)
+			if got != tt.want {
+				t.Errorf("Permission() got=%v, want=%v", got, tt.want)
+			}
+		})
+	}
+
+	err := fs.Authorize("alice", "/home", grants, Permission_Write)
+	if !errors.Is(err, ErrPermissionDenied) || err.Error() != "Error: The alice doesn't have the permission to write /home." {
+		t.Errorf("Authorize() error=%v, want=%v", err, ErrPermissionDenied)
+	}
+	err = fs.Authorize("alice", "/home/dev/go", grants, Permission_Write)
+	if err != nil {
+		t.Errorf("Authorize() error=%v, want=nil", err)
+	}
+}
+
+func TestFileSystem_Share(t *testing.T) {
+	createdTime := pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now()
+	fs := testFileSystem()
+
+	grant, err := fs.Share("/home", "alice", Permission_Read, nil, createdTime)
+	if err != nil {
+		t.Fatalf("Share() error=%v", err)
+	}
+	grants := []*Grant{grant}
+
+	// sharing again changes the former grant
+	again, err := fs.Share("/HOME", "Alice", Permission_Admin, grants, createdTime)
+	if err != nil || again.Id != grant.Id || again.Permission != Permission_Admin {
+		t.Errorf("Share() grant=%v error=%v, want the former grant", again, err)
+	}
+
+	_, err = fs.Share("/home", "caesar", Permission_Read, grants, createdTime)
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("Share() error=%v, want=%v", err, ErrInvalidParams)
+	}
+	_, err = fs.Share("/app", "alice", Permission_Read, grants, createdTime)
+	if !errors.Is(err, ErrFolderNotExists) {
+		t.Errorf("Share() error=%v, want=%v", err, ErrFolderNotExists)
+	}
+
+	found, err := fs.Unshare("/home", "alice", grants)
+	if err != nil || found.Id != grant.Id {
+		t.Errorf("Unshare() grant=%v error=%v", found, err)
+	}
+	_, err = fs.Unshare("/home/dev", "alice", grants)
+	if !errors.Is(err, ErrGrantNotExists) {
+		t.Errorf("Unshare() error=%v, want=%v", err, ErrGrantNotExists)
+	}
+}
+
+func TestParsePermission(t *testing.T) {
+	for _, s := range []string{"read", "WRITE", "admin"} {
+		_, err := ParsePermission(s)
+		if err != nil {
+			t.Errorf("ParsePermission(%v) error=%v", s, err)
+		}
+	}
+	for _, s := range []string{"owner", "", "execute"} {
+		_, err := ParsePermission(s)
+		if !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("ParsePermission(%v) error=%v, want=%v", s, err, ErrInvalidParams)
+		}
+	}
+}
@@ -0,0 +1,1735 @@
+// Package apptest provides the contract of the repositories required by app,
+// every implementation runs it from its own tests.
+package apptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+type Repositories struct {
+	Uow         app.UnitOfWork
+	UserRepo    app.UserRepository
+	FsRepo      app.FileSystemRepository
+	AuditRepo   app.AuditRepository
+	OutboxRepo  app.OutboxRepository
+	WebhookRepo app.WebhookRepository
+}
+
+func (repos Repositories) service() *app.Service {
+	return repos.serviceWithSender(nil)
+}
+
+// serviceWithSender builds the use cases on the repositories,
+// the events of the use cases go through the outbox to the returned EventBus.
+// The webhooks are posted by sender, only the cases which add webhooks need one.
+func (repos Repositories) serviceWithSender(sender app.WebhookSender) *app.Service {
+	bus := app.NewEventBus(repos.OutboxRepo)
+	repos.Uow = app.NewEventUnitOfWork(repos.Uow, bus)
+	return &app.Service{
+		UserService:    app.NewUserUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		AuthService:    app.NewAuthUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		FolderService:  app.NewFolderUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		FileService:    app.NewFileUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		TrashService:   app.NewTrashUseCase(repos.Uow, repos.FsRepo, repos.AuditRepo),
+		StatService:    app.NewStatUseCase(repos.Uow, repos.FsRepo),
+		ShareService:   app.NewShareUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		AuditService:   app.NewAuditUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.AuditRepo),
+		WebhookService: app.NewWebhookUseCase(repos.Uow, repos.UserRepo, repos.FsRepo, repos.WebhookRepo, repos.AuditRepo, bus, sender),
+		Events:         bus,
+	}
+}
+
+// RepositoryContract verifies the behaviour which app relies on.
+// newRepositories is called by every case, and returns the repositories backed by an empty storage.
+func RepositoryContract(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repos Repositories)
+	}{
+		{name: "user", run: testUser},
+		{name: "load file system", run: testLoadFileSystem},
+		{name: "find children", run: testFindChildren},
+		{name: "list children", run: testListChildren},
+		{name: "sort children", run: testSortChildren},
+		{name: "unique names", run: testUniqueNames},
+		{name: "update", run: testUpdate},
+		{name: "file content", run: testFileContent},
+		{name: "metadata", run: testMetadata},
+		{name: "quota", run: testQuota},
+		{name: "role", run: testRole},
+		{name: "share", run: testShare},
+		{name: "auth", run: testAuth},
+		{name: "audit", run: testAudit},
+		{name: "events", run: testEvents},
+		{name: "webhook", run: testWebhook},
+		{name: "trash", run: testTrash},
+		{name: "delete file system", run: testDeleteFileSystem},
+		{name: "unit of work", run: testUnitOfWork},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepositories(t))
+		})
+	}
+}
+
+var createdTime = time.Date(2024, 5, 27, 23, 0, 0, 0, time.UTC)
+
+// as returns the context of username who has logged in,
+// the use cases refuse a context without a principal.
+func as(username string) context.Context {
+	return app.ContextWithPrincipal(context.Background(), app.Principal{Username: username})
+}
+
+// register creates username with the password "[username]-password".
+func register(t *testing.T, svc *app.Service, username string) {
+	t.Helper()
+	err := svc.Register(context.Background(), app.RegisterParams{
+		Username:    username,
+		Password:    username + "-password",
+		CreatedTime: createdTime,
+	})
+	require.NoError(t, err)
+}
+
+// seed registers user1 with the tree:
+//
+//	/readme
+//	/etc
+//	/home/dev/dev.conf
+//	/home/dev/go/go.mod
+func seed(t *testing.T, repos Repositories) *app.Service {
+	svc := repos.service()
+	ctx := as("user1")
+
+	register(t, svc, "user1")
+
+	for i, foldername := range []string{"/home", "/home/dev", "/home/dev/go", "/etc"} {
+		err := svc.CreateFolder(ctx, "user1", app.CreateFolderParams{
+			Foldername:  foldername,
+			Description: "folder " + foldername,
+			CreatedTime: createdTime.Add(time.Duration(i+1) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	for i, file := range [][2]string{{"/home/dev/go", "go.mod"}, {"/home/dev", "dev.conf"}, {"/", "readme"}} {
+		err := svc.CreateFile(ctx, "user1", app.CreateFileParams{
+			Foldername:  file[0],
+			Filename:    file[1],
+			Description: "file " + file[1],
+			CreatedTime: createdTime.Add(time.Duration(i+1) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	return svc
+}
+
+// listPending returns the pending events of the outbox, including the ones waiting for their backoff.
+func listPending(t *testing.T, repos Repositories) []*app.OutboxEvent {
+	pending, err := repos.OutboxRepo.ListPendingOutbox(context.Background(), app.EventMaxAttempts, time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return pending
+}
+
+func testUser(t *testing.T, repos Repositories) {
+	ctx := as("user1")
+
+	for _, username := range []string{"user2", "user1"} {
+		err := repos.UserRepo.CreateUser(ctx, &app.User{Username: username, CreatedTime: createdTime})
+		require.NoError(t, err)
+	}
+	err := repos.UserRepo.CreateUser(ctx, &app.User{Username: "USER1", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrUserExists)
+
+	user, err := repos.UserRepo.QueryUserByName(ctx, "User1")
+	require.NoError(t, err)
+	require.Equal(t, "user1", user.Username)
+	require.True(t, user.CreatedTime.Equal(createdTime), user.CreatedTime)
+
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user3")
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	users, err := repos.UserRepo.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, []string{"user1", "user2"}, []string{users[0].Username, users[1].Username})
+
+	err = repos.UserRepo.DeleteUser(ctx, user)
+	require.NoError(t, err)
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+}
+
+func testLoadFileSystem(t *testing.T, repos Repositories) {
+	seed(t, repos)
+	ctx := as("user1")
+
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "USER1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"/",
+		"/etc",
+		"/home",
+		"/home/dev",
+		"/home/dev/dev.conf",
+		"/home/dev/go",
+		"/home/dev/go/go.mod",
+		"/readme",
+	}, FileSystemPaths(fs))
+
+	fs.Root.Walk(func(folder *app.Folder) {
+		require.Equal(t, fs.Id, folder.FsId, folder.Name)
+		for _, file := range folder.Files {
+			require.Equal(t, fs.Id, file.FsId, file.Name)
+			require.Equal(t, folder.Id, file.FolderId, file.Name)
+			require.Equal(t, folder.Name, file.Foldername, file.Name)
+		}
+		if folder.Name == "go" {
+			require.True(t, folder.CreatedTime.Equal(createdTime.Add(3*time.Second)), folder.CreatedTime)
+		}
+	})
+
+	for _, strategy := range app.LoadStrategies {
+		other, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{Strategy: strategy})
+		require.NoError(t, err, strategy)
+		require.Equal(t, dumpFileSystem(fs), dumpFileSystem(other), strategy)
+	}
+
+	_, err = repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{Strategy: "unknown"})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+
+	_, err = repos.FsRepo.LoadFileSystem(ctx, "user2", app.LoadFileSystemOptions{})
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	// the loaded entities are detached from the storage
+	fs.Root.Folders = nil
+	fs, err = repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Len(t, fs.Root.Folders, 2)
+}
+
+func testFindChildren(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "USER1")
+	require.NoError(t, err)
+	require.Equal(t, "user1", fs.Username)
+	require.Equal(t, "/", fs.Root.Name)
+	require.Empty(t, fs.Root.Folders)
+	require.Empty(t, fs.Root.Files)
+
+	_, err = repos.FsRepo.FindFileSystem(ctx, "user2")
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	home, err := repos.FsRepo.FindChildFolder(ctx, &fs.Root, "HOME")
+	require.NoError(t, err)
+	require.Equal(t, "home", home.Name)
+	require.Equal(t, fs.Root.Id, home.ParentFolderId)
+	require.Empty(t, home.Folders)
+
+	dev, err := repos.FsRepo.FindChildFolder(ctx, home, "dev")
+	require.NoError(t, err)
+	file, err := repos.FsRepo.FindChildFile(ctx, dev, "DEV.conf")
+	require.NoError(t, err)
+	require.Equal(t, "dev.conf", file.Name)
+	require.Equal(t, dev.Id, file.FolderId)
+
+	// a child is found under its own parent only
+	_, err = repos.FsRepo.FindChildFolder(ctx, &fs.Root, "dev")
+	require.ErrorIs(t, err, app.ErrFolderNotExists)
+	_, err = repos.FsRepo.FindChildFile(ctx, home, "dev.conf")
+	require.ErrorIs(t, err, app.ErrFileNotExists)
+
+	// the trash hides the subtree
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home", DeletedTime: time.Now()})
+	require.NoError(t, err)
+	_, err = repos.FsRepo.FindChildFolder(ctx, &fs.Root, "home")
+	require.ErrorIs(t, err, app.ErrFolderNotExists)
+	_, err = repos.FsRepo.FindChildFolder(ctx, home, "dev")
+	require.ErrorIs(t, err, app.ErrFolderNotExists)
+	_, err = repos.FsRepo.FindChildFile(ctx, dev, "dev.conf")
+	require.ErrorIs(t, err, app.ErrFileNotExists)
+}
+
+func testListChildren(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	for i, filename := range []string{"c.conf", "A.conf", "b.conf", "x_y.txt"} {
+		err := svc.CreateFile(ctx, "user1", app.CreateFileParams{
+			Foldername:  "/etc",
+			Filename:    filename,
+			CreatedTime: createdTime.Add(time.Duration(i+1) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	etc, err := repos.FsRepo.FindChildFolder(ctx, &fs.Root, "etc")
+	require.NoError(t, err)
+
+	listFiles := func(opts app.ListChildrenOptions) ([]string, error) {
+		files, err := repos.FsRepo.ListChildFiles(ctx, etc, opts)
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = file.Name
+		}
+		return names, err
+	}
+	fileId := func(name string) string {
+		file, err := repos.FsRepo.FindChildFile(ctx, etc, name)
+		require.NoError(t, err)
+		return file.Id
+	}
+	byCreatedDesc := &app.FileSystemSortParams{Keys: []pkg.SortKey{{Key: app.SortKeyCreated, Kind: pkg.SortKind_Desc}}}
+
+	tests := []struct {
+		name    string
+		opts    app.ListChildrenOptions
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "by default",
+			opts: app.ListChildrenOptions{},
+			want: []string{"A.conf", "b.conf", "c.conf", "x_y.txt"},
+		},
+		{
+			name: "by name",
+			opts: app.ListChildrenOptions{Sort: &app.FileSystemSortParams{Keys: []pkg.SortKey{{Key: app.SortKeyName, Kind: pkg.SortKind_Desc}}}},
+			want: []string{"x_y.txt", "c.conf", "b.conf", "A.conf"},
+		},
+		{
+			name: "by created",
+			opts: app.ListChildrenOptions{Sort: byCreatedDesc},
+			want: []string{"x_y.txt", "b.conf", "A.conf", "c.conf"},
+		},
+		{
+			name: "limit",
+			opts: app.ListChildrenOptions{Page: app.PageParams{Limit: 2}},
+			want: []string{"A.conf", "b.conf"},
+		},
+		{
+			name: "offset",
+			opts: app.ListChildrenOptions{Page: app.PageParams{Offset: 1}},
+			want: []string{"b.conf", "c.conf", "x_y.txt"},
+		},
+		{
+			name: "limit and offset",
+			opts: app.ListChildrenOptions{Page: app.PageParams{Limit: 1, Offset: 1}},
+			want: []string{"b.conf"},
+		},
+		{
+			name: "offset beyond the end",
+			opts: app.ListChildrenOptions{Page: app.PageParams{Offset: 4}},
+			want: []string{},
+		},
+		{
+			name: "cursor",
+			opts: app.ListChildrenOptions{Sort: byCreatedDesc, Page: app.PageParams{Limit: 2, Cursor: fileId("b.conf")}},
+			want: []string{"A.conf", "c.conf"},
+		},
+		{
+			name: "cursor of the last page",
+			opts: app.ListChildrenOptions{Page: app.PageParams{Cursor: fileId("x_y.txt")}},
+			want: []string{},
+		},
+		{
+			name:    "cursor of another folder",
+			opts:    app.ListChildrenOptions{Page: app.PageParams{Cursor: fs.Root.Id}},
+			wantErr: app.ErrInvalidParams,
+		},
+		{
+			name:    "cursor with offset",
+			opts:    app.ListChildrenOptions{Page: app.PageParams{Offset: 1, Cursor: fileId("b.conf")}},
+			wantErr: app.ErrInvalidParams,
+		},
+		{
+			name: "substring",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{Name: "CONF"}},
+			want: []string{"A.conf", "b.conf", "c.conf"},
+		},
+		{
+			name: "substring is literal",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{Name: "_"}},
+			want: []string{"x_y.txt"},
+		},
+		{
+			name: "glob",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{Name: "?.CONF"}},
+			want: []string{"A.conf", "b.conf", "c.conf"},
+		},
+		{
+			name: "glob matches the whole name",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{Name: "b*"}},
+			want: []string{"b.conf"},
+		},
+		{
+			name: "created range is exclusive",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{
+				CreatedAfter:  createdTime.Add(time.Hour),
+				CreatedBefore: createdTime.Add(4 * time.Hour),
+			}},
+			want: []string{"A.conf", "b.conf"},
+		},
+		{
+			name: "created after in another time zone",
+			opts: app.ListChildrenOptions{Filter: app.ListFilterParams{
+				CreatedAfter: createdTime.Add(3 * time.Hour).In(time.FixedZone("UTC+8", 8*60*60)),
+			}},
+			want: []string{"x_y.txt"},
+		},
+		{
+			name: "filter and cursor",
+			opts: app.ListChildrenOptions{
+				Filter: app.ListFilterParams{Name: "*.conf"},
+				Page:   app.PageParams{Cursor: fileId("A.conf")},
+			},
+			want: []string{"b.conf", "c.conf"},
+		},
+	}
+
+	for _, tt := range tests {
+		names, err := listFiles(tt.opts)
+		require.ErrorIs(t, err, tt.wantErr, tt.name)
+		if tt.wantErr == nil {
+			require.Equal(t, tt.want, names, tt.name)
+		}
+	}
+
+	folders, err := repos.FsRepo.ListChildFolders(ctx, &fs.Root, app.ListChildrenOptions{
+		Sort: &app.FileSystemSortParams{Keys: []pkg.SortKey{{Key: app.SortKeyCreated, Kind: pkg.SortKind_Asc}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, folders, 2)
+	require.Equal(t, []string{"home", "etc"}, []string{folders[0].Name, folders[1].Name})
+	require.Empty(t, folders[0].Folders, "the children of the listed folders aren't loaded")
+
+	folders, err = repos.FsRepo.ListChildFolders(ctx, &fs.Root, app.ListChildrenOptions{
+		Filter: app.ListFilterParams{Name: "ho"},
+		Page:   app.PageParams{Limit: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, folders, 1)
+	require.Equal(t, "home", folders[0].Name)
+}
+
+// The storage rejects the duplicated names which bypass the checks of app,
+// such as two processes creating the same name at the same time.
+func testSortChildren(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	files := []struct {
+		name        string
+		description string
+		content     string
+		created     time.Duration
+	}{
+		{"file10.txt", "b", "0123456789abcdefghijklmnopqrst", time.Hour},
+		{"File3.txt", "a", "0123456789", 2 * time.Hour},
+		{"file1.txt", "B", "0123456789abcdefghij", time.Hour},
+		{"file2.txt", "a", "0123456789", 3 * time.Hour},
+	}
+	for _, file := range files {
+		err := svc.CreateFile(ctx, "user1", app.CreateFileParams{
+			Foldername:  "/etc",
+			Filename:    file.name,
+			Description: file.description,
+			CreatedTime: createdTime.Add(file.created),
+		})
+		require.NoError(t, err)
+		err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/etc", Filename: file.name, Content: []byte(file.content)})
+		require.NoError(t, err)
+	}
+
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	etc, err := repos.FsRepo.FindChildFolder(ctx, &fs.Root, "etc")
+	require.NoError(t, err)
+
+	fileId := func(name string) string {
+		file, err := repos.FsRepo.FindChildFile(ctx, etc, name)
+		require.NoError(t, err)
+		return file.Id
+	}
+	sortBy := func(keys string) *app.FileSystemSortParams {
+		params, err := app.ParseFileSystemSortParams(keys, "")
+		require.NoError(t, err)
+		return params
+	}
+	natural := sortBy("name")
+	natural.Natural = true
+
+	// file10.txt and file1.txt have the same description and created time,
+	// the ids created in the same millisecond aren't ordered, so the order of the tie is read from them.
+	tied := []string{"file10.txt", "file1.txt"}
+	if fileId("file1.txt") < fileId("file10.txt") {
+		tied = []string{"file1.txt", "file10.txt"}
+	}
+
+	tests := []struct {
+		name    string
+		opts    app.ListChildrenOptions
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "case-sensitive name by default",
+			opts: app.ListChildrenOptions{},
+			want: []string{"File3.txt", "file1.txt", "file10.txt", "file2.txt"},
+		},
+		{
+			name: "ignore case",
+			opts: app.ListChildrenOptions{Sort: &app.FileSystemSortParams{IgnoreCase: true}},
+			want: []string{"file1.txt", "file10.txt", "file2.txt", "File3.txt"},
+		},
+		{
+			name: "natural",
+			opts: app.ListChildrenOptions{Sort: natural},
+			want: []string{"File3.txt", "file1.txt", "file2.txt", "file10.txt"},
+		},
+		{
+			name: "natural and ignore case",
+			opts: app.ListChildrenOptions{Sort: &app.FileSystemSortParams{Natural: true, IgnoreCase: true}},
+			want: []string{"file1.txt", "file2.txt", "File3.txt", "file10.txt"},
+		},
+		{
+			name: "natural with cursor",
+			opts: app.ListChildrenOptions{Sort: natural, Page: app.PageParams{Limit: 2, Cursor: fileId("file1.txt")}},
+			want: []string{"file2.txt", "file10.txt"},
+		},
+		{
+			name: "size then name",
+			opts: app.ListChildrenOptions{Sort: sortBy("size:desc,name:asc")},
+			want: []string{"file10.txt", "file1.txt", "File3.txt", "file2.txt"},
+		},
+		{
+			name: "created then name",
+			opts: app.ListChildrenOptions{Sort: sortBy("created:desc,name:asc")},
+			want: []string{"file2.txt", "File3.txt", "file1.txt", "file10.txt"},
+		},
+		{
+			name: "ties broken by id",
+			opts: app.ListChildrenOptions{Sort: &app.FileSystemSortParams{
+				Keys:       []pkg.SortKey{{Key: app.SortKeyDescription, Kind: pkg.SortKind_Asc}, {Key: app.SortKeyCreated, Kind: pkg.SortKind_Desc}},
+				IgnoreCase: true,
+			}},
+			want: append([]string{"file2.txt", "File3.txt"}, tied...),
+		},
+		{
+			name: "multi-key cursor",
+			opts: app.ListChildrenOptions{Sort: sortBy("size:desc,name:asc"), Page: app.PageParams{Cursor: fileId("file1.txt")}},
+			want: []string{"File3.txt", "file2.txt"},
+		},
+		{
+			name:    "duplicate key",
+			opts:    app.ListChildrenOptions{Sort: sortBy("name,name:desc")},
+			wantErr: app.ErrInvalidParams,
+		},
+		{
+			name:    "unknown key",
+			opts:    app.ListChildrenOptions{Sort: sortBy("owner")},
+			wantErr: app.ErrInvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		files, err := repos.FsRepo.ListChildFiles(ctx, etc, tt.opts)
+		require.ErrorIs(t, err, tt.wantErr, tt.name)
+		if tt.wantErr != nil {
+			continue
+		}
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = file.Name
+		}
+		require.Equal(t, tt.want, names, tt.name)
+	}
+
+	_, err = repos.FsRepo.ListChildFolders(ctx, &fs.Root, app.ListChildrenOptions{Sort: sortBy("size")})
+	require.ErrorIs(t, err, app.ErrInvalidParams, "a folder has no size to sort by")
+
+	folders, err := repos.FsRepo.ListChildFolders(ctx, &fs.Root, app.ListChildrenOptions{Sort: sortBy("description:desc")})
+	require.NoError(t, err)
+	require.Equal(t, []string{"home", "etc"}, []string{folders[0].Name, folders[1].Name})
+}
+
+func testUniqueNames(t *testing.T, repos Repositories) {
+	seed(t, repos)
+	ctx := as("user1")
+
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	home := childFolder(t, &fs.Root, "home")
+	dev := childFolder(t, home, "dev")
+
+	err = repos.FsRepo.CreateFolder(ctx, &app.Folder{Id: "01HYXCD1CGB36V08CNRGJQMZHT", ParentFolderId: home.Id, FsId: fs.Id, Name: "DEV", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrFolderExists)
+
+	err = repos.FsRepo.CreateFile(ctx, &app.File{Id: "01HYYMMTX8F4D2BESDCAD2YXS5", FolderId: dev.Id, FsId: fs.Id, Name: "Dev.Conf", Foldername: dev.Name, CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrFileExists)
+
+	// the same name is allowed in another folder
+	err = repos.FsRepo.CreateFile(ctx, &app.File{Id: "01HYYMMTX8F4D2BESDCAD2YXS5", FolderId: home.Id, FsId: fs.Id, Name: "Dev.Conf", Foldername: home.Name, CreatedTime: createdTime})
+	require.NoError(t, err)
+}
+
+func testUpdate(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	err := svc.RenameFolder(ctx, "user1", app.RenameFolderParams{OldFolderName: "/home/dev", NewFolderName: "qa"})
+	require.NoError(t, err)
+
+	// the files follow the name of their folder, though app hasn't loaded them
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	qa := childFolder(t, childFolder(t, &fs.Root, "home"), "qa")
+	require.Equal(t, "qa", qa.Files[0].Foldername)
+
+	err = svc.MoveFile(ctx, "user1", app.MoveFileParams{SrcFoldername: "/home/qa", Filename: "dev.conf", DstFoldername: "/etc", NewFilename: "qa.conf"})
+	require.NoError(t, err)
+
+	err = svc.RenameUser(ctx, app.RenameUserParams{Username: "user1", NewUsername: "User2"})
+	require.NoError(t, err)
+
+	fs, err = repos.FsRepo.LoadFileSystem(ctx, "user2", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.True(t, strings.EqualFold("User2", fs.Username), fs.Username)
+	require.Equal(t, []string{
+		"/",
+		"/etc",
+		"/etc/qa.conf",
+		"/home",
+		"/home/qa",
+		"/home/qa/go",
+		"/home/qa/go/go.mod",
+		"/readme",
+	}, FileSystemPaths(fs))
+
+	etc := childFolder(t, &fs.Root, "etc")
+	require.Equal(t, "etc", etc.Files[0].Foldername)
+
+	user, err := repos.UserRepo.QueryUserByName(ctx, "user2")
+	require.NoError(t, err)
+	require.Equal(t, "User2", user.Username)
+
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+}
+
+func testFileContent(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	for _, content := range []string{"port=8080", "port=9090"} {
+		err := svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/home/dev", Filename: "dev.conf", Content: []byte(content)})
+		require.NoError(t, err)
+	}
+
+	err := svc.CopyFile(ctx, "user1", app.CopyFileParams{SrcFoldername: "/home/dev", Filename: "dev.conf", DstFoldername: "/etc", CreatedTime: createdTime})
+	require.NoError(t, err)
+
+	data, err := svc.ReadFile(ctx, "user1", app.ReadFileParams{Foldername: "/etc", Filename: "dev.conf"})
+	require.NoError(t, err)
+	require.Equal(t, "port=9090", string(data))
+
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	etc := childFolder(t, &fs.Root, "etc")
+	require.Equal(t, int64(len("port=9090")), etc.Files[0].Size)
+
+	// the content is detached from the storage
+	content, err := repos.FsRepo.GetFileContent(ctx, etc.Files[0].Id)
+	require.NoError(t, err)
+	content.Data[0] = 'P'
+	data, err = svc.ReadFile(ctx, "user1", app.ReadFileParams{Foldername: "/etc", Filename: "dev.conf"})
+	require.NoError(t, err)
+	require.Equal(t, "port=9090", string(data))
+
+	data, err = svc.ReadFile(ctx, "user1", app.ReadFileParams{Foldername: "/home/dev/go", Filename: "go.mod"})
+	require.NoError(t, err)
+	require.NotNil(t, data)
+	require.Empty(t, data)
+}
+
+func testMetadata(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	updatedTime := createdTime.Add(24 * time.Hour)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	stat := func(path string) app.ViewStat {
+		stat, err := svc.Stat(ctx, "user1", app.StatParams{Path: path})
+		require.NoError(t, err, path)
+		return stat
+	}
+
+	// the content type comes from the extension, or from the content without a known extension
+	err := svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/home/dev/go", Filename: "data.json", CreatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/home/dev/go", Filename: "data.json", Content: []byte("{}"), UpdatedTime: updatedTime})
+	require.NoError(t, err)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/", Filename: "readme", Content: png, UpdatedTime: updatedTime})
+	require.NoError(t, err)
+
+	file := stat("/HOME/dev/go/data.json")
+	require.Equal(t, app.StatKind_File, file.Kind)
+	require.Equal(t, "/home/dev/go/data.json", file.Path)
+	require.Equal(t, "application/json", file.ContentType)
+	require.Equal(t, int64(2), file.Size)
+	require.True(t, file.CreatedTime.Equal(createdTime))
+	require.True(t, file.UpdatedTime.Equal(updatedTime))
+
+	file = stat("/readme")
+	require.Equal(t, "image/png", file.ContentType)
+	require.Equal(t, "file readme", file.Description)
+
+	// a copy keeps the content type, and a move updates the file
+	err = svc.CopyFile(ctx, "user1", app.CopyFileParams{SrcFoldername: "/", Filename: "readme", DstFoldername: "/etc", CreatedTime: createdTime})
+	require.NoError(t, err)
+	require.Equal(t, "image/png", stat("/etc/readme").ContentType)
+	err = svc.MoveFile(ctx, "user1", app.MoveFileParams{SrcFoldername: "/etc", Filename: "readme", DstFoldername: "/home", UpdatedTime: updatedTime})
+	require.NoError(t, err)
+	file = stat("/home/readme")
+	require.True(t, file.UpdatedTime.Equal(updatedTime))
+	require.Equal(t, "image/png", file.ContentType)
+
+	// renaming a folder updates it
+	folder := stat("/home/dev")
+	require.True(t, folder.UpdatedTime.Equal(folder.CreatedTime))
+	err = svc.RenameFolder(ctx, "user1", app.RenameFolderParams{OldFolderName: "/home/dev", NewFolderName: "Dev", UpdatedTime: updatedTime})
+	require.NoError(t, err)
+	folder = stat("/home/dev")
+	require.Equal(t, "/home/Dev", folder.Path)
+	require.True(t, folder.UpdatedTime.Equal(updatedTime))
+
+	// the size of a folder adds up its live subtree
+	home := stat("/home")
+	require.Equal(t, app.StatKind_Folder, home.Kind)
+	require.Equal(t, int64(2+len(png)), home.Size)
+	require.Equal(t, 2, home.Folders)
+	require.Equal(t, 4, home.Files)
+
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home/Dev/go", DeletedTime: updatedTime})
+	require.NoError(t, err)
+	home = stat("/home")
+	require.Equal(t, int64(len(png)), home.Size)
+	require.Equal(t, 1, home.Folders)
+	require.Equal(t, 2, home.Files)
+
+	root := stat("/")
+	require.Equal(t, "/", root.Path)
+	require.Equal(t, int64(2*len(png)), root.Size)
+	require.Equal(t, 3, root.Folders)
+	require.Equal(t, 3, root.Files)
+
+	_, err = svc.Stat(ctx, "user1", app.StatParams{Path: "/home/Dev/go"})
+	require.ErrorIs(t, err, app.ErrPathNotExists)
+	_, err = svc.Stat(ctx, "user1", app.StatParams{Path: "/nothing/readme"})
+	require.ErrorIs(t, err, app.ErrPathNotExists)
+}
+
+func testQuota(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	limit := func(n int) *int { return &n }
+	bytes := func(n int64) *int64 { return &n }
+
+	// a new user is unlimited
+	quota, err := svc.GetQuota(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, app.ViewQuota{Username: "user1", Folders: 4, Files: 3}, quota)
+
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "USER1", MaxFolders: limit(5), MaxFiles: limit(4), MaxBytes: bytes(10)})
+	require.NoError(t, err)
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user1", MaxFiles: limit(-1)})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user2", MaxFiles: limit(1)})
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	quota, err = svc.GetQuota(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, app.ViewQuota{Username: "user1", MaxFolders: 5, MaxFiles: 4, MaxBytes: 10, Folders: 4, Files: 3}, quota)
+
+	// folders
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/tmp", CreatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/var", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrQuotaExceeded)
+	require.EqualError(t, err, "Error: The user1 has exceeded the quota of 5 folders.")
+	_, err = svc.Stat(ctx, "user1", app.StatParams{Path: "/var"})
+	require.ErrorIs(t, err, app.ErrPathNotExists)
+
+	// the trash counts until it is emptied
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/tmp", DeletedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/var", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrQuotaExceeded)
+	_, err = svc.EmptyTrash(ctx, "user1", app.EmptyTrashParams{Now: createdTime})
+	require.NoError(t, err)
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/var", CreatedTime: createdTime})
+	require.NoError(t, err)
+
+	// files
+	err = svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/var", Filename: "a.log", CreatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/var", Filename: "b.log", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrQuotaExceeded)
+	err = svc.CopyFile(ctx, "user1", app.CopyFileParams{SrcFoldername: "/var", Filename: "a.log", DstFoldername: "/etc", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrQuotaExceeded)
+
+	// bytes, only the growth of a file is checked
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/var", Filename: "a.log", Content: []byte("12345678")})
+	require.NoError(t, err)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/", Filename: "readme", Content: []byte("123")})
+	require.ErrorIs(t, err, app.ErrQuotaExceeded)
+	require.EqualError(t, err, "Error: The user1 has exceeded the quota of 10 bytes.")
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/var", Filename: "a.log", Content: []byte("1234567890")})
+	require.NoError(t, err)
+
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user1", MaxBytes: bytes(1)})
+	require.NoError(t, err)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/var", Filename: "a.log", Content: []byte("12")})
+	require.NoError(t, err)
+
+	quota, err = svc.GetQuota(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, app.ViewQuota{Username: "user1", MaxFolders: 5, MaxFiles: 4, MaxBytes: 1, Folders: 5, Files: 4, Size: 2}, quota)
+}
+
+func testRole(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	limit := func(n int) *int { return &n }
+
+	// the first user is the admin, the next users aren't
+	register(t, svc, "user2")
+	info, err := svc.GetUserInfo(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, "admin", info.Role)
+	info, err = svc.GetUserInfo(as("user2"), "user2")
+	require.NoError(t, err)
+	require.Equal(t, "user", info.Role)
+
+	// a user can't lift its own limits
+	err = svc.SetQuota(as("user2"), app.SetQuotaParams{Username: "user2", MaxFiles: limit(100)})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	require.EqualError(t, err, "Error: The user2 doesn't have the permission to set the quota of user2.")
+	err = svc.SetQuota(context.Background(), app.SetQuotaParams{Username: "user2", MaxFiles: limit(100)})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.SetRole(as("user2"), app.SetRoleParams{Username: "user2", Role: "admin"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	_, err = svc.GetUserInfo(as("user2"), "user1")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+
+	// the admin manages the other users
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user2", MaxFiles: limit(1)})
+	require.NoError(t, err)
+	quota, err := svc.GetQuota(as("user2"), "user2")
+	require.NoError(t, err)
+	require.Equal(t, 1, quota.MaxFiles)
+	_, err = svc.GetUserInfo(ctx, "user2")
+	require.NoError(t, err)
+
+	// an admin is always kept
+	err = svc.SetRole(ctx, app.SetRoleParams{Username: "user1", Role: "user"})
+	require.ErrorIs(t, err, app.ErrLastAdmin)
+	err = svc.SetRole(ctx, app.SetRoleParams{Username: "user2", Role: "root"})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+	require.NoError(t, svc.SetRole(ctx, app.SetRoleParams{Username: "USER2", Role: "admin"}))
+	require.NoError(t, svc.SetRole(ctx, app.SetRoleParams{Username: "user1", Role: "user"}))
+
+	// the changed roles apply at once
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user1", MaxFiles: limit(1)})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = svc.SetQuota(as("user2"), app.SetQuotaParams{Username: "user1", MaxFiles: limit(1)})
+	require.NoError(t, err)
+
+	// a user registered while there is an admin isn't the admin
+	register(t, svc, "user3")
+	info, err = svc.GetUserInfo(as("user3"), "user3")
+	require.NoError(t, err)
+	require.Equal(t, "user", info.Role)
+}
+
+func testShare(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	share := func(ctx context.Context, foldername, grantee, permission string) error {
+		return svc.ShareFolder(ctx, app.ShareFolderParams{
+			Owner:       "user1",
+			Foldername:  foldername,
+			Grantee:     grantee,
+			Permission:  permission,
+			CreatedTime: createdTime,
+		})
+	}
+	for _, username := range []string{"user2", "user3"} {
+		register(t, svc, username)
+	}
+
+	// the owner acts, the others need a grant
+	_, err := svc.ListFolders(as("USER1"), "user1", app.ListFoldersParams{Foldername: "/home"})
+	require.NoError(t, err)
+	_, err = svc.ListFolders(as("user2"), "user1", app.ListFoldersParams{Foldername: "/home"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	require.EqualError(t, err, "Error: The user2 doesn't have the permission to read /home.")
+
+	// read, inherited by the subfolders
+	require.NoError(t, share(ctx, "/home/dev", "USER2", "read"))
+	files, err := svc.ListFiles(as("user2"), "user1", app.ListFilesParams{Foldername: "/home/dev"})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	_, err = svc.ReadFile(as("user2"), "user1", app.ReadFileParams{Foldername: "/home/dev/go", Filename: "go.mod"})
+	require.NoError(t, err)
+	_, err = svc.Stat(as("user2"), "user1", app.StatParams{Path: "/home/dev/go/go.mod"})
+	require.NoError(t, err)
+	err = svc.CreateFile(as("user2"), "user1", app.CreateFileParams{Foldername: "/home/dev", Filename: "main.go"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = share(as("user2"), "/home/dev/go", "user3", "read")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	require.ErrorIs(t, share(ctx, "/home/dev", "user2", "owner"), app.ErrInvalidParams)
+	require.ErrorIs(t, share(ctx, "/home/dev", "user1", "read"), app.ErrInvalidParams)
+	require.ErrorIs(t, share(ctx, "/home/dev", "user9", "read"), app.ErrUserNotExists)
+
+	// sharing again changes the permission to write
+	require.NoError(t, share(ctx, "/home/dev", "user2", "write"))
+	err = svc.CreateFile(as("user2"), "user1", app.CreateFileParams{Foldername: "/home/dev", Filename: "main.go", CreatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.WriteFile(as("user2"), "user1", app.WriteFileParams{Foldername: "/home/dev", Filename: "main.go", Content: []byte("package main")})
+	require.NoError(t, err)
+	err = svc.MoveFile(as("user2"), "user1", app.MoveFileParams{SrcFoldername: "/home/dev", Filename: "main.go", DstFoldername: "/home/dev/go"})
+	require.NoError(t, err)
+	err = svc.CopyFile(as("user2"), "user1", app.CopyFileParams{SrcFoldername: "/home/dev/go", Filename: "main.go", DstFoldername: "/etc"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = svc.DeleteFolder(as("user2"), "user1", app.DeleteFolderParams{Foldername: "/home/dev"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = svc.CreateFolder(as("user2"), "user1", app.CreateFolderParams{Foldername: "/home/dev/tmp", CreatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(as("user2"), "user1", app.DeleteFolderParams{Foldername: "/home/dev/tmp", DeletedTime: createdTime})
+	require.NoError(t, err)
+
+	// the trash belongs to the owner
+	_, err = svc.ListTrash(as("user2"), "user1")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	items, err := svc.ListTrash(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	// admin shares the subfolders
+	require.NoError(t, share(ctx, "/home/dev", "user2", "admin"))
+	require.NoError(t, share(as("user2"), "/home/dev/go", "user3", "read"))
+	_, err = svc.ListSharedWithMe(as("user2"), "user3")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+
+	// the path follows the renamed folder, and the grantee follows the renamed user
+	err = svc.RenameFolder(ctx, "user1", app.RenameFolderParams{OldFolderName: "/home/dev", NewFolderName: "work", UpdatedTime: createdTime})
+	require.NoError(t, err)
+	err = svc.RenameUser(as("user3"), app.RenameUserParams{Username: "user3", NewUsername: "user4"})
+	require.NoError(t, err)
+	shared, err := svc.ListSharedWithMe(as("user4"), "user4")
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	require.Equal(t, "user1", shared[0].Owner)
+	require.Equal(t, "/home/work/go", shared[0].Path)
+	require.Equal(t, "read", shared[0].Permission)
+	require.True(t, shared[0].SharedTime.Equal(createdTime))
+
+	// the grantee can leave by itself
+	unshare := app.UnshareFolderParams{Owner: "user1", Foldername: "/home/work/go", Grantee: "user4"}
+	require.NoError(t, svc.UnshareFolder(as("user4"), unshare))
+	require.ErrorIs(t, svc.UnshareFolder(ctx, unshare), app.ErrGrantNotExists)
+	_, err = svc.ListSharedWithMe(as("user4"), "user4")
+	require.ErrorIs(t, err, app.ErrListSharedEmpty)
+
+	// a trashed folder isn't shared until it is restored, and its grants go with the emptied trash
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home/work", DeletedTime: createdTime})
+	require.NoError(t, err)
+	_, err = svc.ListSharedWithMe(as("user2"), "user2")
+	require.ErrorIs(t, err, app.ErrListSharedEmpty)
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/home/work"})
+	require.NoError(t, err)
+	shared, err = svc.ListSharedWithMe(as("user2"), "user2")
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home/work", DeletedTime: createdTime})
+	require.NoError(t, err)
+	_, err = svc.EmptyTrash(ctx, "user1", app.EmptyTrashParams{Now: createdTime})
+	require.NoError(t, err)
+	grants, err := repos.FsRepo.ListGrants(ctx, fs.Id, "user2")
+	require.NoError(t, err)
+	require.Empty(t, grants)
+
+	// the grants of a deleted user go with it
+	require.NoError(t, share(ctx, "/etc", "user2", "read"))
+	require.NoError(t, svc.DeleteUser(as("user2"), "user2"))
+	grants, err = repos.FsRepo.ListGrants(ctx, fs.Id, "user2")
+	require.NoError(t, err)
+	require.Empty(t, grants)
+}
+
+func testAuth(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	anonymous := context.Background()
+	login := func(username, password string) (app.ViewSession, error) {
+		return svc.Login(anonymous, app.LoginParams{Username: username, Password: password, LoginTime: createdTime})
+	}
+	register(t, svc, "user2")
+
+	// the password is taken by the registration, so nobody else can claim the account
+	err := svc.Register(anonymous, app.RegisterParams{Username: "user3", Password: "short", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user3")
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	// nobody acts without login, neither on a file system nor on an account
+	_, err = svc.ListFolders(anonymous, "user1", app.ListFoldersParams{Foldername: "/"})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.CreateFolder(anonymous, "user1", app.CreateFolderParams{Foldername: "/tmp", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	_, err = svc.ListSharedWithMe(anonymous, "user1")
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.UnshareFolder(anonymous, app.UnshareFolderParams{Owner: "user1", Foldername: "/etc", Grantee: "user2"})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	_, err = svc.GetQuota(anonymous, "user1")
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.SetPassword(anonymous, app.SetPasswordParams{Username: "user1", Password: "other-password"})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.DeleteUser(anonymous, "user2")
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	_, err = svc.ListUsers(anonymous)
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	users, err := svc.ListUsers(as("user2"))
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	// a user registered before the passwords has no password, and gets one from an admin
+	register(t, svc, "user4")
+	err = repos.UserRepo.UpdatePassword(ctx, &app.User{Username: "user4"})
+	require.NoError(t, err)
+	_, err = login("user4", "user4-password")
+	require.ErrorIs(t, err, app.ErrPasswordNotSet)
+	err = svc.SetPassword(anonymous, app.SetPasswordParams{Username: "user4", Password: "user4-password"})
+	require.ErrorIs(t, err, app.ErrUnauthenticated)
+	err = svc.SetPassword(as("user2"), app.SetPasswordParams{Username: "user4", Password: "user4-password"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = svc.SetPassword(ctx, app.SetPasswordParams{Username: "user4", Password: "short"})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+	err = svc.SetPassword(ctx, app.SetPasswordParams{Username: "user4", Password: "user4-password"})
+	require.NoError(t, err)
+	_, err = login("user4", "user4-password")
+	require.NoError(t, err)
+
+	_, err = login("user1", "other-password")
+	require.ErrorIs(t, err, app.ErrIncorrectPassword)
+	_, err = login("user9", "user1-password")
+	require.ErrorIs(t, err, app.ErrIncorrectPassword)
+
+	session, err := login("USER1", "user1-password")
+	require.NoError(t, err)
+	require.Equal(t, "user1", session.Username)
+	require.True(t, session.ExpiredTime.Equal(createdTime.Add(app.SessionTTL)))
+
+	principal, err := svc.Authenticate(ctx, session.Token, createdTime)
+	require.NoError(t, err)
+	require.Equal(t, "user1", principal.Username)
+	_, err = svc.Authenticate(ctx, session.Token, createdTime.Add(app.SessionTTL))
+	require.ErrorIs(t, err, app.ErrSessionNotExists)
+	_, err = svc.Authenticate(ctx, "unknown", createdTime)
+	require.ErrorIs(t, err, app.ErrSessionNotExists)
+
+	// the principal manages its own account only
+	_, err = svc.ListFolders(as("user1"), "user1", app.ListFoldersParams{Foldername: "/"})
+	require.NoError(t, err)
+	err = svc.DeleteUser(as("user2"), "user1")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+	err = svc.SetPassword(as("user2"), app.SetPasswordParams{Username: "user1", Password: "other-password"})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+
+	// the session follows the renamed user
+	err = svc.RenameUser(as("user1"), app.RenameUserParams{Username: "user1", NewUsername: "user3"})
+	require.NoError(t, err)
+	principal, err = svc.Authenticate(ctx, session.Token, createdTime)
+	require.NoError(t, err)
+	require.Equal(t, "user3", principal.Username)
+
+	require.NoError(t, svc.Logout(ctx, session.Token))
+	_, err = svc.Authenticate(ctx, session.Token, createdTime)
+	require.ErrorIs(t, err, app.ErrSessionNotExists)
+	require.ErrorIs(t, svc.Logout(ctx, session.Token), app.ErrSessionNotExists)
+
+	// the sessions of a deleted user go with it, after another admin is kept
+	session, err = login("user3", "user1-password")
+	require.NoError(t, err)
+	require.NoError(t, svc.SetRole(as("user3"), app.SetRoleParams{Username: "user2", Role: "admin"}))
+	require.NoError(t, svc.DeleteUser(as("user3"), "user3"))
+	_, err = svc.Authenticate(ctx, session.Token, createdTime)
+	require.ErrorIs(t, err, app.ErrSessionNotExists)
+}
+
+func testAudit(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	// register, 4 folders and 3 files of the seed
+	entries, err := svc.ListAudit(ctx, "user1", app.ListAuditParams{})
+	require.NoError(t, err)
+	require.Len(t, entries, 8)
+	require.Equal(t, "register", entries[0].Action)
+	require.Equal(t, "user1", entries[0].After)
+	require.Equal(t, "", entries[0].Actor)
+	require.Equal(t, "create-folder", entries[2].Action)
+	require.Equal(t, "", entries[2].Before)
+	require.Equal(t, "/home/dev", entries[2].After)
+	require.True(t, entries[2].CreatedTime.Equal(createdTime.Add(2*time.Second)))
+	require.Equal(t, "create-file", entries[5].Action)
+	require.Equal(t, "/home/dev/go/go.mod", entries[5].After)
+	require.Equal(t, entries[3].TargetId, entries[5].ParentId)
+
+	// a failed use case doesn't write any entry
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/HOME", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrFolderExists)
+
+	later := createdTime.Add(time.Hour)
+	err = svc.RenameFolder(as("user1"), "user1", app.RenameFolderParams{OldFolderName: "/HOME/dev", NewFolderName: "work", UpdatedTime: later})
+	require.NoError(t, err)
+	err = svc.DeleteFile(as("user1"), "user1", app.DeleteFileParams{Foldername: "/home/work", Filename: "DEV.conf", DeletedTime: later.Add(time.Minute)})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(as("user1"), "user1", app.DeleteFolderParams{Foldername: "/home/work/go", DeletedTime: later.Add(2 * time.Minute)})
+	require.NoError(t, err)
+
+	entries, err = svc.ListAudit(ctx, "user1", app.ListAuditParams{Since: later})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "rename-folder", entries[0].Action)
+	require.Equal(t, "user1", entries[0].Actor)
+	require.Equal(t, "/home/dev", entries[0].Before)
+	require.Equal(t, "/home/work", entries[0].After)
+	require.Equal(t, "delete-file", entries[1].Action)
+	require.Equal(t, "/home/work/dev.conf", entries[1].Before)
+	require.Equal(t, "", entries[1].After)
+	require.Equal(t, "delete-folder", entries[2].Action)
+	require.Equal(t, "/home/work/go", entries[2].Before)
+
+	_, err = svc.ListAudit(ctx, "user1", app.ListAuditParams{Since: later.Add(time.Hour)})
+	require.ErrorIs(t, err, app.ErrListAuditEmpty)
+
+	register(t, svc, "user2")
+	_, err = svc.ListAudit(as("user2"), "user1", app.ListAuditParams{})
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+
+	// the files, the trash, the shares and the webhooks
+	later = createdTime.Add(2 * time.Hour)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/", Filename: "README", Content: []byte("vFS"), UpdatedTime: later})
+	require.NoError(t, err)
+	err = svc.MoveFile(ctx, "user1", app.MoveFileParams{SrcFoldername: "/", Filename: "readme", DstFoldername: "/ETC", NewFilename: "readme.md", UpdatedTime: later.Add(time.Minute)})
+	require.NoError(t, err)
+	err = svc.CopyFile(ctx, "user1", app.CopyFileParams{SrcFoldername: "/etc", Filename: "readme.md", DstFoldername: "/home/work", CreatedTime: later.Add(2 * time.Minute)})
+	require.NoError(t, err)
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/home/work/dev.conf", RestoredTime: later.Add(3 * time.Minute)})
+	require.NoError(t, err)
+	_, err = svc.EmptyTrash(ctx, "user1", app.EmptyTrashParams{Now: later.Add(4 * time.Minute)})
+	require.NoError(t, err)
+	err = svc.ShareFolder(ctx, app.ShareFolderParams{Owner: "user1", Foldername: "/ETC", Grantee: "user2", Permission: "read", CreatedTime: later.Add(5 * time.Minute)})
+	require.NoError(t, err)
+	err = svc.ShareFolder(ctx, app.ShareFolderParams{Owner: "user1", Foldername: "/etc", Grantee: "user2", Permission: "write", CreatedTime: later.Add(6 * time.Minute)})
+	require.NoError(t, err)
+	hook, err := svc.AddWebhook(ctx, "user1", app.AddWebhookParams{Url: "http://audit.example", CreatedTime: later.Add(7 * time.Minute)})
+	require.NoError(t, err)
+
+	entries, err = svc.ListAudit(ctx, "user1", app.ListAuditParams{Since: later})
+	require.NoError(t, err)
+	want := [][3]string{
+		{"write-file", "/readme", "/readme"},
+		{"move-file", "/readme", "/etc/readme.md"},
+		{"copy-file", "/etc/readme.md", "/home/work/readme.md"},
+		{"restore-trash", "", "/home/work/dev.conf"},
+		{"empty-trash", "/home/work/go", ""},
+		{"share-folder", "", "/etc user2:read"},
+		{"share-folder", "/etc user2:read", "/etc user2:write"},
+		{"add-webhook", "", "http://audit.example"},
+	}
+	require.Len(t, entries, len(want))
+	for i, entry := range entries {
+		require.Equal(t, want[i], [3]string{entry.Action, entry.Before, entry.After}, i)
+		require.Equal(t, "user1", entry.Actor)
+	}
+	require.Equal(t, hook.Id, entries[7].TargetId)
+
+	// the use cases which don't take a time are stamped by the clock
+	clock := pkg.NewMockTimeFunc("2024-05-29T00:00:00Z")
+	svc.UserService.(*app.UserUseCase).Time = &clock
+	svc.AuthService.(*app.AuthUseCase).Time = &clock
+	svc.ShareService.(*app.ShareUseCase).Time = &clock
+	svc.WebhookService.(*app.WebhookUseCase).Time = &clock
+	start := clock.Now()
+
+	err = svc.UnshareFolder(ctx, app.UnshareFolderParams{Owner: "user1", Foldername: "/etc", Grantee: "user2"})
+	require.NoError(t, err)
+	clock.Sleep(time.Minute)
+	err = svc.RemoveWebhook(ctx, "user1", app.RemoveWebhookParams{Id: hook.Id})
+	require.NoError(t, err)
+	clock.Sleep(time.Minute)
+	maxFolders := 5
+	err = svc.SetQuota(ctx, app.SetQuotaParams{Username: "user1", MaxFolders: &maxFolders})
+	require.NoError(t, err)
+	clock.Sleep(time.Minute)
+	err = svc.SetPassword(ctx, app.SetPasswordParams{Username: "user1", Password: "other-password"})
+	require.NoError(t, err)
+	clock.Sleep(time.Minute)
+	err = svc.RenameUser(ctx, app.RenameUserParams{Username: "user1", NewUsername: "User1"})
+	require.NoError(t, err)
+	clock.Sleep(time.Minute)
+	err = svc.SetRole(ctx, app.SetRoleParams{Username: "user2", Role: "admin"})
+	require.NoError(t, err)
+
+	entries, err = svc.ListAudit(ctx, "user1", app.ListAuditParams{Since: start})
+	require.NoError(t, err)
+	want = [][3]string{
+		{"unshare-folder", "/etc user2:write", ""},
+		{"remove-webhook", "http://audit.example", ""},
+		{"set-quota", "max-folders=0 max-files=0 max-bytes=0", "max-folders=5 max-files=0 max-bytes=0"},
+		{"set-password", "", ""},
+		{"rename-user", "user1", "User1"},
+	}
+	require.Len(t, entries, len(want))
+	for i, entry := range entries {
+		require.Equal(t, want[i], [3]string{entry.Action, entry.Before, entry.After}, i)
+		require.True(t, entry.CreatedTime.Equal(start.Add(time.Duration(i)*time.Minute)), entry.CreatedTime)
+	}
+	require.Equal(t, "User1", entries[4].Username)
+
+	// the role is recorded in the FileSystem of the user whose role is set
+	entries, err = svc.ListAudit(ctx, "user2", app.ListAuditParams{Since: start})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, [3]string{"set-role", "user", "admin"}, [3]string{entries[0].Action, entries[0].Before, entries[0].After})
+	require.Equal(t, "user1", entries[0].Actor)
+
+	// the entries stay after the user is deleted
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteUser(ctx, "user1"))
+	kept, err := repos.AuditRepo.ListAudit(ctx, fs.Id, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, kept, 25)
+	last := kept[len(kept)-1]
+	require.Equal(t, app.AuditAction_DeleteUser, last.Action)
+	require.Equal(t, "User1", last.Before)
+}
+
+func testEvents(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	// the events of the seed are dispatched without any handler
+	require.NotEmpty(t, listPending(t, repos))
+	require.NoError(t, svc.Events.Relay(ctx))
+	require.Len(t, listPending(t, repos), 0)
+
+	var all, folders []app.Event
+	svc.Events.Subscribe(func(ctx context.Context, event app.Event) error {
+		all = append(all, event)
+		return nil
+	})
+	svc.Events.Subscribe(func(ctx context.Context, event app.Event) error {
+		folders = append(folders, event)
+		return nil
+	}, app.EventName_FolderRenamed, app.EventName_FolderDeleted)
+
+	later := createdTime.Add(time.Hour)
+	err := svc.RenameFolder(ctx, "user1", app.RenameFolderParams{OldFolderName: "/HOME/dev", NewFolderName: "work", UpdatedTime: later})
+	require.NoError(t, err)
+	err = svc.WriteFile(ctx, "user1", app.WriteFileParams{Foldername: "/home/work", Filename: "DEV.conf", Content: []byte("debug"), UpdatedTime: later})
+	require.NoError(t, err)
+	err = svc.MoveFile(ctx, "user1", app.MoveFileParams{SrcFoldername: "/home/work", Filename: "dev.conf", DstFoldername: "/etc", UpdatedTime: later})
+	require.NoError(t, err)
+	err = svc.CopyFile(ctx, "user1", app.CopyFileParams{SrcFoldername: "/etc", Filename: "dev.conf", DstFoldername: "/", NewFilename: "dev.bak", CreatedTime: later})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home/work", DeletedTime: later})
+	require.NoError(t, err)
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/home/work", RestoredTime: later})
+	require.NoError(t, err)
+
+	// a failed use case doesn't raise any event
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/etc", CreatedTime: later})
+	require.ErrorIs(t, err, app.ErrFolderExists)
+
+	// a commit leaves its events in the outbox without calling the handlers
+	require.Len(t, all, 0)
+	require.Len(t, listPending(t, repos), 6)
+	require.NoError(t, svc.Events.Relay(ctx))
+
+	var got [][3]string
+	for _, event := range all {
+		got = append(got, [3]string{string(event.Name), event.OldPath, event.Path})
+	}
+	require.Equal(t, [][3]string{
+		{"folder.renamed", "/home/dev", "/home/work"},
+		{"file.written", "", "/home/work/dev.conf"},
+		{"file.moved", "/home/work/dev.conf", "/etc/dev.conf"},
+		{"file.copied", "/etc/dev.conf", "/dev.bak"},
+		{"folder.deleted", "", "/home/work"},
+		{"folder.restored", "", "/home/work"},
+	}, got)
+	require.Len(t, folders, 2)
+	require.Equal(t, app.EventName_FolderDeleted, folders[1].Name)
+	require.True(t, folders[1].OccurredTime.Equal(later))
+
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, fs.Id, all[0].FsId)
+
+	// a failed handler keeps the event in the outbox until its backoff has passed
+	clock := pkg.NewMockTimeFunc("2024-05-28T00:00:00Z")
+	svc.Events.Time = &clock
+	failed := errors.New("handler is down")
+	svc.Events.Subscribe(func(ctx context.Context, event app.Event) error {
+		return failed
+	}, app.EventName_FileCreated)
+
+	err = svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/etc", Filename: "hosts", CreatedTime: later})
+	require.NoError(t, err)
+	require.NoError(t, svc.Events.Relay(ctx))
+	pending := listPending(t, repos)
+	require.Len(t, pending, 1)
+	require.Equal(t, app.EventName_FileCreated, pending[0].Name)
+	require.Equal(t, "/etc/hosts", pending[0].Path)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.Equal(t, "handler is down", pending[0].LastError)
+	require.True(t, pending[0].NextAttemptTime.Equal(clock.Now().Add(app.EventRetryBackoff)), pending[0].NextAttemptTime)
+	due, err := repos.OutboxRepo.ListPendingOutbox(ctx, app.EventMaxAttempts, clock.Now())
+	require.NoError(t, err)
+	require.Len(t, due, 0)
+
+	// Relay skips the events which aren't due
+	failed = nil
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/tmp", CreatedTime: later})
+	require.NoError(t, err)
+	require.NoError(t, svc.Events.Relay(ctx))
+	require.Equal(t, "/tmp", all[len(all)-1].Path)
+	require.Len(t, listPending(t, repos), 1)
+
+	// Run relays the due events in the background
+	clock.Sleep(app.EventRetryBackoff)
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Events.Run(runCtx, time.Hour)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	isRelayed := func() bool {
+		return len(listPending(t, repos)) == 0
+	}
+	require.Eventually(t, isRelayed, 5*time.Second, 10*time.Millisecond)
+
+	// a commit wakes Run up, and doesn't wait for the handlers
+	release := make(chan struct{})
+	svc.Events.Subscribe(func(ctx context.Context, event app.Event) error {
+		<-release
+		return nil
+	}, app.EventName_FolderCreated)
+
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/var", CreatedTime: later})
+	require.NoError(t, err)
+	close(release)
+	require.Eventually(t, isRelayed, 5*time.Second, 10*time.Millisecond)
+}
+
+type webhookSenderFunc func(ctx context.Context, url string, header map[string]string, body []byte) (int, error)
+
+func (f webhookSenderFunc) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	return f(ctx, url, header, body)
+}
+
+func testWebhook(t *testing.T, repos Repositories) {
+	ctx := as("user1")
+
+	type post struct {
+		url    string
+		header map[string]string
+		body   []byte
+	}
+	var posts []post
+	// the status codes which a url responds in turn, then 200
+	statusCodes := map[string][]int{}
+	sender := webhookSenderFunc(func(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+		posts = append(posts, post{url: url, header: header, body: body})
+		codes := statusCodes[url]
+		if len(codes) == 0 {
+			return 200, nil
+		}
+		statusCodes[url] = codes[1:]
+		return codes[0], nil
+	})
+
+	svc := repos.serviceWithSender(sender)
+	clock := pkg.NewMockTimeFunc("2024-05-28T00:00:00Z")
+	svc.WebhookService.(*app.WebhookUseCase).Time = &clock
+	start := clock.Now()
+
+	register(t, svc, "user1")
+	_, err := svc.ListWebhooks(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrListWebhookEmpty)
+
+	_, err = svc.AddWebhook(ctx, "user1", app.AddWebhookParams{Url: "ftp://example.com", CreatedTime: createdTime})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+	_, err = app.ParseEventNames([]string{"folder.moved"})
+	require.ErrorIs(t, err, app.ErrInvalidParams)
+
+	events, err := app.ParseEventNames([]string{"FOLDER.created", "folder.renamed"})
+	require.NoError(t, err)
+	all, err := svc.AddWebhook(ctx, "user1", app.AddWebhookParams{Url: "http://all.example", CreatedTime: createdTime})
+	require.NoError(t, err)
+	require.Len(t, all.Secret, 64)
+	folders, err := svc.AddWebhook(ctx, "user1", app.AddWebhookParams{Url: "http://folders.example", Events: events, CreatedTime: createdTime.Add(time.Second)})
+	require.NoError(t, err)
+
+	hooks, err := svc.ListWebhooks(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	require.Equal(t, []string{}, hooks[0].Events)
+	require.Equal(t, []string{"folder.created", "folder.renamed"}, hooks[1].Events)
+	require.Equal(t, "", hooks[0].Secret)
+
+	// the commit posts nothing, Relay posts to both webhooks once, and the folder webhook fails
+	svc.Events.Time = &clock
+	statusCodes["http://folders.example"] = []int{500, 503}
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/home", CreatedTime: createdTime})
+	require.NoError(t, err)
+	require.Len(t, posts, 0)
+	require.NoError(t, svc.Events.Relay(ctx))
+	require.Len(t, posts, 2)
+	require.True(t, clock.Now().Equal(start))
+
+	var payload app.WebhookPayload
+	require.NoError(t, json.Unmarshal(posts[0].body, &payload))
+	require.Equal(t, "http://all.example", posts[0].url)
+	require.Equal(t, app.EventName_FolderCreated, payload.Event)
+	require.Equal(t, "/home", payload.Path)
+	require.Equal(t, "user1", payload.Username)
+	require.Equal(t, "folder.created", posts[0].header[app.WebhookEventHeader])
+	require.True(t, app.VerifyWebhook(all.Secret, posts[0].body, posts[0].header[app.WebhookSignatureHeader]))
+	require.False(t, app.VerifyWebhook(folders.Secret, posts[0].body, posts[0].header[app.WebhookSignatureHeader]))
+
+	// Relay retries the failed webhook only when the backoff of 1s and then 2s has passed
+	retry := func(wait time.Duration) []string {
+		posts = nil
+		clock.Sleep(wait)
+		require.NoError(t, svc.Events.Relay(ctx))
+		urls := []string{}
+		for _, post := range posts {
+			urls = append(urls, post.url)
+		}
+		return urls
+	}
+	require.Equal(t, []string{}, retry(0))
+	require.Equal(t, []string{"http://folders.example"}, retry(app.EventRetryBackoff))
+	require.Equal(t, []string{}, retry(app.EventRetryBackoff))
+	require.Equal(t, []string{"http://folders.example"}, retry(app.EventRetryBackoff))
+	require.Len(t, listPending(t, repos), 0)
+
+	// a webhook which is down keeps the event in the outbox, the next Relay skips it until it is due
+	statusCodes["http://all.example"] = []int{500}
+	posts = nil
+	err = svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/home", Filename: "a.txt", CreatedTime: createdTime})
+	require.NoError(t, err)
+	require.NoError(t, svc.Events.Relay(ctx))
+	require.Len(t, posts, 1)
+	require.Len(t, listPending(t, repos), 1)
+
+	posts = nil
+	err = svc.RenameFolder(as("user1"), "user1", app.RenameFolderParams{OldFolderName: "/home", NewFolderName: "work", UpdatedTime: createdTime})
+	require.NoError(t, err)
+	require.NoError(t, svc.Events.Relay(ctx))
+	var sent [][2]string
+	for _, post := range posts {
+		require.NoError(t, json.Unmarshal(post.body, &payload))
+		sent = append(sent, [2]string{post.url, string(payload.Event)})
+		require.Equal(t, "/home", payload.OldPath)
+		require.Equal(t, "user1", payload.Actor)
+	}
+	require.ElementsMatch(t, [][2]string{
+		{"http://all.example", "folder.renamed"},
+		{"http://folders.example", "folder.renamed"},
+	}, sent)
+
+	require.Equal(t, []string{"http://all.example"}, retry(app.EventRetryBackoff))
+	require.NoError(t, json.Unmarshal(posts[0].body, &payload))
+	require.Equal(t, app.EventName_FileCreated, payload.Event)
+	require.Len(t, listPending(t, repos), 0)
+
+	// every attempt is logged: folder.created and folder.renamed to both webhooks,
+	// folder.created failed twice at the folder webhook, file.created failed once
+	deliveries, err := svc.ListDeliveries(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, deliveries, 8)
+	var failed []app.ViewWebhookDelivery
+	for _, delivery := range deliveries {
+		if delivery.Error != "" {
+			failed = append(failed, delivery)
+		}
+	}
+	require.Len(t, failed, 3)
+	require.Equal(t, "folder.created", failed[0].Event)
+	require.Equal(t, 1, failed[0].Attempts)
+	require.Equal(t, "status code 500", failed[0].Error)
+	require.Equal(t, 2, failed[1].Attempts)
+	require.Equal(t, 503, failed[1].StatusCode)
+	require.Equal(t, "file.created", failed[2].Event)
+	require.Equal(t, 1, failed[2].Attempts)
+	for _, delivery := range deliveries {
+		if delivery.Event == "folder.created" && delivery.Url == "http://folders.example" && delivery.Error == "" {
+			require.Equal(t, 3, delivery.Attempts)
+		}
+	}
+
+	register(t, svc, "user2")
+	_, err = svc.ListWebhooks(as("user2"), "user1")
+	require.ErrorIs(t, err, app.ErrPermissionDenied)
+
+	err = svc.RemoveWebhook(ctx, "user1", app.RemoveWebhookParams{Id: "01HZ0000000000000000000000"})
+	require.ErrorIs(t, err, app.ErrWebhookNotExists)
+	require.NoError(t, svc.RemoveWebhook(ctx, "user1", app.RemoveWebhookParams{Id: folders.Id}))
+	hooks, err = svc.ListWebhooks(ctx, "user1")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+
+	// deleting the user deletes its webhooks and deliveries
+	fs, err := repos.FsRepo.FindFileSystem(ctx, "user1")
+	require.NoError(t, err)
+	require.NoError(t, svc.SetRole(ctx, app.SetRoleParams{Username: "user2", Role: "admin"}))
+	require.NoError(t, svc.DeleteUser(ctx, "user1"))
+	kept, err := repos.WebhookRepo.ListWebhooks(ctx, fs.Id)
+	require.NoError(t, err)
+	require.Len(t, kept, 0)
+	logged, err := repos.WebhookRepo.ListDeliveries(ctx, fs.Id)
+	require.NoError(t, err)
+	require.Len(t, logged, 0)
+}
+
+func testTrash(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+	// the databases round the times to milliseconds
+	now := time.Now().Truncate(time.Millisecond)
+
+	err := svc.DeleteFile(ctx, "user1", app.DeleteFileParams{Foldername: "/home/dev/go", Filename: "go.mod", DeletedTime: now})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home", DeletedTime: now})
+	require.NoError(t, err)
+
+	// the same name is available once the folder is in the trash
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/home", CreatedTime: now})
+	require.NoError(t, err)
+
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/", "/etc", "/home", "/readme"}, FileSystemPaths(fs))
+
+	items, err := repos.FsRepo.ListTrashItems(ctx, fs.Id)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/home"})
+	require.ErrorIs(t, err, app.ErrFolderExists)
+
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home", DeletedTime: now.Add(-time.Minute)})
+	require.NoError(t, err)
+
+	// the latest deleted one is restored
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/home"})
+	require.NoError(t, err)
+
+	fs, err = repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/", "/etc", "/home", "/home/dev", "/home/dev/dev.conf", "/home/dev/go", "/readme"}, FileSystemPaths(fs))
+
+	count, err := svc.EmptyTrash(ctx, "user1", app.EmptyTrashParams{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	items, err = repos.FsRepo.ListTrashItems(ctx, fs.Id)
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	// a file and a folder trashed before their parent folder are purged with it,
+	// only /home is old enough to be purged
+	err = svc.DeleteFile(ctx, "user1", app.DeleteFileParams{Foldername: "/home/dev", Filename: "dev.conf", DeletedTime: now})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home/dev/go", DeletedTime: now})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/home", DeletedTime: now.Add(-time.Hour)})
+	require.NoError(t, err)
+
+	count, err = svc.EmptyTrash(ctx, "user1", app.EmptyTrashParams{OlderThan: time.Minute, Now: now})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	items, err = repos.FsRepo.ListTrashItems(ctx, fs.Id)
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	usage, err := repos.FsRepo.SumFileSystem(ctx, fs)
+	require.NoError(t, err)
+	require.Equal(t, app.FolderUsage{Folders: 1, Files: 1}, usage)
+
+	// an item goes back to its parent folder found by id, so a renamed ancestor doesn't matter
+	err = svc.CreateFolder(ctx, "user1", app.CreateFolderParams{Foldername: "/etc/ssh", CreatedTime: now})
+	require.NoError(t, err)
+	err = svc.CreateFile(ctx, "user1", app.CreateFileParams{Foldername: "/etc/ssh", Filename: "config", CreatedTime: now})
+	require.NoError(t, err)
+	err = svc.DeleteFile(ctx, "user1", app.DeleteFileParams{Foldername: "/etc/ssh", Filename: "config", DeletedTime: now})
+	require.NoError(t, err)
+	err = svc.RenameFolder(ctx, "user1", app.RenameFolderParams{OldFolderName: "/etc", NewFolderName: "conf", UpdatedTime: now})
+	require.NoError(t, err)
+
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/etc/ssh/config", RestoredTime: now})
+	require.NoError(t, err)
+	fs, err = repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/", "/conf", "/conf/ssh", "/conf/ssh/config", "/readme"}, FileSystemPaths(fs))
+
+	// the parent folder in the trash isn't found
+	err = svc.DeleteFile(ctx, "user1", app.DeleteFileParams{Foldername: "/conf/ssh", Filename: "config", DeletedTime: now})
+	require.NoError(t, err)
+	err = svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/conf/ssh", DeletedTime: now})
+	require.NoError(t, err)
+	_, err = svc.RestoreTrash(ctx, "user1", app.RestoreTrashParams{Target: "/conf/ssh/config", RestoredTime: now})
+	require.ErrorIs(t, err, app.ErrFolderNotExists)
+
+	info, err := svc.GetUserInfo(ctx, "user1")
+	require.NoError(t, err)
+	require.Equal(t, 1, info.Folders)
+	require.Equal(t, 1, info.Files)
+}
+
+func testDeleteFileSystem(t *testing.T, repos Repositories) {
+	svc := seed(t, repos)
+	ctx := as("user1")
+
+	err := svc.DeleteFolder(ctx, "user1", app.DeleteFolderParams{Foldername: "/etc", DeletedTime: time.Now()})
+	require.NoError(t, err)
+	err = svc.DeleteUser(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrLastAdmin)
+	require.EqualError(t, err, "Error: The user1 is the last admin.")
+
+	register(t, svc, "user2")
+	require.NoError(t, svc.SetRole(ctx, app.SetRoleParams{Username: "user2", Role: "admin"}))
+	err = svc.DeleteUser(ctx, "user1")
+	require.NoError(t, err)
+
+	_, err = repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.ErrorIs(t, err, app.ErrUserNotExists)
+
+	// the name is available again
+	seed(t, repos)
+	fs, err := repos.FsRepo.LoadFileSystem(ctx, "user1", app.LoadFileSystemOptions{})
+	require.NoError(t, err)
+	require.Len(t, FileSystemPaths(fs), 8)
+}
+
+func testUnitOfWork(t *testing.T, repos Repositories) {
+	ctx := as("user1")
+
+	errRollback := errors.New("rollback")
+	err := repos.Uow.WithTx(ctx, func(ctx context.Context) error {
+		err := repos.UserRepo.CreateUser(ctx, &app.User{Username: "user1", CreatedTime: createdTime})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user1")
+	require.ErrorIs(t, err, app.ErrUserNotExists, "rollback")
+
+	err = repos.Uow.WithTx(ctx, func(ctx context.Context) error {
+		return repos.Uow.WithTx(ctx, func(ctx context.Context) error {
+			return repos.UserRepo.CreateUser(ctx, &app.User{Username: "user1", CreatedTime: createdTime})
+		})
+	})
+	require.NoError(t, err)
+
+	_, err = repos.UserRepo.QueryUserByName(ctx, "user1")
+	require.NoError(t, err, "commit")
+}
+
+func childFolder(t *testing.T, parent *app.Folder, name string) *app.Folder {
+	for _, folder := range parent.Folders {
+		if folder.Name == name {
+			return folder
+		}
+	}
+	t.Fatalf("The %v doesn't exist in %v", name, parent.Name)
+	return nil
+}
+
+// dumpFileSystem describes every field of the folders and files in fs,
+// so the trees loaded by different strategies can be compared regardless of the order.
+func dumpFileSystem(fs *app.FileSystem) []string {
+	lines := []string{fmt.Sprintf("fs %v %v", fs.Id, fs.Username)}
+	fs.Root.Walk(func(folder *app.Folder) {
+		lines = append(lines, fmt.Sprintf("folder %v parent=%v fs=%v name=%v description=%v created=%v trash=%v",
+			folder.Id, folder.ParentFolderId, folder.FsId, folder.Name, folder.Description, folder.CreatedTime.UTC(), folder.TrashId))
+		for _, file := range folder.Files {
+			lines = append(lines, fmt.Sprintf("file %v folder=%v fs=%v name=%v foldername=%v description=%v created=%v size=%v trash=%v",
+				file.Id, file.FolderId, file.FsId, file.Name, file.Foldername, file.Description, file.CreatedTime.UTC(), file.Size, file.TrashId))
+		}
+	})
+	sort.Strings(lines)
+	return lines
+}
+
+// FileSystemPaths lists the paths of the folders and files in fs,
+// so the trees loaded by different implementations can be compared regardless of the order.
+func FileSystemPaths(fs *app.FileSystem) []string {
+	var paths []string
+	var walk func(folder *app.Folder, path string)
+	walk = func(folder *app.Folder, path string) {
+		paths = append(paths, path)
+		prefix := path
+		if prefix != "/" {
+			prefix += "/"
+		}
+		for _, file := range folder.Files {
+			paths = append(paths, prefix+file.Name)
+		}
+		for _, child := range folder.Folders {
+			walk(child, prefix+child.Name)
+		}
+	}
+	walk(&fs.Root, "/")
+
+	sort.Strings(paths)
+	return paths
+}
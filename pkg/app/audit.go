@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+// AuditAction is the use case which an AuditEntry records.
+type AuditAction string
+
+const (
+	AuditAction_Register      AuditAction = "register"
+	AuditAction_DeleteUser    AuditAction = "delete-user"
+	AuditAction_RenameUser    AuditAction = "rename-user"
+	AuditAction_SetQuota      AuditAction = "set-quota"
+	AuditAction_SetRole       AuditAction = "set-role"
+	AuditAction_SetPassword   AuditAction = "set-password"
+	AuditAction_CreateFolder  AuditAction = "create-folder"
+	AuditAction_DeleteFolder  AuditAction = "delete-folder"
+	AuditAction_RenameFolder  AuditAction = "rename-folder"
+	AuditAction_CreateFile    AuditAction = "create-file"
+	AuditAction_DeleteFile    AuditAction = "delete-file"
+	AuditAction_WriteFile     AuditAction = "write-file"
+	AuditAction_MoveFile      AuditAction = "move-file"
+	AuditAction_CopyFile      AuditAction = "copy-file"
+	AuditAction_RestoreTrash  AuditAction = "restore-trash"
+	AuditAction_EmptyTrash    AuditAction = "empty-trash"
+	AuditAction_ShareFolder   AuditAction = "share-folder"
+	AuditAction_UnshareFolder AuditAction = "unshare-folder"
+	AuditAction_AddWebhook    AuditAction = "add-webhook"
+	AuditAction_RemoveWebhook AuditAction = "remove-webhook"
+)
+
+// newAuditEntry records the principal of ctx as the actor,
+// an empty actor means the use case ran without login.
+func newAuditEntry(ctx context.Context, fs *FileSystem, action AuditAction, targetId, parentId, before, after string, createdTime time.Time) *AuditEntry {
+	principal, _ := PrincipalFrom(ctx)
+	return &AuditEntry{
+		Id:          pkg.NewUlid(),
+		FsId:        fs.Id,
+		Username:    fs.Username,
+		Actor:       principal.Username,
+		Action:      action,
+		TargetId:    targetId,
+		ParentId:    parentId,
+		Before:      before,
+		After:       after,
+		CreatedTime: createdTime,
+	}
+}
+
+// auditGrant is how an AuditEntry records the permission of grantee on path.
+func auditGrant(path string, grantee string, permission Permission) string {
+	return fmt.Sprintf("%v %v:%v", path, grantee, permission)
+}
+
+// auditQuota is how an AuditEntry records the quota of a user.
+func auditQuota(quota Quota) string {
+	return fmt.Sprintf("max-folders=%v max-files=%v max-bytes=%v", quota.MaxFolders, quota.MaxFiles, quota.MaxBytes)
+}
+
+// AuditEntry records who changed what in a FileSystem.
+// The entries are only appended, they are never changed, and stay after the user is deleted.
+//
+// Before and After are the paths of the target, an empty one means the target didn't exist.
+// The other actions record:
+//   - the user actions: the username, the role, or the quota such as "max-folders=5 max-files=0 max-bytes=1024".
+//   - copy-file: the path of the source as Before.
+//   - empty-trash: one entry for each purged item.
+//   - share-folder and unshare-folder: the path, the grantee and the permission such as "/folder1 user2:read".
+//   - add-webhook and remove-webhook: the url.
+//
+// set-password records neither the password nor its hash.
+type AuditEntry struct {
+	Id          string      `gorm:"column:id;type:char(26);not null;primaryKey"`
+	FsId        string      `gorm:"column:fs_id;type:char(26);not null;index"`
+	Username    string      `gorm:"column:username;type:varchar(64);not null"`
+	Actor       string      `gorm:"column:actor;type:varchar(64);not null"`
+	Action      AuditAction `gorm:"column:action;type:varchar(32);not null"`
+	TargetId    string      `gorm:"column:target_id;type:char(26);not null"`
+	ParentId    string      `gorm:"column:parent_id;type:char(26);not null"`
+	Before      string      `gorm:"column:before_value;type:varchar(4096);not null"`
+	After       string      `gorm:"column:after_value;type:varchar(4096);not null"`
+	CreatedTime time.Time   `gorm:"column:created_time;not null;index"`
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"time"
+)
+
+// ListAuditParams filters the entries created at or after Since, a zero Since lists all.
+type ListAuditParams struct {
+	Since time.Time
+}
+
+func ToViewAuditEntry(entry *AuditEntry) ViewAuditEntry {
+	return ViewAuditEntry{
+		Id:          entry.Id,
+		Username:    entry.Username,
+		Actor:       entry.Actor,
+		Action:      string(entry.Action),
+		TargetId:    entry.TargetId,
+		ParentId:    entry.ParentId,
+		Before:      entry.Before,
+		After:       entry.After,
+		CreatedTime: entry.CreatedTime,
+	}
+}
+
+type ViewAuditEntry struct {
+	Id          string    `json:"id"`
+	Username    string    `json:"username"`
+	Actor       string    `json:"actor"`
+	Action      string    `json:"action"`
+	TargetId    string    `json:"target_id"`
+	ParentId    string    `json:"parent_id"`
+	Before      string    `json:"before"`
+	After       string    `json:"after"`
+	CreatedTime time.Time `json:"created_time"`
+}
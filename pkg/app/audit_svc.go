@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+type AuditService interface {
+	// ListAudit returns the entries of the FileSystem of the user, in the order they happened.
+	ListAudit(ctx context.Context, username string, params ListAuditParams) ([]ViewAuditEntry, error)
+}
+
+// AuditRepository is append-only, the entries are written in the transaction of the use case,
+// so an entry exists if and only if its change has been committed.
+type AuditRepository interface {
+	AppendAudit(ctx context.Context, entry *AuditEntry) error
+
+	// ListAudit returns the entries of the FileSystem created at or after since, ordered by time.
+	ListAudit(ctx context.Context, fsId string, since time.Time) ([]*AuditEntry, error)
+}
+
+func NewAuditUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *AuditUseCase {
+	return &AuditUseCase{
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
+	}
+}
+
+type AuditUseCase struct {
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
+}
+
+func (uc *AuditUseCase) ListAudit(ctx context.Context, username string, params ListAuditParams) ([]ViewAuditEntry, error) {
+	var response []ViewAuditEntry
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		entries, err := uc.AuditRepo.ListAudit(ctx, fs.Id, params.Since)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return ErrListAuditEmpty
+		}
+
+		response = make([]ViewAuditEntry, len(entries))
+		for i, entry := range entries {
+			response[i] = ToViewAuditEntry(entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
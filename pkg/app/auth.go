@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionTTL is how long a session lasts after login.
+const SessionTTL = 30 * 24 * time.Hour
+
+const (
+	minPasswordLength = 8
+
+	// maxPasswordLength is the limit of bcrypt.
+	maxPasswordLength = 72
+)
+
+func (user *User) HasPassword() bool {
+	return user.PasswordHash != ""
+}
+
+// SetPassword keeps the bcrypt hash of password, the password itself is never stored.
+func (user *User) SetPassword(password string) error {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return fmt.Errorf("Error: The password must have %v to %v bytes, it %w", minPasswordLength, maxPasswordLength, ErrInvalidParams)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hash)
+	return nil
+}
+
+func (user *User) VerifyPassword(password string) error {
+	if !user.HasPassword() {
+		return fmt.Errorf("Error: The %v %w", user.Username, ErrPasswordNotSet)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrIncorrectPassword
+	}
+	return err
+}
+
+// newSession returns the token for the client and the session to store,
+// only the sha256 of the token is stored, so a leaked database doesn't leak the sessions.
+func newSession(username string, createdTime time.Time) (string, *Session, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := hex.EncodeToString(secret)
+	session := &Session{
+		TokenHash:   hashToken(token),
+		Username:    username,
+		CreatedTime: createdTime,
+		ExpiredTime: createdTime.Add(SessionTTL),
+	}
+	return token, session, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Session is created by login, and identifies the user until logout or ExpiredTime.
+type Session struct {
+	TokenHash   string    `gorm:"column:token_hash;type:char(64);not null;primaryKey"`
+	Username    string    `gorm:"column:username;type:varchar(64);not null;index"`
+	CreatedTime time.Time `gorm:"column:created_time;not null"`
+	ExpiredTime time.Time `gorm:"column:expired_time;not null"`
+}
+
+func (session *Session) IsExpired(now time.Time) bool {
+	return !now.Before(session.ExpiredTime)
+}
+
+// Principal is the authenticated user who performs the use cases.
+type Principal struct {
+	Username string
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal sets the user who performs the use cases,
+// the adapters set it after the session has been authenticated.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok && principal.Username != ""
+}
+
+// requirePrincipal fails closed, a use case never acts for the owner implicitly
+// when nobody has logged in.
+func requirePrincipal(ctx context.Context) (Principal, error) {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok {
+		return Principal{}, fmt.Errorf("Error: The user %w", ErrUnauthenticated)
+	}
+	return principal, nil
+}
+
+// authorizeUser checks the principal of ctx may manage the account of user,
+// only the user itself or an admin may do it.
+// It fails closed, so a user registered before the passwords gets one from an admin.
+func authorizeUser(ctx context.Context, userRepo UserRepository, user *User) error {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(principal.Username, user.Username) {
+		return nil
+	}
+
+	return authorizeAdmin(ctx, userRepo, "manage "+user.Username)
+}
+
+// authorizeAdmin checks the principal of ctx is an admin, action tells what is denied.
+// The role is read from the repository, so a changed role applies to the sessions at once.
+func authorizeAdmin(ctx context.Context, userRepo UserRepository, action string) error {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	actor, err := userRepo.QueryUserByName(ctx, principal.Username)
+	if err != nil && !errors.Is(err, ErrUserNotExists) {
+		return err
+	}
+	if err != nil || !actor.IsAdmin() {
+		return fmt.Errorf("Error: The %v %w to %v.", principal.Username, ErrPermissionDenied, action)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+func TestUser_SetPassword(t *testing.T) {
+	user := &User{Username: "caesar"}
+	if user.HasPassword() {
+		t.Fatalf("HasPassword() got=true, want=false")
+	}
+
+	err := user.SetPassword("1234567")
+	if !errors.Is(err, ErrInvalidParams) {
+		t.Errorf("SetPassword() error=%v, want=%v", err, ErrInvalidParams)
+	}
+
+	err = user.SetPassword("caesar-password")
+	if err != nil {
+		t.Fatalf("SetPassword() error=%v", err)
+	}
+	if user.PasswordHash == "caesar-password" {
+		t.Errorf("SetPassword() stored the password itself")
+	}
+
+	err = user.VerifyPassword("caesar-password")
+	if err != nil {
+		t.Errorf("VerifyPassword() error=%v", err)
+	}
+	err = user.VerifyPassword("other-password")
+	if !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("VerifyPassword() error=%v, want=%v", err, ErrIncorrectPassword)
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	createdTime := pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now()
+
+	token, session, err := newSession("caesar", createdTime)
+	if err != nil {
+		t.Fatalf("newSession() error=%v", err)
+	}
+	if session.TokenHash != hashToken(token) || session.TokenHash == token {
+		t.Errorf("newSession() token_hash=%v, want the hash of the token", session.TokenHash)
+	}
+
+	other, _, _ := newSession("caesar", createdTime)
+	if other == token {
+		t.Errorf("newSession() returned the same token twice")
+	}
+
+	if session.IsExpired(createdTime.Add(SessionTTL - 1)) {
+		t.Errorf("IsExpired() got=true before ExpiredTime")
+	}
+	if !session.IsExpired(createdTime.Add(SessionTTL)) {
+		t.Errorf("IsExpired() got=false at ExpiredTime")
+	}
+}
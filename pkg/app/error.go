@@ -9,9 +9,19 @@ var (
 	ErrExists        = errors.New("has already existed.")
 	ErrNotExists     = errors.New("doesn't exist.")
 	ErrInvalidParams = errors.New("contain invalid chars.")
+	ErrQuotaExceeded = errors.New("has exceeded the quota")
+
+	ErrPermissionDenied = errors.New("doesn't have the permission")
+	ErrUnauthenticated  = errors.New("needs to login.")
+
+	ErrPasswordNotSet    = errors.New("doesn't have a password, please ask an admin to set-password.")
+	ErrIncorrectPassword = errors.New("Error: The username or password is incorrect.")
+	ErrSessionNotExists  = errors.New("doesn't exist or has expired, please login again.")
 
 	ErrUserExists    = fmt.Errorf("%w", ErrExists)
 	ErrUserNotExists = fmt.Errorf("%w", ErrNotExists)
+	ErrLastAdmin     = errors.New("is the last admin.")
+	ErrListUserEmpty = errors.New("Warning: There are no users.")
 
 	ErrFolderExists    = fmt.Errorf("%w", ErrExists)
 	ErrFolderNotExists = fmt.Errorf("%w", ErrNotExists)
@@ -20,4 +30,18 @@ var (
 	ErrFileExists    = fmt.Errorf("%w", ErrExists)
 	ErrFileNotExists = fmt.Errorf("%w", ErrNotExists)
 	ErrListFileEmpty = errors.New("Warning: The folder is empty.")
+
+	ErrPathNotExists = fmt.Errorf("%w", ErrNotExists)
+
+	ErrGrantNotExists  = fmt.Errorf("%w", ErrNotExists)
+	ErrListSharedEmpty = errors.New("Warning: There are no shared folders.")
+
+	ErrListAuditEmpty = errors.New("Warning: There are no audit entries.")
+
+	ErrWebhookNotExists  = fmt.Errorf("%w", ErrNotExists)
+	ErrListWebhookEmpty  = errors.New("Warning: There are no webhooks.")
+	ErrListDeliveryEmpty = errors.New("Warning: There are no webhook deliveries.")
+
+	ErrTrashItemNotExists = fmt.Errorf("%w", ErrNotExists)
+	ErrListTrashEmpty     = errors.New("Warning: The trash is empty.")
 )
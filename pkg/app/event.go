@@ -0,0 +1,80 @@
+package app
+
+import (
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+// EventName tells what happened to a folder or a file.
+type EventName string
+
+const (
+	EventName_FolderCreated  EventName = "folder.created"
+	EventName_FolderDeleted  EventName = "folder.deleted"
+	EventName_FolderRenamed  EventName = "folder.renamed"
+	EventName_FolderRestored EventName = "folder.restored"
+	EventName_FileCreated    EventName = "file.created"
+	EventName_FileDeleted    EventName = "file.deleted"
+	EventName_FileWritten    EventName = "file.written"
+	EventName_FileMoved      EventName = "file.moved"
+	EventName_FileCopied     EventName = "file.copied"
+	EventName_FileRestored   EventName = "file.restored"
+)
+
+// EventNames lists the events raised by Folder, in the order of the constants.
+var EventNames = []EventName{
+	EventName_FolderCreated,
+	EventName_FolderDeleted,
+	EventName_FolderRenamed,
+	EventName_FolderRestored,
+	EventName_FileCreated,
+	EventName_FileDeleted,
+	EventName_FileWritten,
+	EventName_FileMoved,
+	EventName_FileCopied,
+	EventName_FileRestored,
+}
+
+func newEvent(name EventName, fsId, targetId, parentId, path, oldPath string, occurredTime time.Time) Event {
+	return Event{
+		Id:           pkg.NewUlid(),
+		Name:         name,
+		FsId:         fsId,
+		TargetId:     targetId,
+		ParentId:     parentId,
+		Path:         path,
+		OldPath:      oldPath,
+		OccurredTime: occurredTime,
+	}
+}
+
+// Event is a domain event raised by a Folder method which has changed the tree.
+//
+// Path is where the target is after the change, or where it was before a delete.
+// OldPath is where the target was before a rename or a move, and where the source is for a copy.
+// Username and Actor are filled in by the use case which collects the event, like AuditEntry.
+type Event struct {
+	Id           string    `gorm:"column:id;type:char(26);not null;primaryKey"`
+	Name         EventName `gorm:"column:name;type:varchar(32);not null"`
+	FsId         string    `gorm:"column:fs_id;type:char(26);not null"`
+	Username     string    `gorm:"column:username;type:varchar(64);not null"`
+	Actor        string    `gorm:"column:actor;type:varchar(64);not null"`
+	TargetId     string    `gorm:"column:target_id;type:char(26);not null"`
+	ParentId     string    `gorm:"column:parent_id;type:char(26);not null"`
+	Path         string    `gorm:"column:path;type:varchar(4096);not null"`
+	OldPath      string    `gorm:"column:old_path;type:varchar(4096);not null"`
+	OccurredTime time.Time `gorm:"column:occurred_time;not null"`
+}
+
+// raise records event on dir, the use case collects it by PullEvents.
+func (dir *Folder) raise(event Event) {
+	dir.events = append(dir.events, event)
+}
+
+// PullEvents returns the events raised on dir, and forgets them.
+func (dir *Folder) PullEvents() []Event {
+	events := dir.events
+	dir.events = nil
+	return events
+}
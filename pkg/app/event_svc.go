@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+// EventMaxAttempts is how many times the outbox dispatches an event before giving it up,
+// the event given up is kept in the outbox with its last error.
+const EventMaxAttempts = 10
+
+// EventRetryBackoff is how long a failed event waits before its first retry,
+// the n-th retry waits EventRetryBackoff * 2^(n-1).
+const EventRetryBackoff = time.Second
+
+// OutboxRepository stores the events in the transaction which raised them,
+// so an event is dispatched if and only if its change is committed.
+type OutboxRepository interface {
+	AppendOutbox(ctx context.Context, events []Event) error
+
+	// ListPendingOutbox returns the events which are neither dispatched nor given up,
+	// and whose next attempt is due at now, in the order they occurred.
+	ListPendingOutbox(ctx context.Context, maxAttempts int, now time.Time) ([]*OutboxEvent, error)
+	UpdateOutbox(ctx context.Context, event *OutboxEvent) error
+}
+
+// OutboxEvent is an Event waiting in the outbox, an empty DispatchedTime means it is pending.
+// A failed event isn't dispatched again before NextAttemptTime.
+type OutboxEvent struct {
+	Event           `gorm:"embedded"`
+	Attempts        int        `gorm:"column:attempts;not null"`
+	LastError       string     `gorm:"column:last_error;type:varchar(1024);not null"`
+	DispatchedTime  *time.Time `gorm:"column:dispatched_time"`
+	NextAttemptTime *time.Time `gorm:"column:next_attempt_time"`
+}
+
+// fail keeps event in the outbox until the backoff of its attempts has passed.
+func (event *OutboxEvent) fail(err error, now time.Time) {
+	nextAttemptTime := now.Add(EventRetryBackoff << (event.Attempts - 1))
+	event.LastError = truncateError(err, 1024)
+	event.NextAttemptTime = &nextAttemptTime
+}
+
+// EventHandler reacts to an event after its change is committed.
+// An event is delivered at least once, a handler may see the same Event.Id again after a failure.
+type EventHandler func(ctx context.Context, event Event) error
+
+type subscription struct {
+	names   []EventName
+	handler EventHandler
+}
+
+func NewEventBus(outboxRepo OutboxRepository) *EventBus {
+	return &EventBus{
+		OutboxRepo: outboxRepo,
+		Logger:     slog.Default(),
+		Time:       pkg.NewTimeFunc(),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// EventBus dispatches the events of the outbox to the handlers subscribed in the same process.
+type EventBus struct {
+	OutboxRepo OutboxRepository
+
+	// Logger reports the relays which fail, since nobody waits for them.
+	Logger *slog.Logger
+
+	// Time decides which failed events are due, and stamps the dispatched events.
+	Time pkg.TimeFunc
+
+	mu            sync.RWMutex
+	subscriptions []subscription
+
+	// relayMu keeps the events of the process from being dispatched twice at the same time.
+	relayMu sync.Mutex
+
+	// running counts the calls of Run, wake tells them a commit has appended events.
+	running atomic.Int32
+	wake    chan struct{}
+}
+
+// Subscribe registers handler for names, no names means all events.
+func (bus *EventBus) Subscribe(handler EventHandler, names ...EventName) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscriptions = append(bus.subscriptions, subscription{names: names, handler: handler})
+}
+
+// Publish calls the handlers subscribed to the event in the order they subscribed,
+// and joins their errors.
+func (bus *EventBus) Publish(ctx context.Context, event Event) error {
+	bus.mu.RLock()
+	subscriptions := slices.Clone(bus.subscriptions)
+	bus.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subscriptions {
+		if len(sub.names) != 0 && !slices.Contains(sub.names, event.Name) {
+			continue
+		}
+		err := sub.handler(ctx, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Relay publishes the pending events of the outbox which are due.
+// An event is marked dispatched when all of its handlers succeed,
+// otherwise it stays pending and is published again by a Relay after its backoff, until EventMaxAttempts.
+func (bus *EventBus) Relay(ctx context.Context) error {
+	bus.relayMu.Lock()
+	defer bus.relayMu.Unlock()
+
+	events, err := bus.OutboxRepo.ListPendingOutbox(ctx, EventMaxAttempts, bus.Time.Now())
+	if err != nil {
+		return err
+	}
+	return bus.relay(ctx, events)
+}
+
+func (bus *EventBus) relay(ctx context.Context, events []*OutboxEvent) error {
+	for _, event := range events {
+		event.Attempts++
+		err := bus.Publish(ctx, event.Event)
+		if err != nil {
+			event.fail(err, bus.Time.Now())
+			bus.Logger.WarnContext(ctx, "The event is kept in the outbox.", "event_id", event.Id, "attempts", event.Attempts, "error", err)
+		} else {
+			dispatchedTime := bus.Time.Now()
+			event.LastError = ""
+			event.DispatchedTime = &dispatchedTime
+			event.NextAttemptTime = nil
+		}
+
+		err = bus.OutboxRepo.UpdateOutbox(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run relays the pending events every interval and after every commit, until ctx is done.
+func (bus *EventBus) Run(ctx context.Context, interval time.Duration) {
+	bus.running.Add(1)
+	defer bus.running.Add(-1)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := bus.Relay(ctx)
+		if err != nil && ctx.Err() == nil {
+			bus.Logger.ErrorContext(ctx, "Failed to relay the outbox.", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-bus.wake:
+		}
+	}
+}
+
+// notify wakes Run up after a commit has appended events, it does nothing while Run isn't running.
+func (bus *EventBus) notify() {
+	if bus.running.Load() == 0 {
+		return
+	}
+	select {
+	case bus.wake <- struct{}{}:
+	default:
+	}
+}
+
+func truncateError(err error, size int) string {
+	message := err.Error()
+	if len(message) > size {
+		return message[:size]
+	}
+	return message
+}
+
+type eventsKey struct{}
+
+type eventCollector struct {
+	events []Event
+}
+
+// collectEvents moves the events raised on the root folder of fs to the transaction carried by ctx,
+// and records the owner of fs and the principal of ctx on them.
+// They are dropped when ctx isn't inside EventUnitOfWork.WithTx.
+func collectEvents(ctx context.Context, fs *FileSystem) {
+	principal, _ := PrincipalFrom(ctx)
+	events := fs.Root.PullEvents()
+	for i := range events {
+		events[i].Username = fs.Username
+		events[i].Actor = principal.Username
+	}
+
+	collector, ok := ctx.Value(eventsKey{}).(*eventCollector)
+	if ok {
+		collector.events = append(collector.events, events...)
+	}
+}
+
+func NewEventUnitOfWork(uow UnitOfWork, bus *EventBus) *EventUnitOfWork {
+	return &EventUnitOfWork{
+		Uow: uow,
+		Bus: bus,
+	}
+}
+
+// EventUnitOfWork appends the events collected by a use case to the outbox before the commit,
+// and leaves them to EventBus.Run or EventBus.Relay, so a use case never waits for the handlers.
+type EventUnitOfWork struct {
+	Uow UnitOfWork
+	Bus *EventBus
+}
+
+// WithTx runs fn by Uow.
+// A nested call joins the events of the transaction which is already carried by ctx.
+func (uow *EventUnitOfWork) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, ok := ctx.Value(eventsKey{}).(*eventCollector)
+	if ok {
+		return uow.Uow.WithTx(ctx, fn)
+	}
+
+	collector := &eventCollector{}
+	err := uow.Uow.WithTx(context.WithValue(ctx, eventsKey{}, collector), func(ctx context.Context) error {
+		err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if len(collector.events) == 0 {
+			return nil
+		}
+		return uow.Bus.OutboxRepo.AppendOutbox(ctx, collector.events)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(collector.events) != 0 {
+		uow.Bus.notify()
+	}
+	return nil
+}
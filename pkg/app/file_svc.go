@@ -2,77 +2,383 @@ package app
 
 import (
 	"context"
+	"fmt"
 )
 
 type FileService interface {
 	CreateFile(ctx context.Context, username string, params CreateFileParams) error
 	DeleteFile(ctx context.Context, username string, params DeleteFileParams) error
 	ListFiles(ctx context.Context, username string, params ListFilesParams) ([]ViewFile, error)
+	WriteFile(ctx context.Context, username string, params WriteFileParams) error
+	ReadFile(ctx context.Context, username string, params ReadFileParams) ([]byte, error)
+	MoveFile(ctx context.Context, username string, params MoveFileParams) error
+	CopyFile(ctx context.Context, username string, params CopyFileParams) error
 }
 
-func NewFileUseCase(fsRepo FileSystemRepository) *FileUseCase {
+func NewFileUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *FileUseCase {
 	return &FileUseCase{
-		FsRepo: fsRepo,
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
 	}
 }
 
 type FileUseCase struct {
-	FsRepo FileSystemRepository
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
 }
 
 func (uc *FileUseCase) CreateFile(ctx context.Context, username string, params CreateFileParams) error {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
-	if err != nil {
-		return err
-	}
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
 
-	file, err := fs.Root.CreateFile(params)
-	if err != nil {
-		return err
-	}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.Foldername, params.Filename)
+		if err != nil {
+			return err
+		}
 
-	err = uc.FsRepo.CreateFile(ctx, file)
-	if err != nil {
-		return err
-	}
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		file, err := fs.Root.CreateFile(params)
+		if err != nil {
+			return err
+		}
+
+		err = checkQuota(ctx, uc.UserRepo, uc.FsRepo, fs, FolderUsage{Files: 1})
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.CreateFile(ctx, file)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		path := joinPath(fs.Root.storedPath(params.Foldername), file.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_CreateFile, file.Id, file.FolderId, "", path, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
 
 func (uc *FileUseCase) DeleteFile(ctx context.Context, username string, params DeleteFileParams) error {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
-	if err != nil {
-		return err
-	}
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
 
-	file, err := fs.Root.DeleteFile(params)
-	if err != nil {
-		return err
-	}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.Foldername, params.Filename)
+		if err != nil {
+			return err
+		}
 
-	err = uc.FsRepo.DeleteFile(ctx, file)
-	if err != nil {
-		return err
-	}
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Write)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		file, item, err := fs.Root.DeleteFile(params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.TrashFile(ctx, file, item)
+		if err != nil {
+			return err
+		}
+
+		path := joinPath(fs.Root.storedPath(params.Foldername), file.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_DeleteFile, file.Id, file.FolderId, path, "", params.DeletedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
 
 func (uc *FileUseCase) ListFiles(ctx context.Context, username string, params ListFilesParams) ([]ViewFile, error) {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
+	var response []ViewFile
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		err := params.Sort.Validate(true)
+		if err != nil {
+			return err
+		}
+		err = params.Page.Validate()
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		folder, err := loadFolder(ctx, uc.FsRepo, &fs.Root, params.Foldername)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Read)
+		if err != nil {
+			return err
+		}
+		if folder == nil {
+			return fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderNotExists)
+		}
+
+		files, err := uc.FsRepo.ListChildFiles(ctx, folder, ListChildrenOptions{
+			Sort:   params.Sort.Value(),
+			Filter: params.Filter,
+			Page:   params.Page,
+		})
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return ErrListFileEmpty
+		}
+
+		response = make([]ViewFile, len(files))
+		for i, file := range files {
+			response[i] = ToViewFile(file, username)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	files, err := fs.Root.ListFiles(params)
+	return response, nil
+}
+
+func (uc *FileUseCase) WriteFile(ctx context.Context, username string, params WriteFileParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.Foldername, params.Filename)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		// the size before writing, only the growth of the file is checked by the quota
+		file, err := fs.Root.ReadFile(ReadFileParams{Foldername: params.Foldername, Filename: params.Filename})
+		if err != nil {
+			return err
+		}
+		oldSize := file.Size
+
+		file, content, err := fs.Root.WriteFile(params)
+		if err != nil {
+			return err
+		}
+
+		err = checkQuota(ctx, uc.UserRepo, uc.FsRepo, fs, FolderUsage{Size: file.Size - oldSize})
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.SaveFileContent(ctx, content)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.UpdateFile(ctx, file)
+		if err != nil {
+			return err
+		}
+
+		path := joinPath(fs.Root.storedPath(params.Foldername), file.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_WriteFile, file.Id, file.FolderId, path, path, params.UpdatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
+}
+
+func (uc *FileUseCase) ReadFile(ctx context.Context, username string, params ReadFileParams) ([]byte, error) {
+	var data []byte
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.Foldername, params.Filename)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Read)
+		if err != nil {
+			return err
+		}
+
+		file, err := fs.Root.ReadFile(params)
+		if err != nil {
+			return err
+		}
+
+		content, err := uc.FsRepo.GetFileContent(ctx, file.Id)
+		if err != nil {
+			return err
+		}
+
+		data = content.Data
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]ViewFile, len(files))
-	for i, file := range files {
-		response[i] = ToViewFile(file, username)
-	}
+	return data, nil
+}
 
-	return response, nil
+func (uc *FileUseCase) MoveFile(ctx context.Context, username string, params MoveFileParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		newName := params.NewFilename
+		if newName == "" {
+			newName = params.Filename
+		}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.SrcFoldername, params.Filename)
+		if err != nil {
+			return err
+		}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.DstFoldername, newName)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.SrcFoldername, Permission_Write)
+		if err != nil {
+			return err
+		}
+		err = authorize(ctx, uc.FsRepo, fs, params.DstFoldername, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		// the path before moving
+		_, file, err := fs.Root.findFile(params.SrcFoldername, params.Filename)
+		if err != nil {
+			return err
+		}
+		before := joinPath(fs.Root.storedPath(params.SrcFoldername), file.Name)
+
+		file, err = fs.Root.MoveFile(params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.UpdateFile(ctx, file)
+		if err != nil {
+			return err
+		}
+
+		after := joinPath(fs.Root.storedPath(params.DstFoldername), file.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_MoveFile, file.Id, file.FolderId, before, after, params.UpdatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
+}
+
+func (uc *FileUseCase) CopyFile(ctx context.Context, username string, params CopyFileParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		newName := params.NewFilename
+		if newName == "" {
+			newName = params.Filename
+		}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.SrcFoldername, params.Filename)
+		if err != nil {
+			return err
+		}
+		err = loadChildFile(ctx, uc.FsRepo, &fs.Root, params.DstFoldername, newName)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.SrcFoldername, Permission_Read)
+		if err != nil {
+			return err
+		}
+		err = authorize(ctx, uc.FsRepo, fs, params.DstFoldername, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		src, dst, err := fs.Root.CopyFile(params)
+		if err != nil {
+			return err
+		}
+
+		err = checkQuota(ctx, uc.UserRepo, uc.FsRepo, fs, FolderUsage{Files: 1, Size: dst.Size})
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.CopyFile(ctx, src, dst)
+		if err != nil {
+			return err
+		}
+
+		before := joinPath(fs.Root.storedPath(params.SrcFoldername), src.Name)
+		after := joinPath(fs.Root.storedPath(params.DstFoldername), dst.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_CopyFile, dst.Id, dst.FolderId, before, after, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 type FolderService interface {
@@ -12,88 +13,215 @@ type FolderService interface {
 	RenameFolder(ctx context.Context, username string, params RenameFolderParams) error
 }
 
-func NewFolderUseCase(fsRepo FileSystemRepository) *FolderUseCase {
+func NewFolderUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *FolderUseCase {
 	return &FolderUseCase{
-		FsRepo: fsRepo,
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
 	}
 }
 
 type FolderUseCase struct {
-	FsRepo FileSystemRepository
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
 }
 
 func (uc *FolderUseCase) CreateFolder(ctx context.Context, username string, params CreateFolderParams) error {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
-	if err != nil {
-		return err
-	}
-
-	folder, err := fs.Root.CreateFolder(params)
-	if err != nil {
-		return err
-	}
-
-	err = uc.FsRepo.CreateFolder(ctx, folder)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		parentPath, name := splitFolderPath(params.Foldername)
+		err = loadChildFolder(ctx, uc.FsRepo, &fs.Root, parentPath, name)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, parentPath, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		folder, err := fs.Root.CreateFolder(params)
+		if err != nil {
+			return err
+		}
+
+		err = checkQuota(ctx, uc.UserRepo, uc.FsRepo, fs, FolderUsage{Folders: 1})
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.CreateFolder(ctx, folder)
+		if err != nil {
+			return err
+		}
+
+		path := joinPath(fs.Root.storedPath(parentPath), folder.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_CreateFolder, folder.Id, folder.ParentFolderId, "", path, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
 
 func (uc *FolderUseCase) DeleteFolder(ctx context.Context, username string, params DeleteFolderParams) error {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
-	if err != nil {
-		return err
-	}
-
-	folder, err := fs.Root.DeleteFolder(params)
-	if err != nil {
-		return err
-	}
-
-	err = uc.FsRepo.DeleteFolder(ctx, folder)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		parentPath, name := splitFolderPath(params.Foldername)
+		err = loadChildFolder(ctx, uc.FsRepo, &fs.Root, parentPath, name)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, parentPath, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		folder, item, err := fs.Root.DeleteFolder(params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.TrashFolder(ctx, folder, item)
+		if err != nil {
+			return err
+		}
+
+		path := joinPath(fs.Root.storedPath(parentPath), folder.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_DeleteFolder, folder.Id, folder.ParentFolderId, path, "", params.DeletedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
 
 func (uc *FolderUseCase) ListFolders(ctx context.Context, username string, params ListFoldersParams) ([]ViewFolder, error) {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
+	var response []ViewFolder
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		err := params.Sort.Validate(false)
+		if err != nil {
+			return err
+		}
+		err = params.Page.Validate()
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		folder, err := loadFolder(ctx, uc.FsRepo, &fs.Root, params.Foldername)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Foldername, Permission_Read)
+		if err != nil {
+			return err
+		}
+		if folder == nil {
+			return fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderNotExists)
+		}
+
+		folders, err := uc.FsRepo.ListChildFolders(ctx, folder, ListChildrenOptions{
+			Sort:   params.Sort.Value(),
+			Filter: params.Filter,
+			Page:   params.Page,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(folders) == 0 {
+			subject := username
+			if !isRootPath(params.Foldername) {
+				subject = params.Foldername
+			}
+			return fmt.Errorf("Warning: The %v %w", subject, ErrListFolderEmpty)
+		}
+
+		response = make([]ViewFolder, len(folders))
+		for i, folder := range folders {
+			response[i] = ToViewFolder(folder, username)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(fs.Root.Folders) == 0 {
-		return nil, fmt.Errorf("Warning: The %v %w", username, ErrListFolderEmpty)
-	}
-
-	folders := fs.Root.ListFolders(params)
-	response := make([]ViewFolder, len(folders))
-	for i, folder := range folders {
-		response[i] = ToViewFolder(folder, username)
-	}
-
 	return response, nil
 }
 
 func (uc *FolderUseCase) RenameFolder(ctx context.Context, username string, params RenameFolderParams) error {
-	fs, err := uc.FsRepo.GetFileSystemByUsernameV3(ctx, username)
-	if err != nil {
-		return err
-	}
-
-	folder, err := fs.Root.RenameFolder(params)
-	if err != nil {
-		return err
-	}
-
-	err = uc.FsRepo.UpdateFolder(ctx, folder)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		parentPath, oldName := splitFolderPath(params.OldFolderName)
+		for _, name := range []string{oldName, strings.Trim(params.NewFolderName, pathSeparator)} {
+			err = loadChildFolder(ctx, uc.FsRepo, &fs.Root, parentPath, name)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, parentPath, Permission_Write)
+		if err != nil {
+			return err
+		}
+
+		// the path before renaming, RenameFolder reports the folder which doesn't exist
+		var before string
+		_, err = fs.Root.findFolder(params.OldFolderName)
+		if err == nil {
+			before = fs.Root.storedPath(params.OldFolderName)
+		}
+
+		folder, err := fs.Root.RenameFolder(params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.UpdateFolder(ctx, folder)
+		if err != nil {
+			return err
+		}
+
+		after := joinPath(fs.Root.storedPath(parentPath), folder.Name)
+		entry := newAuditEntry(ctx, fs, AuditAction_RenameFolder, folder.Id, folder.ParentFolderId, before, after, params.UpdatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		return nil
+	})
 }
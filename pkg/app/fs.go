@@ -1,9 +1,9 @@
 package app
 
 import (
-	"errors"
+	"cmp"
 	"fmt"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -26,28 +26,39 @@ type FileSystem struct {
 	Root     Folder `gorm:"foreignKey:fs_id"`
 }
 
+// Count returns the number of folders and files, the root folder isn't included.
+func (fs *FileSystem) Count() (folders int, files int) {
+	fs.Root.Walk(func(folder *Folder) {
+		folders++
+		files += len(folder.Files)
+	})
+	return folders - 1, files
+}
+
 func newRootFolder(fsId string, createdTime time.Time) Folder {
 	return Folder{
 		Id:          pkg.NewUlid(),
 		FsId:        fsId,
 		Name:        "/",
 		CreatedTime: createdTime,
+		UpdatedTime: createdTime,
 	}
 }
 
-func newFolder(parentId, fsId string, params CreateFolderParams) (*Folder, error) {
-	err := validateFoldername(params.Foldername)
+func newFolder(parent *Folder, name string, params CreateFolderParams) (*Folder, error) {
+	err := validateFoldername(name)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Folder{
 		Id:             pkg.NewUlid(),
-		ParentFolderId: parentId,
-		FsId:           fsId,
-		Name:           params.Foldername,
+		ParentFolderId: parent.Id,
+		FsId:           parent.FsId,
+		Name:           name,
 		Description:    params.Description,
 		CreatedTime:    params.CreatedTime,
+		UpdatedTime:    params.CreatedTime,
 	}, nil
 }
 
@@ -58,112 +69,198 @@ type Folder struct {
 	Name           string    `gorm:"column:name;type:varchar(256);not null"`
 	Description    string    `gorm:"column:description;type:varchar(1024);not null"`
 	CreatedTime    time.Time `gorm:"column:created_time;not null"`
+	UpdatedTime    time.Time `gorm:"column:updated_time;not null"`
+	TrashId        string    `gorm:"column:trash_id;type:char(26);not null;default:'';index"`
 	Files          []*File   `gorm:"foreignKey:folder_id"`
 	Folders        []*Folder `gorm:"foreignKey:parent_id"`
 
 	ByUpdate pkg.MapData `gorm:"-"`
+
+	// events are raised by the methods called on this folder, see PullEvents.
+	events []Event
 }
 
 func (dir *Folder) CreateFolder(params CreateFolderParams) (*Folder, error) {
-	_, err := dir.findFolder(params.Foldername)
-	if err == nil {
+	err := validateFolderPath(params.Foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	parentPath, name := splitFolderPath(params.Foldername)
+	if name == "" {
 		return nil, fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderExists)
 	}
 
-	if !errors.Is(err, ErrFolderNotExists) {
+	parent, err := dir.findFolder(parentPath)
+	if err != nil {
 		return nil, err
 	}
 
-	folder, err := newFolder(dir.Id, dir.FsId, params)
+	_, ok := parent.findChildFolder(name)
+	if ok {
+		return nil, fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderExists)
+	}
+
+	folder, err := newFolder(parent, name, params)
 	if err != nil {
 		return nil, err
 	}
 
-	dir.Folders = append(dir.Folders, folder)
+	parent.Folders = append(parent.Folders, folder)
+	path := joinPath(dir.storedPath(parentPath), folder.Name)
+	dir.raise(newEvent(EventName_FolderCreated, folder.FsId, folder.Id, parent.Id, path, "", params.CreatedTime))
 	return folder, nil
 }
 
-func (dir *Folder) DeleteFolder(params DeleteFolderParams) (*Folder, error) {
-	targetFolder, err := dir.findFolder(params.Foldername)
+// DeleteFolder detaches the folder from its parent,
+// and returns the TrashItem which keeps the folder and its subtree restorable.
+func (dir *Folder) DeleteFolder(params DeleteFolderParams) (*Folder, *TrashItem, error) {
+	parentPath, name := splitFolderPath(params.Foldername)
+	if name == "" {
+		return nil, nil, fmt.Errorf("Error: The %v %w", params.Foldername, ErrInvalidParams)
+	}
+
+	parent, err := dir.findFolder(parentPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderNotExists)
+	}
+
+	for i, folder := range parent.Folders {
+		if strings.EqualFold(folder.Name, name) {
+			parent.Folders = append(parent.Folders[:i], parent.Folders[i+1:]...)
+			path := joinPath(dir.storedPath(parentPath), folder.Name)
+			item := newTrashItem(
+				TrashKind_Folder,
+				folder.Id,
+				parent.Id,
+				folder.FsId,
+				joinPath(parentPath, folder.Name),
+				params.DeletedTime,
+			)
+			dir.raise(newEvent(EventName_FolderDeleted, folder.FsId, folder.Id, parent.Id, path, "", params.DeletedTime))
+			return folder, item, nil
+		}
 	}
+	return nil, nil, fmt.Errorf("Error: The %v %w", params.Foldername, ErrFolderNotExists)
+}
 
-	for i, folder := range dir.Folders {
-		if folder == targetFolder {
-			dir.Folders = append(dir.Folders[:i], dir.Folders[i+1:]...)
-			return folder, nil
+// findFolder resolves a slash separated path relative to dir,
+// one segment at a time through Folder.Folders.
+// An empty path or "/" resolves to dir itself.
+func (dir *Folder) findFolder(path string) (*Folder, error) {
+	folder := dir
+	for _, segment := range splitFolderSegments(path) {
+		child, ok := folder.findChildFolder(segment)
+		if !ok {
+			return nil, fmt.Errorf("Error: The %v %w", path, ErrFolderNotExists)
 		}
+		folder = child
 	}
-	return nil, nil
+	return folder, nil
 }
 
-func (dir *Folder) findFolder(foldername string) (*Folder, error) {
-	if dir.Name == foldername {
-		return dir, nil
+// storedPath spells the folders on path in the case they are stored, path must exist.
+func (dir *Folder) storedPath(path string) string {
+	stored := pathSeparator
+	folder := dir
+	for _, segment := range splitFolderSegments(path) {
+		folder, _ = folder.findChildFolder(segment)
+		stored = joinPath(stored, folder.Name)
 	}
+	return stored
+}
+
+func (dir *Folder) findChildFolder(name string) (*Folder, bool) {
 	for _, folder := range dir.Folders {
-		if strings.EqualFold(folder.Name, foldername) {
-			return folder, nil
+		if strings.EqualFold(folder.Name, name) {
+			return folder, true
 		}
 	}
-	return nil, fmt.Errorf("Error: The %v %w", foldername, ErrFolderNotExists)
-}
-
-func (dir *Folder) ListFolders(params ListFoldersParams) []*Folder {
-	pkg.SortTraversalParams(params.Sort.Value(), func(key string, value pkg.SortKind) {
-		sort.Slice(dir.Folders, func(i, j int) bool {
-			switch key {
-			case "name":
-				if value == pkg.SortKind_Asc {
-					return dir.Folders[i].Name < dir.Folders[j].Name
-				}
-				if value == pkg.SortKind_Desc {
-					return dir.Folders[i].Name > dir.Folders[j].Name
-				}
-
-			case "created":
-				if value == pkg.SortKind_Asc {
-					return dir.Folders[i].CreatedTime.Second() < dir.Folders[j].CreatedTime.Second()
-				}
-				if value == pkg.SortKind_Desc {
-					return dir.Folders[i].CreatedTime.Second() > dir.Folders[j].CreatedTime.Second()
-				}
-			}
+	return nil, false
+}
 
-			return false
-		})
+// Walk visits dir and all of its descendant folders in pre-order.
+func (dir *Folder) Walk(fn func(folder *Folder)) {
+	fn(dir)
+	for _, folder := range dir.Folders {
+		folder.Walk(fn)
+	}
+}
+
+// ListFolders returns a page of the loaded child folders which match opts,
+// it is the reference of how FileSystemRepository.ListChildFolders applies opts.
+func (dir *Folder) ListFolders(opts ListChildrenOptions) ([]*Folder, error) {
+	return listChildren(dir.Folders, opts, false, func(folder *Folder) childColumns {
+		return childColumns{
+			id:          folder.Id,
+			name:        folder.Name,
+			createdTime: folder.CreatedTime,
+			description: folder.Description,
+		}
 	})
+}
+
+// Stat finds the folder or the file on path,
+// the folder is chosen when a folder and a file have the same name.
+func (dir *Folder) Stat(path string) (*Folder, *File, error) {
+	parentPath, name := splitFolderPath(path)
+	if name == "" {
+		return dir, nil, nil
+	}
 
-	return dir.Folders
+	parent, err := dir.findFolder(parentPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error: The %v %w", path, ErrPathNotExists)
+	}
+	folder, ok := parent.findChildFolder(name)
+	if ok {
+		return folder, nil, nil
+	}
+	file, ok := parent.findChildFile(name)
+	if ok {
+		return nil, file, nil
+	}
+	return nil, nil, fmt.Errorf("Error: The %v %w", path, ErrPathNotExists)
 }
 
 func (dir *Folder) RenameFolder(params RenameFolderParams) (*Folder, error) {
-	err := validateFoldername(params.NewFolderName)
+	newName := strings.Trim(params.NewFolderName, pathSeparator)
+	err := validateFoldername(newName)
 	if err != nil {
-		return nil, fmt.Errorf("Error: The %v %w", params.NewFolderName, err)
+		return nil, err
 	}
 
-	_, err = dir.findFolder(params.NewFolderName)
-	if err == nil {
-		return nil, fmt.Errorf("Error: The %v %w", params.NewFolderName, ErrFolderExists)
+	parentPath, oldName := splitFolderPath(params.OldFolderName)
+	if oldName == "" {
+		return nil, fmt.Errorf("Error: The %v %w", params.OldFolderName, ErrInvalidParams)
 	}
 
-	if !errors.Is(err, ErrFolderNotExists) {
+	folder, err := dir.findFolder(params.OldFolderName)
+	if err != nil {
 		return nil, err
 	}
 
-	folder, err := dir.findFolder(params.OldFolderName)
+	parent, err := dir.findFolder(parentPath)
 	if err != nil {
 		return nil, err
 	}
 
-	folder.Name = params.NewFolderName
+	other, ok := parent.findChildFolder(newName)
+	if ok && other != folder {
+		return nil, fmt.Errorf("Error: The %v %w", params.NewFolderName, ErrFolderExists)
+	}
+
+	oldPath := dir.storedPath(params.OldFolderName)
+	folder.Name = newName
+	folder.UpdatedTime = params.UpdatedTime
 	folder.ByUpdate.MustOk().Set("name", folder.Name)
+	folder.ByUpdate.MustOk().Set("updated_time", folder.UpdatedTime)
 	for _, file := range folder.Files {
-		file.Foldername = params.NewFolderName
+		file.Foldername = folder.Name
 		file.ByUpdate.MustOk().Set("foldername", file.Foldername)
 	}
+	path := joinPath(dir.storedPath(parentPath), folder.Name)
+	dir.raise(newEvent(EventName_FolderRenamed, folder.FsId, folder.Id, parent.Id, path, oldPath, params.UpdatedTime))
 	return folder, nil
 }
 
@@ -173,75 +270,303 @@ func (dir *Folder) CreateFile(params CreateFileParams) (*File, error) {
 		return nil, err
 	}
 
-	for _, file := range folder.Files {
-		if file.Name == params.Filename {
-			return nil, fmt.Errorf("Error: The %v %w", params.Filename, ErrFileExists)
-		}
+	_, ok := folder.findChildFile(params.Filename)
+	if ok {
+		return nil, fmt.Errorf("Error: The %v %w", params.Filename, ErrFileExists)
 	}
 
-	file, err := newFile(folder.Id, folder.FsId, params)
+	file, err := newFile(folder, params)
 	if err != nil {
 		return nil, err
 	}
 
 	folder.Files = append(folder.Files, file)
+	path := joinPath(dir.storedPath(params.Foldername), file.Name)
+	dir.raise(newEvent(EventName_FileCreated, file.FsId, file.Id, folder.Id, path, "", params.CreatedTime))
 	return file, nil
 }
 
-func (dir *Folder) DeleteFile(params DeleteFileParams) (*File, error) {
+// DeleteFile detaches the file from its folder,
+// and returns the TrashItem which keeps the file restorable.
+func (dir *Folder) DeleteFile(params DeleteFileParams) (*File, *TrashItem, error) {
 	folder, err := dir.findFolder(params.Foldername)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for i, file := range folder.Files {
-		if file.Name == params.Filename {
+		if strings.EqualFold(file.Name, params.Filename) {
 			folder.Files = append(folder.Files[:i], folder.Files[i+1:]...)
-			return file, nil
+			item := newTrashItem(
+				TrashKind_File,
+				file.Id,
+				folder.Id,
+				file.FsId,
+				joinPath(cleanPath(params.Foldername), file.Name),
+				params.DeletedTime,
+			)
+			path := joinPath(dir.storedPath(params.Foldername), file.Name)
+			dir.raise(newEvent(EventName_FileDeleted, file.FsId, file.Id, folder.Id, path, "", params.DeletedTime))
+			return file, item, nil
 		}
 	}
 
-	return nil, fmt.Errorf("Error: The %v %w", params.Filename, ErrFileNotExists)
+	return nil, nil, fmt.Errorf("Error: The %v %w", params.Filename, ErrFileNotExists)
 }
 
-func (dir *Folder) ListFiles(params ListFilesParams) ([]*File, error) {
-	folder, err := dir.findFolder(params.Foldername)
+func (dir *Folder) WriteFile(params WriteFileParams) (*File, *FileContent, error) {
+	folder, file, err := dir.findFile(params.Foldername, params.Filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := newFileContent(file, params.Content)
+	file.Size = content.Size()
+	file.ContentType = pkg.DetectContentType(file.Name, content.Data)
+	file.UpdatedTime = params.UpdatedTime
+	file.ByUpdate.MustOk().Set("size", file.Size)
+	file.ByUpdate.MustOk().Set("content_type", file.ContentType)
+	file.ByUpdate.MustOk().Set("updated_time", file.UpdatedTime)
+	path := joinPath(dir.storedPath(params.Foldername), file.Name)
+	dir.raise(newEvent(EventName_FileWritten, file.FsId, file.Id, folder.Id, path, "", params.UpdatedTime))
+	return file, content, nil
+}
+
+func (dir *Folder) ReadFile(params ReadFileParams) (*File, error) {
+	_, file, err := dir.findFile(params.Foldername, params.Filename)
+	return file, err
+}
+
+func (dir *Folder) MoveFile(params MoveFileParams) (*File, error) {
+	src, file, err := dir.findFile(params.SrcFoldername, params.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	newName := params.NewFilename
+	if newName == "" {
+		newName = file.Name
+	}
+
+	dst, err := dir.prepareFileDestination(params.DstFoldername, newName, file)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(folder.Files) == 0 {
-		return nil, ErrListFileEmpty
-	}
-
-	pkg.SortTraversalParams(params.Sort.Value(), func(key string, value pkg.SortKind) {
-		sort.Slice(folder.Files, func(i, j int) bool {
-			switch key {
-			case "name":
-				if value == pkg.SortKind_Asc {
-					return folder.Files[i].Name < folder.Files[j].Name
-				}
-				if value == pkg.SortKind_Desc {
-					return folder.Files[i].Name > folder.Files[j].Name
-				}
-
-			case "created":
-				if value == pkg.SortKind_Asc {
-					return folder.Files[i].CreatedTime.Second() < folder.Files[j].CreatedTime.Second()
-				}
-				if value == pkg.SortKind_Desc {
-					return folder.Files[i].CreatedTime.Second() > folder.Files[j].CreatedTime.Second()
-				}
+	oldPath := joinPath(dir.storedPath(params.SrcFoldername), file.Name)
+	for i, f := range src.Files {
+		if f == file {
+			src.Files = append(src.Files[:i], src.Files[i+1:]...)
+			break
+		}
+	}
+	dst.Files = append(dst.Files, file)
+
+	file.FolderId = dst.Id
+	file.Foldername = dst.Name
+	file.Name = newName
+	file.UpdatedTime = params.UpdatedTime
+	file.ByUpdate.MustOk().Set("folder_id", file.FolderId)
+	file.ByUpdate.MustOk().Set("foldername", file.Foldername)
+	file.ByUpdate.MustOk().Set("name", file.Name)
+	file.ByUpdate.MustOk().Set("updated_time", file.UpdatedTime)
+	path := joinPath(dir.storedPath(params.DstFoldername), file.Name)
+	dir.raise(newEvent(EventName_FileMoved, file.FsId, file.Id, dst.Id, path, oldPath, params.UpdatedTime))
+	return file, nil
+}
+
+// CopyFile returns the source file and its copy.
+// The copy keeps the description, size and content type of the source, but it is created at params.CreatedTime.
+func (dir *Folder) CopyFile(params CopyFileParams) (src *File, dst *File, err error) {
+	_, src, err = dir.findFile(params.SrcFoldername, params.Filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newName := params.NewFilename
+	if newName == "" {
+		newName = src.Name
+	}
+
+	folder, err := dir.prepareFileDestination(params.DstFoldername, newName, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dst, err = newFile(folder, CreateFileParams{
+		Foldername:  params.DstFoldername,
+		Filename:    newName,
+		Description: src.Description,
+		CreatedTime: params.CreatedTime,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	dst.Size = src.Size
+	dst.ContentType = src.ContentType
+
+	folder.Files = append(folder.Files, dst)
+	path := joinPath(dir.storedPath(params.DstFoldername), dst.Name)
+	oldPath := joinPath(dir.storedPath(params.SrcFoldername), src.Name)
+	dir.raise(newEvent(EventName_FileCopied, dst.FsId, dst.Id, folder.Id, path, oldPath, params.CreatedTime))
+	return src, dst, nil
+}
+
+// prepareFileDestination resolves the destination folder of a move or copy,
+// and makes sure filename doesn't collide with another file than self.
+func (dir *Folder) prepareFileDestination(foldername, filename string, self *File) (*Folder, error) {
+	folder, err := dir.findFolder(foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	other, ok := folder.findChildFile(filename)
+	if ok && other != self {
+		return nil, fmt.Errorf("Error: The %v %w", filename, ErrFileExists)
+	}
+	return folder, nil
+}
+
+func (dir *Folder) findFile(foldername, filename string) (*Folder, *File, error) {
+	folder, err := dir.findFolder(foldername)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, ok := folder.findChildFile(filename)
+	if !ok {
+		return nil, nil, fmt.Errorf("Error: The %v %w", filename, ErrFileNotExists)
+	}
+	return folder, file, nil
+}
+
+func (dir *Folder) findChildFile(filename string) (*File, bool) {
+	for _, file := range dir.Files {
+		if strings.EqualFold(file.Name, filename) {
+			return file, true
+		}
+	}
+	return nil, false
+}
+
+// ListFiles is ListFolders for the loaded files.
+func (dir *Folder) ListFiles(opts ListChildrenOptions) ([]*File, error) {
+	return listChildren(dir.Files, opts, true, func(file *File) childColumns {
+		return childColumns{
+			id:          file.Id,
+			name:        file.Name,
+			createdTime: file.CreatedTime,
+			size:        file.Size,
+			description: file.Description,
+		}
+	})
+}
+
+// childColumns are the columns which the children are filtered and sorted by.
+type childColumns struct {
+	id          string
+	name        string
+	createdTime time.Time
+	size        int64
+	description string
+}
+
+// listChildren filters and sorts the children, and then picks the page,
+// the page after a cursor holds the children sorted after the cursor.
+func listChildren[T any](children []T, opts ListChildrenOptions, isFile bool, columns func(T) childColumns) ([]T, error) {
+	err := opts.Sort.Validate(isFile)
+	if err != nil {
+		return nil, err
+	}
+	err = opts.Page.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor *childColumns
+	if opts.Page.Cursor != "" {
+		for _, child := range children {
+			c := columns(child)
+			if c.id == opts.Page.Cursor {
+				cursor = &c
+				break
 			}
+		}
+		if cursor == nil {
+			return nil, fmt.Errorf("Error: The cursor %v %w", opts.Page.Cursor, ErrInvalidParams)
+		}
+	}
+
+	compare := compareChildren(opts.Sort)
+	match := opts.Filter.matcher()
+	list := make([]T, 0, len(children))
+	for _, child := range children {
+		c := columns(child)
+		if !match(c.name, c.createdTime) {
+			continue
+		}
+		if cursor != nil && compare(c, *cursor) <= 0 {
+			continue
+		}
+		list = append(list, child)
+	}
 
-			return false
-		})
+	slices.SortStableFunc(list, func(a, b T) int {
+		return compare(columns(a), columns(b))
 	})
 
-	return folder.Files, nil
+	list = list[min(opts.Page.Offset, len(list)):]
+	if opts.Page.Limit > 0 {
+		list = list[:min(opts.Page.Limit, len(list))]
+	}
+	return list, nil
+}
+
+// compareChildren orders the children by the sort keys one after another, and then by id.
+func compareChildren(params *FileSystemSortParams) func(a, b childColumns) int {
+	params = params.Value()
+
+	compareString := strings.Compare
+	if params.Natural {
+		compareString = pkg.NaturalCompare
+	}
+	if params.IgnoreCase {
+		compareCase := compareString
+		compareString = func(a, b string) int {
+			return compareCase(strings.ToLower(a), strings.ToLower(b))
+		}
+	}
+
+	return func(a, b childColumns) int {
+		for _, k := range params.Keys {
+			var c int
+			switch k.Key {
+			case SortKeyName:
+				c = compareString(a.name, b.name)
+			case SortKeyCreated:
+				c = a.createdTime.Compare(b.createdTime)
+			case SortKeySize:
+				c = cmp.Compare(a.size, b.size)
+			case SortKeyDescription:
+				c = compareString(a.description, b.description)
+			}
+			if k.IsDesc() {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.id, b.id)
+	}
 }
 
-func newFile(folderId, fsId string, params CreateFileParams) (*File, error) {
+func newFile(folder *Folder, params CreateFileParams) (*File, error) {
 	err := validateFilename(params.Filename)
 	if err != nil {
 		return nil, err
@@ -249,12 +574,14 @@ func newFile(folderId, fsId string, params CreateFileParams) (*File, error) {
 
 	return &File{
 		Id:          pkg.NewUlid(),
-		FolderId:    folderId,
-		FsId:        fsId,
+		FolderId:    folder.Id,
+		FsId:        folder.FsId,
 		Name:        params.Filename,
-		Foldername:  params.Foldername,
+		Foldername:  folder.Name,
 		Description: params.Description,
 		CreatedTime: params.CreatedTime,
+		UpdatedTime: params.CreatedTime,
+		ContentType: pkg.DetectContentType(params.Filename, nil),
 		ByUpdate:    nil,
 	}, nil
 }
@@ -267,18 +594,94 @@ type File struct {
 	Foldername  string    `gorm:"column:foldername;type:varchar(256);not null"`
 	Description string    `gorm:"column:description;type:varchar(1024);not null"`
 	CreatedTime time.Time `gorm:"column:created_time;not null"`
+	UpdatedTime time.Time `gorm:"column:updated_time;not null"`
+	Size        int64     `gorm:"column:size;not null;default:0"`
+	ContentType string    `gorm:"column:content_type;type:varchar(256);not null"`
+	TrashId     string    `gorm:"column:trash_id;type:char(26);not null;default:'';index"`
 
 	ByUpdate pkg.MapData `gorm:"-"`
 }
 
+func newFileContent(file *File, data []byte) *FileContent {
+	if data == nil {
+		data = []byte{}
+	}
+	return &FileContent{
+		FileId: file.Id,
+		Data:   data,
+	}
+}
+
+// FileContent holds the bytes of a File.
+// It is stored apart from File, so loading the tree doesn't read any content.
+type FileContent struct {
+	FileId string `gorm:"column:file_id;type:char(26);not null;primaryKey"`
+	Data   []byte `gorm:"column:data;not null"`
+}
+
+func (c *FileContent) Size() int64 {
+	return int64(len(c.Data))
+}
+
+// path
+
+const pathSeparator = "/"
+
+func splitFolderSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, pathSeparator) {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// splitFolderPath splits path into the parent path and the last segment.
+// The name is empty when path refers to the root folder.
+func splitFolderPath(path string) (parentPath string, name string) {
+	segments := splitFolderSegments(path)
+	if len(segments) == 0 {
+		return pathSeparator, ""
+	}
+	n := len(segments)
+	return cleanPath(strings.Join(segments[:n-1], pathSeparator)), segments[n-1]
+}
+
+// cleanPath returns path in the form of "/a/b".
+func cleanPath(path string) string {
+	return pathSeparator + strings.Join(splitFolderSegments(path), pathSeparator)
+}
+
+// joinPath appends name to a path returned by cleanPath.
+func joinPath(path string, name string) string {
+	if path == pathSeparator {
+		return path + name
+	}
+	return path + pathSeparator + name
+}
+
+func isRootPath(path string) bool {
+	return len(splitFolderSegments(path)) == 0
+}
+
 // validate
 
+func validateFolderPath(path string) error {
+	for _, segment := range splitFolderSegments(path) {
+		if validateFoldername(segment) != nil {
+			return fmt.Errorf("Error: The %v %w", path, ErrInvalidParams)
+		}
+	}
+	return nil
+}
+
 func validateFoldername(foldername string) error {
-	if len(foldername) > 256 {
+	if foldername == "" || len(foldername) > 256 {
 		return fmt.Errorf("Error: The %v %w", foldername, ErrInvalidParams)
 	}
 	for _, char := range foldername {
-		if !(unicode.IsLetter(char) || unicode.IsNumber(char) || char == '_' || char == '-' || char == '/' || char == ' ') {
+		if !(unicode.IsLetter(char) || unicode.IsNumber(char) || char == '_' || char == '-' || char == ' ') {
 			return fmt.Errorf("Error: The %v %w", foldername, ErrInvalidParams)
 		}
 	}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/KScaesar/IsCoolLab2024/pkg"
@@ -15,16 +19,21 @@ type CreateFolderParams struct {
 }
 
 type DeleteFolderParams struct {
-	Foldername string `validate:"required,foldername"`
+	Foldername  string `validate:"required,foldername"`
+	DeletedTime time.Time
 }
 
 type ListFoldersParams struct {
-	Sort *FileSystemSortParams
+	Foldername string `validate:"foldername"`
+	Sort       *FileSystemSortParams
+	Filter     ListFilterParams
+	Page       PageParams
 }
 
 type RenameFolderParams struct {
 	OldFolderName string `validate:"required,foldername"`
 	NewFolderName string `validate:"required,foldername"`
+	UpdatedTime   time.Time
 }
 
 // file
@@ -37,43 +46,268 @@ type CreateFileParams struct {
 }
 
 type DeleteFileParams struct {
+	Foldername  string `validate:"required,foldername"`
+	Filename    string `validate:"required,filename"`
+	DeletedTime time.Time
+}
+
+type WriteFileParams struct {
+	Foldername  string `validate:"required,foldername"`
+	Filename    string `validate:"required,filename"`
+	Content     []byte
+	UpdatedTime time.Time
+}
+
+type ReadFileParams struct {
 	Foldername string `validate:"required,foldername"`
 	Filename   string `validate:"required,filename"`
 }
 
+type MoveFileParams struct {
+	SrcFoldername string `validate:"required,foldername"`
+	Filename      string `validate:"required,filename"`
+	DstFoldername string `validate:"required,foldername"`
+	NewFilename   string `validate:"filename"`
+	UpdatedTime   time.Time
+}
+
+type CopyFileParams struct {
+	SrcFoldername string `validate:"required,foldername"`
+	Filename      string `validate:"required,filename"`
+	DstFoldername string `validate:"required,foldername"`
+	NewFilename   string `validate:"filename"`
+	CreatedTime   time.Time
+}
+
 type ListFilesParams struct {
 	Foldername string `validate:"required,foldername"`
 	Sort       *FileSystemSortParams
+	Filter     ListFilterParams
+	Page       PageParams
+}
+
+// ListFilterParams narrows the listed children, the zero value matches all of them.
+type ListFilterParams struct {
+	// Name is a glob with "*" and "?" when it holds any of them, otherwise a substring,
+	// both are case-insensitive.
+	Name string
+
+	// CreatedAfter and CreatedBefore are exclusive, the zero value means unbounded.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// IsGlob reports whether Name is matched as a glob rather than a substring.
+func (p ListFilterParams) IsGlob() bool {
+	return strings.ContainsAny(p.Name, "*?")
+}
+
+// matcher compiles the filter once for all children.
+func (p ListFilterParams) matcher() func(name string, createdTime time.Time) bool {
+	matchName := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), strings.ToLower(p.Name))
+	}
+	if p.IsGlob() {
+		var pattern strings.Builder
+		pattern.WriteString("(?is)^")
+		for _, char := range p.Name {
+			switch char {
+			case '*':
+				pattern.WriteString(".*")
+			case '?':
+				pattern.WriteString(".")
+			default:
+				pattern.WriteString(regexp.QuoteMeta(string(char)))
+			}
+		}
+		pattern.WriteString("$")
+		matchName = regexp.MustCompile(pattern.String()).MatchString
+	}
+
+	return func(name string, createdTime time.Time) bool {
+		if !p.CreatedAfter.IsZero() && !createdTime.After(p.CreatedAfter) {
+			return false
+		}
+		if !p.CreatedBefore.IsZero() && !createdTime.Before(p.CreatedBefore) {
+			return false
+		}
+		return matchName(name)
+	}
+}
+
+// PageParams picks a page of the sorted children,
+// a page starts either at Offset or right after Cursor.
+type PageParams struct {
+	// Limit is the max number of children, zero means no limit.
+	Limit  int
+	Offset int
+
+	// Cursor is the id of the last child of the previous page,
+	// so the next page isn't shifted by the children created or deleted in the meantime.
+	Cursor string
+}
+
+func (p PageParams) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("Error: The limit %v %w", p.Limit, ErrInvalidParams)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("Error: The offset %v %w", p.Offset, ErrInvalidParams)
+	}
+	if p.Cursor != "" && (p.Offset != 0 || !pkg.IsUlid(p.Cursor)) {
+		return fmt.Errorf("Error: The cursor %v %w", p.Cursor, ErrInvalidParams)
+	}
+	return nil
+}
+
+// stat
+
+type StatParams struct {
+	// Path is a folder path, or a folder path followed by a filename.
+	Path string
+}
+
+// share
+
+type ShareFolderParams struct {
+	Owner      string `validate:"required,username"`
+	Foldername string `validate:"required,foldername"`
+	Grantee    string `validate:"required,username"`
+	// Permission is read, write or admin.
+	Permission  string
+	CreatedTime time.Time
+}
+
+type UnshareFolderParams struct {
+	Owner      string `validate:"required,username"`
+	Foldername string `validate:"required,foldername"`
+	Grantee    string `validate:"required,username"`
+}
+
+// trash
+
+type RestoreTrashParams struct {
+	// Target is the id or the original path of a TrashItem.
+	Target       string `validate:"required"`
+	RestoredTime time.Time
+}
+
+type EmptyTrashParams struct {
+	// OlderThan keeps the items deleted within the duration, zero empties all items.
+	OlderThan time.Duration
+	Now       time.Time
 }
 
 // sort
 
+const (
+	SortKeyName        = "name"
+	SortKeyCreated     = "created"
+	SortKeySize        = "size"
+	SortKeyDescription = "description"
+
+	NameOrderNatural    = "natural"
+	NameOrderIgnoreCase = "ignore-case"
+)
+
 var (
-	defaultFileSystemSortParams = &FileSystemSortParams{ByName: pkg.SortKind_Asc}
+	defaultFileSystemSortParams = &FileSystemSortParams{
+		Keys: []pkg.SortKey{{Key: SortKeyName, Kind: pkg.SortKind_Asc}},
+	}
+
+	// folderSortKeys leaves out size, a folder has no size of its own.
+	folderSortKeys = []string{SortKeyName, SortKeyCreated, SortKeyDescription}
+	fileSortKeys   = []string{SortKeyName, SortKeyCreated, SortKeySize, SortKeyDescription}
 )
 
+// FileSystemSortParams orders the children by Keys one after another,
+// a later key only breaks the ties of the former keys, and the id breaks the rest,
+// so the order is stable and a cursor always points to the same place.
 type FileSystemSortParams struct {
-	ByName    pkg.SortKind `sort:"name"`
-	ByCreated pkg.SortKind `sort:"created"`
+	Keys []pkg.SortKey
+
+	// IgnoreCase and Natural decide how name and description compare,
+	// Natural compares the digits by their numeric values, e.g. "file2" sorts before "file10".
+	IgnoreCase bool
+	Natural    bool
+}
+
+// ParseFileSystemSortParams parses the keys such as "created:desc,name:asc",
+// and the name order which holds "natural", "ignore-case" or both separated by a comma.
+// Empty strings leave the default.
+func ParseFileSystemSortParams(keys string, nameOrder string) (*FileSystemSortParams, error) {
+	params := &FileSystemSortParams{}
+	if keys != "" {
+		sortKeys, err := pkg.ParseSortKeys(keys)
+		if err != nil {
+			return nil, fmt.Errorf("Error: The sort %v %w", keys, ErrInvalidParams)
+		}
+		params.Keys = sortKeys
+	}
+
+	if nameOrder != "" {
+		for _, order := range strings.Split(nameOrder, ",") {
+			switch strings.ToLower(strings.TrimSpace(order)) {
+			case NameOrderNatural:
+				params.Natural = true
+			case NameOrderIgnoreCase:
+				params.IgnoreCase = true
+			default:
+				return nil, fmt.Errorf("Error: The name order %v %w", order, ErrInvalidParams)
+			}
+		}
+	}
+	return params, nil
 }
 
 func (p *FileSystemSortParams) Value() *FileSystemSortParams {
-	if p.IsZero() {
+	if p == nil {
 		return defaultFileSystemSortParams
 	}
+	if len(p.Keys) == 0 {
+		params := *p
+		params.Keys = defaultFileSystemSortParams.Keys
+		return &params
+	}
 	return p
 }
 
 func (p *FileSystemSortParams) IsZero() bool {
 	return p == nil ||
-		(p.ByName == "" && p.ByCreated == "")
+		(len(p.Keys) == 0 && !p.IgnoreCase && !p.Natural)
+}
+
+func (p *FileSystemSortParams) Has(key string) bool {
+	if p == nil {
+		return false
+	}
+	for _, k := range p.Keys {
+		if k.Key == key {
+			return true
+		}
+	}
+	return false
 }
 
-func (p *FileSystemSortParams) Validate() (err error) {
+// Validate accepts the keys of folders when isFile is false, otherwise the keys of files,
+// and each key is accepted once.
+func (p *FileSystemSortParams) Validate(isFile bool) error {
 	if p.IsZero() {
 		return nil
 	}
-	return pkg.SortValidate(p)
+
+	allowed := folderSortKeys
+	if isFile {
+		allowed = fileSortKeys
+	}
+	seen := make(map[string]bool, len(p.Keys))
+	for _, k := range p.Keys {
+		if !slices.Contains(allowed, k.Key) || seen[k.Key] || k.Kind.Validate() != nil {
+			return fmt.Errorf("Error: The sort %v %w", k.Key, ErrInvalidParams)
+		}
+		seen[k.Key] = true
+	}
+	return nil
 }
 
 // view model
@@ -84,14 +318,18 @@ func ToViewFolder(folder *Folder, username string) ViewFolder {
 		Description: folder.Description,
 		CreatedTime: folder.CreatedTime,
 		Username:    username,
+		Id:          folder.Id,
 	}
 }
 
 type ViewFolder struct {
-	Fodlername  string
-	Description string
-	CreatedTime time.Time
-	Username    string
+	Fodlername  string    `json:"foldername"`
+	Description string    `json:"description"`
+	CreatedTime time.Time `json:"created_time"`
+	Username    string    `json:"username"`
+
+	// Id is the cursor of the next page when the folder is the last one of a page.
+	Id string `json:"id"`
 }
 
 func ToViewFile(file *File, username string) ViewFile {
@@ -101,13 +339,106 @@ func ToViewFile(file *File, username string) ViewFile {
 		CreatedTime: file.CreatedTime,
 		Fodlername:  file.Foldername,
 		Username:    username,
+		Id:          file.Id,
 	}
 }
 
 type ViewFile struct {
-	Filename    string
-	Description string
-	CreatedTime time.Time
-	Fodlername  string
-	Username    string
+	Filename    string    `json:"filename"`
+	Description string    `json:"description"`
+	CreatedTime time.Time `json:"created_time"`
+	Fodlername  string    `json:"foldername"`
+	Username    string    `json:"username"`
+
+	// Id is the cursor of the next page when the file is the last one of a page.
+	Id string `json:"id"`
+}
+
+const (
+	StatKind_Folder = "folder"
+	StatKind_File   = "file"
+)
+
+func ToViewFolderStat(folder *Folder, path string, usage FolderUsage, username string) ViewStat {
+	return ViewStat{
+		Kind:        StatKind_Folder,
+		Path:        path,
+		Description: folder.Description,
+		Size:        usage.Size,
+		Folders:     usage.Folders,
+		Files:       usage.Files,
+		CreatedTime: folder.CreatedTime,
+		UpdatedTime: folder.UpdatedTime,
+		Username:    username,
+		Id:          folder.Id,
+	}
+}
+
+func ToViewFileStat(file *File, path string, username string) ViewStat {
+	return ViewStat{
+		Kind:        StatKind_File,
+		Path:        path,
+		Description: file.Description,
+		Size:        file.Size,
+		ContentType: file.ContentType,
+		CreatedTime: file.CreatedTime,
+		UpdatedTime: file.UpdatedTime,
+		Username:    username,
+		Id:          file.Id,
+	}
+}
+
+// ViewStat is the metadata of a folder or a file.
+// The size of a folder adds up the files in its subtree,
+// and Folders and Files count the subtree, the folder itself excluded.
+type ViewStat struct {
+	Kind        string    `json:"kind"`
+	Path        string    `json:"path"`
+	Description string    `json:"description"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	Folders     int       `json:"folders,omitempty"`
+	Files       int       `json:"files,omitempty"`
+	CreatedTime time.Time `json:"created_time"`
+	UpdatedTime time.Time `json:"updated_time"`
+	Username    string    `json:"username"`
+	Id          string    `json:"id"`
+}
+
+func ToViewSharedFolder(shared *SharedFolder, username string) ViewSharedFolder {
+	return ViewSharedFolder{
+		Owner:      shared.Owner,
+		Path:       shared.Path,
+		Permission: string(shared.Grant.Permission),
+		SharedTime: shared.Grant.CreatedTime,
+		Username:   username,
+		Id:         shared.Grant.FolderId,
+	}
+}
+
+type ViewSharedFolder struct {
+	Owner      string    `json:"owner"`
+	Path       string    `json:"path"`
+	Permission string    `json:"permission"`
+	SharedTime time.Time `json:"shared_time"`
+	Username   string    `json:"username"`
+	Id         string    `json:"id"`
+}
+
+func ToViewTrashItem(item *TrashItem, username string) ViewTrashItem {
+	return ViewTrashItem{
+		Id:          item.Id,
+		Kind:        string(item.Kind),
+		Path:        item.Path,
+		DeletedTime: item.DeletedTime,
+		Username:    username,
+	}
+}
+
+type ViewTrashItem struct {
+	Id          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Path        string    `json:"path"`
+	DeletedTime time.Time `json:"deleted_time"`
+	Username    string    `json:"username"`
 }
@@ -2,18 +2,174 @@ package app
 
 import (
 	"context"
+	"errors"
 )
 
 type FileSystemRepository interface {
 	CreateFileSystem(ctx context.Context, fs *FileSystem) error
-	GetFileSystemByUsername(ctx context.Context, username string) (*FileSystem, error)
-	GetFileSystemByUsernameV2(ctx context.Context, username string) (*FileSystem, error)
-	GetFileSystemByUsernameV3(ctx context.Context, username string) (*FileSystem, error)
+	// DeleteFileSystem permanently deletes fs with all of its folders, files, trash and webhooks.
+	DeleteFileSystem(ctx context.Context, fs *FileSystem) error
+	// LoadFileSystem loads the whole tree of the user, the folders and files in the trash are excluded.
+	LoadFileSystem(ctx context.Context, username string, opts LoadFileSystemOptions) (*FileSystem, error)
+	// FindFileSystem returns fs with the root folder only, the children of the root aren't loaded.
+	FindFileSystem(ctx context.Context, username string) (*FileSystem, error)
+
+	// FindChildFolder finds the live folder under parent by name case-insensitively,
+	// it reports ErrFolderNotExists when there is no such folder, so it serves as the existence check.
+	// The children of the returned folder aren't loaded.
+	FindChildFolder(ctx context.Context, parent *Folder, name string) (*Folder, error)
+	// FindChildFile is FindChildFolder for the files, it reports ErrFileNotExists.
+	FindChildFile(ctx context.Context, folder *Folder, name string) (*File, error)
+	// FindFolder finds the live folder of fs by id, it reports ErrFolderNotExists.
+	// The children of the returned folder aren't loaded.
+	FindFolder(ctx context.Context, fsId string, id string) (*Folder, error)
+	// ListChildFolders returns the live folders under parent, sorted and paginated by the repository.
+	ListChildFolders(ctx context.Context, parent *Folder, opts ListChildrenOptions) ([]*Folder, error)
+	ListChildFiles(ctx context.Context, folder *Folder, opts ListChildrenOptions) ([]*File, error)
+	// SumFolder adds up the live subtree under folder, without loading it.
+	SumFolder(ctx context.Context, folder *Folder) (FolderUsage, error)
+	// SumFileSystem adds up all the folders and files of fs, the ones in the trash included,
+	// the root folder isn't counted.
+	SumFileSystem(ctx context.Context, fs *FileSystem) (FolderUsage, error)
 
 	CreateFolder(ctx context.Context, folder *Folder) error
-	DeleteFolder(ctx context.Context, folder *Folder) error
 	UpdateFolder(ctx context.Context, folder *Folder) error
+	TrashFolder(ctx context.Context, folder *Folder, item *TrashItem) error
 
 	CreateFile(ctx context.Context, file *File) error
-	DeleteFile(ctx context.Context, file *File) error
+	TrashFile(ctx context.Context, file *File, item *TrashItem) error
+	UpdateFile(ctx context.Context, file *File) error
+	CopyFile(ctx context.Context, src *File, dst *File) error
+
+	SaveFileContent(ctx context.Context, content *FileContent) error
+	GetFileContent(ctx context.Context, fileId string) (*FileContent, error)
+
+	// ListGrants returns the grants of fs to grantee, the grantee is case-insensitive.
+	ListGrants(ctx context.Context, fsId string, grantee string) ([]*Grant, error)
+	// SaveGrant creates grant, or updates its permission when it exists.
+	SaveGrant(ctx context.Context, grant *Grant) error
+	DeleteGrant(ctx context.Context, grant *Grant) error
+	// ListSharedFolders returns the live folders shared with grantee, and where they are.
+	ListSharedFolders(ctx context.Context, grantee string) ([]*SharedFolder, error)
+
+	ListTrashItems(ctx context.Context, fsId string) ([]*TrashItem, error)
+	RestoreTrashItem(ctx context.Context, item *TrashItem) error
+	PurgeTrashItems(ctx context.Context, items []*TrashItem) error
+}
+
+// FolderUsage is what the live subtree of a folder holds,
+// the folder itself isn't counted in Folders.
+type FolderUsage struct {
+	Size    int64 `gorm:"column:size"`
+	Folders int   `gorm:"column:folders"`
+	Files   int   `gorm:"column:files"`
+}
+
+// LoadStrategy is how FileSystemRepository.LoadFileSystem queries the tree,
+// every strategy returns the same FileSystem.
+type LoadStrategy string
+
+const (
+	// LoadStrategy_Default is chosen by the repository, it should be the fastest one.
+	LoadStrategy_Default LoadStrategy = ""
+
+	// LoadStrategy_Preload queries the folders level by level.
+	LoadStrategy_Preload LoadStrategy = "preload"
+
+	// LoadStrategy_Join queries the folders joined with their files in one flat result.
+	LoadStrategy_Join LoadStrategy = "join"
+
+	// LoadStrategy_Recursive queries the tree by a recursive common table expression.
+	LoadStrategy_Recursive LoadStrategy = "recursive"
+)
+
+var LoadStrategies = []LoadStrategy{LoadStrategy_Preload, LoadStrategy_Join, LoadStrategy_Recursive}
+
+type LoadFileSystemOptions struct {
+	Strategy LoadStrategy
+}
+
+// ListChildrenOptions is applied by the repository,
+// the children with the same sort key are ordered by id, so the pages are stable.
+// Folder.ListFolders and Folder.ListFiles define how it is applied.
+type ListChildrenOptions struct {
+	Sort   *FileSystemSortParams
+	Filter ListFilterParams
+	Page   PageParams
+}
+
+// The use cases load only the part of a FileSystem they work on,
+// the rules stay in Folder, which sees the loaded folders and files only.
+// A path which doesn't exist is left unloaded, so Folder reports it as usual.
+
+// loadFolder loads the folders on path below root one segment at a time,
+// and returns the last one, nil means path doesn't exist.
+func loadFolder(ctx context.Context, repo FileSystemRepository, root *Folder, path string) (*Folder, error) {
+	folder := root
+	for _, segment := range splitFolderSegments(path) {
+		child, ok := folder.findChildFolder(segment)
+		if !ok {
+			var err error
+			child, err = repo.FindChildFolder(ctx, folder, segment)
+			if errors.Is(err, ErrFolderNotExists) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			folder.Folders = append(folder.Folders, child)
+		}
+		folder = child
+	}
+	return folder, nil
+}
+
+// loadChildFolder loads the folder on path, and its child folder named name if it exists.
+func loadChildFolder(ctx context.Context, repo FileSystemRepository, root *Folder, path string, name string) error {
+	parent, err := loadFolder(ctx, repo, root, path)
+	if err != nil || parent == nil || name == "" {
+		return err
+	}
+	_, err = loadFolder(ctx, repo, parent, name)
+	return err
+}
+
+// loadChildFile loads the folder on path, and its file named name if it exists.
+func loadChildFile(ctx context.Context, repo FileSystemRepository, root *Folder, path string, name string) error {
+	folder, err := loadFolder(ctx, repo, root, path)
+	if err != nil || folder == nil {
+		return err
+	}
+
+	_, ok := folder.findChildFile(name)
+	if ok {
+		return nil
+	}
+
+	file, err := repo.FindChildFile(ctx, folder, name)
+	if errors.Is(err, ErrFileNotExists) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	folder.Files = append(folder.Files, file)
+	return nil
+}
+
+// checkQuota checks the quota of the owner of fs before fs grows by delta.
+func checkQuota(ctx context.Context, userRepo UserRepository, fsRepo FileSystemRepository, fs *FileSystem, delta FolderUsage) error {
+	user, err := userRepo.QueryUserByName(ctx, fs.Username)
+	if err != nil {
+		return err
+	}
+	if user.Quota.IsUnlimited() {
+		return nil
+	}
+
+	usage, err := fsRepo.SumFileSystem(ctx, fs)
+	if err != nil {
+		return err
+	}
+	return user.Quota.Check(user.Username, usage, delta)
 }
@@ -28,6 +28,14 @@ func testFileSystem() *FileSystem {
 		Foldername:  "/tmp",
 		CreatedTime: createdTime.Add(time.Second),
 	})
+	fs.Root.CreateFolder(CreateFolderParams{
+		Foldername:  "/home/prod",
+		CreatedTime: createdTime,
+	})
+	fs.Root.CreateFolder(CreateFolderParams{
+		Foldername:  "/home/dev",
+		CreatedTime: createdTime,
+	})
 
 	// file
 	_, err = fs.Root.CreateFile(CreateFileParams{
@@ -75,6 +83,24 @@ func TestFolder_CreateFolder(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "nested folder",
+			params: CreateFolderParams{
+				Foldername: "/home/dev/logs",
+			},
+			wantErr: nil,
+			assert: func(t *testing.T) {
+				folder, err := fs.Root.findFolder("/home/dev/logs")
+				if err != nil {
+					t.Errorf("CreateFolder() error=%v", err)
+					return
+				}
+				want := "logs"
+				if folder.Name != want {
+					t.Errorf("CreateFolder() name=%v, want=%v", folder.Name, want)
+				}
+			},
+		},
 		{
 			name: "The [foldername] has already existed.",
 			params: CreateFolderParams{
@@ -82,6 +108,20 @@ func TestFolder_CreateFolder(t *testing.T) {
 			},
 			wantErr: ErrFolderExists,
 		},
+		{
+			name: "The [foldername] has already existed with different case.",
+			params: CreateFolderParams{
+				Foldername: "/HOME/Dev",
+			},
+			wantErr: ErrFolderExists,
+		},
+		{
+			name: "The parent of [foldername] doesn't exist.",
+			params: CreateFolderParams{
+				Foldername: "/var/log",
+			},
+			wantErr: ErrFolderNotExists,
+		},
 		{
 			name: "The [foldername] contain invalid chars.",
 			params: CreateFolderParams{
@@ -114,6 +154,20 @@ func TestFolder_DeleteFolder(t *testing.T) {
 		wantErr error
 		assert  func(t *testing.T)
 	}{
+		{
+			name: "nested folder",
+			params: DeleteFolderParams{
+				Foldername: "/home/dev",
+			},
+			wantErr: nil,
+			assert: func(t *testing.T) {
+				n := len(fs.Root.Folders[0].Folders)
+				want := 1
+				if n != want {
+					t.Errorf("DeleteFolder() len=%v, want=%v", n, want)
+				}
+			},
+		},
 		{
 			name: "success",
 			params: DeleteFolderParams{
@@ -141,7 +195,7 @@ func TestFolder_DeleteFolder(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := fs.Root.DeleteFolder(tt.params)
+			_, _, err := fs.Root.DeleteFolder(tt.params)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("DeleteFolder() error=%v, want=%v", err, tt.wantErr)
 			}
@@ -154,66 +208,99 @@ func TestFolder_DeleteFolder(t *testing.T) {
 
 func TestFolder_ListFolders(t *testing.T) {
 	fs := testFileSystem()
+	home, _ := fs.Root.findFolder("/home")
+	etc, _ := fs.Root.findFolder("/etc")
 
 	tests := []struct {
 		name    string
-		params  ListFoldersParams
+		dir     *Folder
+		opts    ListChildrenOptions
 		wantErr error
-		assert  func(t *testing.T, folders []*Folder)
+		want    []string
 	}{
 		{
 			name: "by default",
-			params: ListFoldersParams{
-				Sort: nil,
-			},
-			wantErr: nil,
-			assert: func(t *testing.T, folders []*Folder) {
-				want := []string{"/etc", "/home", "/tmp"}
-				for i, folder := range folders {
-					if folder.Name != want[i] {
-						t.Errorf("ListFolders() folder=%v, want=%v", folder.Name, want[i])
-					}
-				}
-			},
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{Sort: nil},
+			want: []string{"etc", "home", "tmp"},
 		},
 		{
 			name: "by name",
-			params: ListFoldersParams{
-				Sort: &FileSystemSortParams{ByName: pkg.SortKind_Desc},
-			},
-			wantErr: nil,
-			assert: func(t *testing.T, folders []*Folder) {
-				want := []string{"/tmp", "/home", "/etc"}
-				for i, folder := range folders {
-					if folder.Name != want[i] {
-						t.Errorf("ListFolders() folder=%v, want=%v", folder.Name, want[i])
-					}
-				}
-			},
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{Sort: &FileSystemSortParams{Keys: []pkg.SortKey{{Key: SortKeyName, Kind: pkg.SortKind_Desc}}}},
+			want: []string{"tmp", "home", "etc"},
+		},
+		{
+			name: "nested folder",
+			dir:  home,
+			opts: ListChildrenOptions{Sort: nil},
+			want: []string{"dev", "prod"},
 		},
 		{
 			name: "by createdTime",
-			params: ListFoldersParams{
-				Sort: &FileSystemSortParams{ByCreated: pkg.SortKind_Desc},
-			},
-			wantErr: nil,
-			assert: func(t *testing.T, folders []*Folder) {
-				want := []string{"/etc", "/tmp", "/home"}
-				for i, folder := range folders {
-					if folder.Name != want[i] {
-						t.Errorf("ListFolders() folder=%v, want=%v", folder.Name, want[i])
-					}
-				}
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{Sort: &FileSystemSortParams{Keys: []pkg.SortKey{{Key: SortKeyCreated, Kind: pkg.SortKind_Desc}}}},
+			want: []string{"etc", "tmp", "home"},
+		},
+		{
+			name: "limit and offset",
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{Page: PageParams{Limit: 1, Offset: 1}},
+			want: []string{"home"},
+		},
+		{
+			name: "cursor",
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{
+				Sort: &FileSystemSortParams{Keys: []pkg.SortKey{{Key: SortKeyCreated, Kind: pkg.SortKind_Desc}}},
+				Page: PageParams{Cursor: etc.Id},
 			},
+			want: []string{"tmp", "home"},
+		},
+		{
+			name:    "The cursor isn't a child.",
+			dir:     &fs.Root,
+			opts:    ListChildrenOptions{Page: PageParams{Cursor: home.Folders[0].Id}},
+			wantErr: ErrInvalidParams,
+		},
+		{
+			name:    "The cursor isn't an id.",
+			dir:     &fs.Root,
+			opts:    ListChildrenOptions{Page: PageParams{Cursor: "home"}},
+			wantErr: ErrInvalidParams,
+		},
+		{
+			name:    "The limit is negative.",
+			dir:     &fs.Root,
+			opts:    ListChildrenOptions{Page: PageParams{Limit: -1}},
+			wantErr: ErrInvalidParams,
+		},
+		{
+			name: "filter by substring",
+			dir:  &fs.Root,
+			opts: ListChildrenOptions{Filter: ListFilterParams{Name: "M"}},
+			want: []string{"home", "tmp"},
 		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			folders := fs.Root.ListFolders(tt.params)
-			if tt.assert != nil {
-				tt.assert(t, folders)
+			folders, err := tt.dir.ListFolders(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ListFolders() error=%v, want=%v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(folders) != len(tt.want) {
+				t.Errorf("ListFolders() len=%v, want=%v", len(folders), len(tt.want))
+				return
+			}
+			for i, folder := range folders {
+				if folder.Name != tt.want[i] {
+					t.Errorf("ListFolders() folder=%v, want=%v", folder.Name, tt.want[i])
+				}
 			}
 		})
 	}
@@ -263,11 +350,19 @@ func TestFolder_RenameFolder(t *testing.T) {
 		{
 			name: "NewName exist",
 			params: RenameFolderParams{
-				OldFolderName: "/home",
+				OldFolderName: "/home2",
 				NewFolderName: "/etc",
 			},
 			wantErr: ErrFolderExists,
 		},
+		{
+			name: "NewName exist in nested folder",
+			params: RenameFolderParams{
+				OldFolderName: "/home2/dev",
+				NewFolderName: "prod",
+			},
+			wantErr: ErrFolderExists,
+		},
 	}
 
 	for _, tt := range tests {
@@ -323,6 +418,14 @@ func TestFolder_CreateFile(t *testing.T) {
 			},
 			wantErr: ErrFileExists,
 		},
+		{
+			name: "The [filename] is case-insensitive.",
+			params: CreateFileParams{
+				Foldername: "/home",
+				Filename:   "DEV.conf",
+			},
+			wantErr: ErrFileExists,
+		},
 	}
 
 	for _, tt := range tests {
@@ -383,7 +486,7 @@ func TestFolder_DeleteFile(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			file, err := fs.Root.DeleteFile(tt.params)
+			file, _, err := fs.Root.DeleteFile(tt.params)
 			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("DeleteFile() error=%v, want=%v", err, tt.wantErr)
 			}
@@ -396,80 +499,399 @@ func TestFolder_DeleteFile(t *testing.T) {
 
 func TestFolder_ListFiles(t *testing.T) {
 	fs := testFileSystem()
+	home, _ := fs.Root.findFolder("/home")
+	createdTime := home.Files[0].CreatedTime
+
+	tests := []struct {
+		name string
+		opts ListChildrenOptions
+		want []string
+	}{
+		{
+			name: "by default",
+			opts: ListChildrenOptions{Sort: &FileSystemSortParams{}},
+			want: []string{"dev.conf", "prod.conf", "qa.conf"},
+		},
+		{
+			name: "by name",
+			opts: ListChildrenOptions{Sort: &FileSystemSortParams{Keys: []pkg.SortKey{{Key: SortKeyName, Kind: pkg.SortKind_Desc}}}},
+			want: []string{"qa.conf", "prod.conf", "dev.conf"},
+		},
+		{
+			name: "by created",
+			opts: ListChildrenOptions{Sort: &FileSystemSortParams{Keys: []pkg.SortKey{{Key: SortKeyCreated, Kind: pkg.SortKind_Desc}}}},
+			want: []string{"prod.conf", "qa.conf", "dev.conf"},
+		},
+		{
+			name: "filter by glob",
+			opts: ListChildrenOptions{Filter: ListFilterParams{Name: "*D*.CONF"}},
+			want: []string{"dev.conf", "prod.conf"},
+		},
+		{
+			name: "filter by created",
+			opts: ListChildrenOptions{Filter: ListFilterParams{CreatedAfter: createdTime}},
+			want: []string{"prod.conf", "qa.conf"},
+		},
+		{
+			name: "limit",
+			opts: ListChildrenOptions{Page: PageParams{Limit: 2}},
+			want: []string{"dev.conf", "prod.conf"},
+		},
+		{
+			name: "The folder is empty.",
+			opts: ListChildrenOptions{Filter: ListFilterParams{Name: "gopher book"}},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := home.ListFiles(tt.opts)
+			if err != nil {
+				t.Errorf("ListFiles() error=%v", err)
+				return
+			}
+			if len(files) != len(tt.want) {
+				t.Errorf("ListFiles() len=%v, want=%v", len(files), len(tt.want))
+				return
+			}
+			for i, file := range files {
+				if file.Name != tt.want[i] {
+					t.Errorf("ListFiles() file=%v, want=%v", file.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFolder_ListFiles_sortKeys(t *testing.T) {
+	// the created times are a minute apart, though their seconds are 59 and 00
+	createdTime := pkg.NewMockTimeFunc("2024-05-26T12:00:59+08:00").Now()
+	dir := &Folder{Files: []*File{
+		{Id: "01", Name: "a.log", CreatedTime: createdTime, Size: 2},
+		{Id: "02", Name: "b.log", CreatedTime: createdTime.Add(time.Second), Size: 1},
+		{Id: "03", Name: "c.log", CreatedTime: createdTime.Add(time.Second), Size: 2},
+	}}
+
+	tests := []struct {
+		name string
+		keys string
+		want []string
+	}{
+		{
+			name: "by created",
+			keys: "created",
+			want: []string{"a.log", "b.log", "c.log"},
+		},
+		{
+			name: "by created and size",
+			keys: "created:desc,size:desc",
+			want: []string{"c.log", "b.log", "a.log"},
+		},
+		{
+			name: "by size and created",
+			keys: "size:desc,created:desc",
+			want: []string{"c.log", "a.log", "b.log"},
+		},
+		{
+			name: "ties broken by id",
+			keys: "description",
+			want: []string{"a.log", "b.log", "c.log"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseFileSystemSortParams(tt.keys, "")
+			if err != nil {
+				t.Errorf("ParseFileSystemSortParams() error=%v", err)
+				return
+			}
+			files, err := dir.ListFiles(ListChildrenOptions{Sort: sort})
+			if err != nil {
+				t.Errorf("ListFiles() error=%v", err)
+				return
+			}
+			for i, file := range files {
+				if file.Name != tt.want[i] {
+					t.Errorf("ListFiles() file=%v, want=%v", file.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFolder_WriteFile(t *testing.T) {
+	fs := testFileSystem()
 
 	tests := []struct {
 		name    string
-		params  ListFilesParams
+		params  WriteFileParams
 		wantErr error
-		assert  func(t *testing.T, files []*File)
+		assert  func(t *testing.T, file *File, content *FileContent)
 	}{
 		{
-			name: "by default",
-			params: ListFilesParams{
-				Foldername: "/home",
-				Sort:       &FileSystemSortParams{},
+			name: "success",
+			params: WriteFileParams{
+				Foldername:  "/home",
+				Filename:    "dev.conf",
+				Content:     []byte("port=8080"),
+				UpdatedTime: pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now(),
 			},
 			wantErr: nil,
-			assert: func(t *testing.T, files []*File) {
-				want := []string{"dev.conf", "prod.conf", "qa.conf"}
-				for i, file := range files {
-					if file.Name != want[i] {
-						t.Errorf("ListFiles() file=%v, want=%v", file.Name, want[i])
-					}
+			assert: func(t *testing.T, file *File, content *FileContent) {
+				if file.Size != 9 {
+					t.Errorf("WriteFile() size=%v, want=%v", file.Size, 9)
+				}
+				if !file.UpdatedTime.After(file.CreatedTime) {
+					t.Errorf("WriteFile() updatedTime=%v, want after %v", file.UpdatedTime, file.CreatedTime)
+				}
+				if content.FileId != file.Id {
+					t.Errorf("WriteFile() fileId=%v, want=%v", content.FileId, file.Id)
 				}
 			},
 		},
 		{
-			name: "by name",
-			params: ListFilesParams{
+			name: "The [foldername] doesn't exist.",
+			params: WriteFileParams{
+				Foldername: "app",
+				Filename:   "dev.conf",
+			},
+			wantErr: ErrFolderNotExists,
+		},
+		{
+			name: "The [filename] doesn't exist.",
+			params: WriteFileParams{
 				Foldername: "/home",
-				Sort:       &FileSystemSortParams{ByName: pkg.SortKind_Desc},
+				Filename:   "qa.key",
+			},
+			wantErr: ErrFileNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			file, content, err := fs.Root.WriteFile(tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WriteFile() error=%v, want=%v", err, tt.wantErr)
+			}
+			if tt.assert != nil {
+				tt.assert(t, file, content)
+			}
+		})
+	}
+}
+
+func TestFolder_MoveFile(t *testing.T) {
+	fs := testFileSystem()
+
+	tests := []struct {
+		name    string
+		params  MoveFileParams
+		wantErr error
+		assert  func(t *testing.T, file *File)
+	}{
+		{
+			name: "success",
+			params: MoveFileParams{
+				SrcFoldername: "/home",
+				Filename:      "dev.conf",
+				DstFoldername: "/home/dev",
 			},
 			wantErr: nil,
-			assert: func(t *testing.T, files []*File) {
-				want := []string{"qa.conf", "prod.conf", "dev.conf"}
-				for i, file := range files {
-					if file.Name != want[i] {
-						t.Errorf("ListFiles() file=%v, want=%v", file.Name, want[i])
-					}
+			assert: func(t *testing.T, file *File) {
+				dst, _ := fs.Root.findFolder("/home/dev")
+				if file.FolderId != dst.Id || file.Foldername != "dev" {
+					t.Errorf("MoveFile() folder=%v, want=%v", file.Foldername, "dev")
+				}
+				if !file.CreatedTime.Equal(pkg.NewMockTimeFunc("2024-05-26T12:00:00+08:00").Now()) {
+					t.Errorf("MoveFile() createdTime=%v", file.CreatedTime)
+				}
+				src, _ := fs.Root.findFolder("/home")
+				if len(src.Files) != 2 || len(dst.Files) != 1 {
+					t.Errorf("MoveFile() src len=%v, dst len=%v", len(src.Files), len(dst.Files))
 				}
 			},
 		},
 		{
-			name: "by created",
-			params: ListFilesParams{
-				Foldername: "/home",
-				Sort:       &FileSystemSortParams{ByCreated: pkg.SortKind_Desc},
+			name: "with new name",
+			params: MoveFileParams{
+				SrcFoldername: "/home",
+				Filename:      "qa.conf",
+				DstFoldername: "/home",
+				NewFilename:   "staging.conf",
 			},
 			wantErr: nil,
-			assert: func(t *testing.T, files []*File) {
-				want := []string{"prod.conf", "qa.conf", "dev.conf"}
-				for i, file := range files {
-					if file.Name != want[i] {
-						t.Errorf("ListFiles() file=%v, want=%v", file.Name, want[i])
-					}
+			assert: func(t *testing.T, file *File) {
+				if file.Name != "staging.conf" {
+					t.Errorf("MoveFile() name=%v, want=%v", file.Name, "staging.conf")
 				}
 			},
 		},
 		{
-			name: "The folder is empty.",
-			params: ListFilesParams{
-				Foldername: "/etc",
-				Sort:       &FileSystemSortParams{},
+			name: "The [new-name] has already existed with different case.",
+			params: MoveFileParams{
+				SrcFoldername: "/home",
+				Filename:      "prod.conf",
+				DstFoldername: "/home",
+				NewFilename:   "Staging.conf",
 			},
-			wantErr: ErrListFileEmpty,
+			wantErr: ErrFileExists,
+		},
+		{
+			name: "The [dst-folder] doesn't exist.",
+			params: MoveFileParams{
+				SrcFoldername: "/home",
+				Filename:      "prod.conf",
+				DstFoldername: "/var",
+			},
+			wantErr: ErrFolderNotExists,
+		},
+		{
+			name: "The [filename] doesn't exist.",
+			params: MoveFileParams{
+				SrcFoldername: "/home",
+				Filename:      "dev.conf",
+				DstFoldername: "/etc",
+			},
+			wantErr: ErrFileNotExists,
 		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			files, err := fs.Root.ListFiles(tt.params)
+			file, err := fs.Root.MoveFile(tt.params)
 			if !errors.Is(err, tt.wantErr) {
-				t.Errorf("ListFiles() error=%v, want=%v", err, tt.wantErr)
+				t.Errorf("MoveFile() error=%v, want=%v", err, tt.wantErr)
 			}
 			if tt.assert != nil {
-				tt.assert(t, files)
+				tt.assert(t, file)
+			}
+		})
+	}
+}
+
+func TestFolder_CopyFile(t *testing.T) {
+	fs := testFileSystem()
+
+	tests := []struct {
+		name    string
+		params  CopyFileParams
+		wantErr error
+		assert  func(t *testing.T, src *File, dst *File)
+	}{
+		{
+			name: "success",
+			params: CopyFileParams{
+				SrcFoldername: "/home",
+				Filename:      "dev.conf",
+				DstFoldername: "/etc",
+			},
+			wantErr: nil,
+			assert: func(t *testing.T, src *File, dst *File) {
+				if dst.Id == src.Id || dst.Name != src.Name || dst.Foldername != "etc" {
+					t.Errorf("CopyFile() dst=%v/%v", dst.Foldername, dst.Name)
+				}
+				folder, _ := fs.Root.findFolder("/home")
+				if len(folder.Files) != 3 {
+					t.Errorf("CopyFile() src len=%v, want=%v", len(folder.Files), 3)
+				}
+			},
+		},
+		{
+			name: "The [filename] has already existed in the same folder.",
+			params: CopyFileParams{
+				SrcFoldername: "/home",
+				Filename:      "dev.conf",
+				DstFoldername: "/home",
+			},
+			wantErr: ErrFileExists,
+		},
+		{
+			name: "The [new-name] contain invalid chars.",
+			params: CopyFileParams{
+				SrcFoldername: "/home",
+				Filename:      "dev.conf",
+				DstFoldername: "/home",
+				NewFilename:   "dev@2.conf",
+			},
+			wantErr: ErrInvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst, err := fs.Root.CopyFile(tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CopyFile() error=%v, want=%v", err, tt.wantErr)
+			}
+			if tt.assert != nil {
+				tt.assert(t, src, dst)
+			}
+		})
+	}
+}
+
+func TestFolder_Stat(t *testing.T) {
+	fs := testFileSystem()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantFolder string
+		wantFile   string
+		wantErr    error
+	}{
+		{
+			name:       "root",
+			path:       "/",
+			wantFolder: "/",
+		},
+		{
+			name:       "folder",
+			path:       "/home/dev",
+			wantFolder: "dev",
+		},
+		{
+			name:     "file",
+			path:     "/HOME/qa.conf",
+			wantFile: "qa.conf",
+		},
+		{
+			name:    "The [path] doesn't exist.",
+			path:    "/home/none",
+			wantErr: ErrPathNotExists,
+		},
+		{
+			name:    "The parent of [path] doesn't exist.",
+			path:    "/app/dev.conf",
+			wantErr: ErrPathNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			folder, file, err := fs.Root.Stat(tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Stat() error=%v, want=%v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.wantFile != "" {
+				if file == nil || file.Name != tt.wantFile {
+					t.Errorf("Stat() file=%v, want=%v", file, tt.wantFile)
+				}
+				return
+			}
+			if folder == nil || folder.Name != tt.wantFolder {
+				t.Errorf("Stat() folder=%v, want=%v", folder, tt.wantFolder)
 			}
 		})
 	}
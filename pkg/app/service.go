@@ -2,6 +2,15 @@ package app
 
 type Service struct {
 	UserService
+	AuthService
 	FolderService
 	FileService
+	TrashService
+	StatService
+	ShareService
+	AuditService
+	WebhookService
+
+	// Events lets the adapters subscribe to the domain events.
+	Events *EventBus
 }
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+)
+
+type StatService interface {
+	Stat(ctx context.Context, username string, params StatParams) (ViewStat, error)
+}
+
+func NewStatUseCase(uow UnitOfWork, fsRepo FileSystemRepository) *StatUseCase {
+	return &StatUseCase{
+		Uow:    uow,
+		FsRepo: fsRepo,
+	}
+}
+
+type StatUseCase struct {
+	Uow    UnitOfWork
+	FsRepo FileSystemRepository
+}
+
+func (uc *StatUseCase) Stat(ctx context.Context, username string, params StatParams) (ViewStat, error) {
+	var response ViewStat
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		parentPath, name := splitFolderPath(params.Path)
+		if name != "" {
+			err = loadChildFolder(ctx, uc.FsRepo, &fs.Root, parentPath, name)
+			if err != nil {
+				return err
+			}
+			err = loadChildFile(ctx, uc.FsRepo, &fs.Root, parentPath, name)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, params.Path, Permission_Read)
+		if err != nil {
+			return err
+		}
+
+		folder, file, err := fs.Root.Stat(params.Path)
+		if err != nil {
+			return err
+		}
+
+		path := fs.Root.storedPath(parentPath)
+		if file != nil {
+			response = ToViewFileStat(file, joinPath(path, file.Name), username)
+			return nil
+		}
+
+		usage, err := uc.FsRepo.SumFolder(ctx, folder)
+		if err != nil {
+			return err
+		}
+		if folder != &fs.Root {
+			path = joinPath(path, folder.Name)
+		}
+		response = ToViewFolderStat(folder, path, usage, username)
+		return nil
+	})
+	if err != nil {
+		return ViewStat{}, err
+	}
+
+	return response, nil
+}
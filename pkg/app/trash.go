@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+type TrashKind string
+
+const (
+	TrashKind_Folder TrashKind = "folder"
+	TrashKind_File   TrashKind = "file"
+)
+
+func newTrashItem(kind TrashKind, targetId, parentId, fsId, path string, deletedTime time.Time) *TrashItem {
+	return &TrashItem{
+		Id:          pkg.NewUlid(),
+		FsId:        fsId,
+		Kind:        kind,
+		TargetId:    targetId,
+		ParentId:    parentId,
+		Path:        path,
+		DeletedTime: deletedTime,
+	}
+}
+
+// TrashItem records a folder or a file removed by the user.
+// The folders and files of its subtree are kept with TrashId = TrashItem.Id,
+// so they can be restored until the trash is emptied.
+type TrashItem struct {
+	Id          string    `gorm:"column:id;type:char(26);not null;primaryKey"`
+	FsId        string    `gorm:"column:fs_id;type:char(26);not null;index"`
+	Kind        TrashKind `gorm:"column:kind;type:varchar(16);not null"`
+	TargetId    string    `gorm:"column:target_id;type:char(26);not null"`
+	ParentId    string    `gorm:"column:parent_id;type:char(26);not null"`
+	Path        string    `gorm:"column:path;type:varchar(4096);not null"`
+	DeletedTime time.Time `gorm:"column:deleted_time;not null;index"`
+}
+
+func (item *TrashItem) Name() string {
+	_, name := splitFolderPath(item.Path)
+	return name
+}
+
+// RestoreTrashItem checks item can go back to parent, its original parent folder,
+// which is nil when the folder doesn't exist any more.
+// The child of parent with the name of item must be loaded if it exists.
+func (dir *Folder) RestoreTrashItem(parent *Folder, item *TrashItem, restoredTime time.Time) error {
+	if parent == nil || parent.Id != item.ParentId {
+		return fmt.Errorf("Error: The parent of %v %w", item.Path, ErrFolderNotExists)
+	}
+
+	switch item.Kind {
+	case TrashKind_Folder:
+		_, ok := parent.findChildFolder(item.Name())
+		if ok {
+			return fmt.Errorf("Error: The %v %w", item.Path, ErrFolderExists)
+		}
+	case TrashKind_File:
+		_, ok := parent.findChildFile(item.Name())
+		if ok {
+			return fmt.Errorf("Error: The %v %w", item.Path, ErrFileExists)
+		}
+	}
+
+	name := EventName_FolderRestored
+	if item.Kind == TrashKind_File {
+		name = EventName_FileRestored
+	}
+	dir.raise(newEvent(name, item.FsId, item.TargetId, item.ParentId, item.Path, "", restoredTime))
+	return nil
+}
+
+func (dir *Folder) findFolderById(id string) (*Folder, bool) {
+	var target *Folder
+	dir.Walk(func(folder *Folder) {
+		if folder.Id == id {
+			target = folder
+		}
+	})
+	return target, target != nil
+}
+
+// findTrashItem looks up target by id first, then by path.
+// The latest deleted item wins when several items have the same path.
+func findTrashItem(items []*TrashItem, target string) (*TrashItem, error) {
+	for _, item := range items {
+		if item.Id == target {
+			return item, nil
+		}
+	}
+
+	path := cleanPath(target)
+	var found *TrashItem
+	for _, item := range items {
+		if !strings.EqualFold(item.Path, path) {
+			continue
+		}
+		if found == nil || item.DeletedTime.After(found.DeletedTime) {
+			found = item
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("Error: The %v %w", target, ErrTrashItemNotExists)
+	}
+	return found, nil
+}
+
+func expiredTrashItems(items []*TrashItem, now time.Time, olderThan time.Duration) []*TrashItem {
+	deadline := now.Add(-olderThan)
+	var expired []*TrashItem
+	for _, item := range items {
+		if !item.DeletedTime.After(deadline) {
+			expired = append(expired, item)
+		}
+	}
+	return expired
+}
+
+func sortTrashItems(items []*TrashItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedTime.After(items[j].DeletedTime)
+	})
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type TrashService interface {
+	ListTrash(ctx context.Context, username string) ([]ViewTrashItem, error)
+	RestoreTrash(ctx context.Context, username string, params RestoreTrashParams) (ViewTrashItem, error)
+	EmptyTrash(ctx context.Context, username string, params EmptyTrashParams) (int, error)
+}
+
+func NewTrashUseCase(uow UnitOfWork, fsRepo FileSystemRepository, auditRepo AuditRepository) *TrashUseCase {
+	return &TrashUseCase{
+		Uow:       uow,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
+	}
+}
+
+type TrashUseCase struct {
+	Uow       UnitOfWork
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
+}
+
+func (uc *TrashUseCase) ListTrash(ctx context.Context, username string) ([]ViewTrashItem, error) {
+	var response []ViewTrashItem
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, pathSeparator, Permission_Owner)
+		if err != nil {
+			return err
+		}
+
+		items, err := uc.FsRepo.ListTrashItems(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return ErrListTrashEmpty
+		}
+
+		sortTrashItems(items)
+		response = make([]ViewTrashItem, len(items))
+		for i, item := range items {
+			response[i] = ToViewTrashItem(item, username)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (uc *TrashUseCase) RestoreTrash(ctx context.Context, username string, params RestoreTrashParams) (ViewTrashItem, error) {
+	var response ViewTrashItem
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, pathSeparator, Permission_Owner)
+		if err != nil {
+			return err
+		}
+
+		items, err := uc.FsRepo.ListTrashItems(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+
+		item, err := findTrashItem(items, params.Target)
+		if err != nil {
+			return err
+		}
+
+		parent, err := uc.loadParent(ctx, fs, item)
+		if err != nil {
+			return err
+		}
+
+		err = fs.Root.RestoreTrashItem(parent, item, params.RestoredTime)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.RestoreTrashItem(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_RestoreTrash, item.TargetId, item.ParentId, "", item.Path, params.RestoredTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		collectEvents(ctx, fs)
+
+		response = ToViewTrashItem(item, username)
+		return nil
+	})
+	if err != nil {
+		return ViewTrashItem{}, err
+	}
+
+	return response, nil
+}
+
+// loadParent loads the live parent folder of item by id, because its path may have been renamed,
+// and the child of the parent which has the name of item.
+// The parent is nil when it doesn't exist any more.
+func (uc *TrashUseCase) loadParent(ctx context.Context, fs *FileSystem, item *TrashItem) (*Folder, error) {
+	parent := &fs.Root
+	if item.ParentId != fs.Root.Id {
+		var err error
+		parent, err = uc.FsRepo.FindFolder(ctx, fs.Id, item.ParentId)
+		if errors.Is(err, ErrFolderNotExists) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch item.Kind {
+	case TrashKind_Folder:
+		_, err := loadFolder(ctx, uc.FsRepo, parent, item.Name())
+		return parent, err
+	default:
+		return parent, loadChildFile(ctx, uc.FsRepo, parent, pathSeparator, item.Name())
+	}
+}
+
+func (uc *TrashUseCase) EmptyTrash(ctx context.Context, username string, params EmptyTrashParams) (int, error) {
+	if params.OlderThan < 0 {
+		return 0, fmt.Errorf("Error: The older-than %v %w", params.OlderThan, ErrInvalidParams)
+	}
+
+	var count int
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.FsRepo.FindFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorize(ctx, uc.FsRepo, fs, pathSeparator, Permission_Owner)
+		if err != nil {
+			return err
+		}
+
+		items, err := uc.FsRepo.ListTrashItems(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+
+		expired := expiredTrashItems(items, params.Now, params.OlderThan)
+		if len(expired) == 0 {
+			return nil
+		}
+
+		err = uc.FsRepo.PurgeTrashItems(ctx, expired)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range expired {
+			entry := newAuditEntry(ctx, fs, AuditAction_EmptyTrash, item.TargetId, item.ParentId, item.Path, "", params.Now)
+			err = uc.AuditRepo.AppendAudit(ctx, entry)
+			if err != nil {
+				return err
+			}
+		}
+
+		count = len(expired)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+func TestFolder_RestoreTrashItem(t *testing.T) {
+	deletedTime := pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now()
+
+	tests := []struct {
+		name    string
+		prepare func(fs *FileSystem) *TrashItem
+		wantErr error
+	}{
+		{
+			name: "success folder",
+			prepare: func(fs *FileSystem) *TrashItem {
+				_, item, _ := fs.Root.DeleteFolder(DeleteFolderParams{
+					Foldername:  "/home/dev",
+					DeletedTime: deletedTime,
+				})
+				if item.Path != "/home/dev" || item.Kind != TrashKind_Folder {
+					t.Errorf("DeleteFolder() item=%v %v", item.Kind, item.Path)
+				}
+				return item
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success file",
+			prepare: func(fs *FileSystem) *TrashItem {
+				_, item, _ := fs.Root.DeleteFile(DeleteFileParams{
+					Foldername:  "home",
+					Filename:    "qa.conf",
+					DeletedTime: deletedTime,
+				})
+				if item.Path != "/home/qa.conf" || item.Kind != TrashKind_File {
+					t.Errorf("DeleteFile() item=%v %v", item.Kind, item.Path)
+				}
+				return item
+			},
+			wantErr: nil,
+		},
+		{
+			name: "The [foldername] has already existed.",
+			prepare: func(fs *FileSystem) *TrashItem {
+				_, item, _ := fs.Root.DeleteFolder(DeleteFolderParams{
+					Foldername:  "/tmp",
+					DeletedTime: deletedTime,
+				})
+				fs.Root.CreateFolder(CreateFolderParams{
+					Foldername:  "/TMP",
+					CreatedTime: deletedTime,
+				})
+				return item
+			},
+			wantErr: ErrFolderExists,
+		},
+		{
+			name: "The parent folder doesn't exist.",
+			prepare: func(fs *FileSystem) *TrashItem {
+				_, item, _ := fs.Root.DeleteFile(DeleteFileParams{
+					Foldername:  "/home",
+					Filename:    "dev.conf",
+					DeletedTime: deletedTime,
+				})
+				fs.Root.DeleteFolder(DeleteFolderParams{
+					Foldername:  "/home",
+					DeletedTime: deletedTime,
+				})
+				return item
+			},
+			wantErr: ErrFolderNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fs := testFileSystem()
+			item := tt.prepare(fs)
+			parent, _ := fs.Root.findFolderById(item.ParentId)
+			err := fs.Root.RestoreTrashItem(parent, item, deletedTime)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RestoreTrashItem() error=%v, want=%v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_findTrashItem(t *testing.T) {
+	now := pkg.NewMockTimeFunc("2024-05-27T12:00:00+08:00").Now()
+	items := []*TrashItem{
+		{Id: "01HYXCD1CD3VFFRYB9BWV19TM8", Path: "/home/dev", DeletedTime: now.Add(-time.Hour)},
+		{Id: "01HYXCD1CGB36V08CNRGJQMZHT", Path: "/home/dev", DeletedTime: now},
+		{Id: "01HYXD0GV43XKBZ7Y1YDK7QDBQ", Path: "/tmp", DeletedTime: now},
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		wantId  string
+		wantErr error
+	}{
+		{
+			name:   "by id",
+			target: "01HYXCD1CD3VFFRYB9BWV19TM8",
+			wantId: "01HYXCD1CD3VFFRYB9BWV19TM8",
+		},
+		{
+			name:   "by path, the latest deleted item wins",
+			target: "home/Dev/",
+			wantId: "01HYXCD1CGB36V08CNRGJQMZHT",
+		},
+		{
+			name:    "The [id|path] doesn't exist.",
+			target:  "/etc",
+			wantErr: ErrTrashItemNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := findTrashItem(items, tt.target)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("findTrashItem() error=%v, want=%v", err, tt.wantErr)
+				return
+			}
+			if item != nil && item.Id != tt.wantId {
+				t.Errorf("findTrashItem() id=%v, want=%v", item.Id, tt.wantId)
+			}
+		})
+	}
+
+	expired := expiredTrashItems(items, now, 30*time.Minute)
+	if len(expired) != 1 || expired[0].Id != "01HYXCD1CD3VFFRYB9BWV19TM8" {
+		t.Errorf("expiredTrashItems() len=%v, want=%v", len(expired), 1)
+	}
+}
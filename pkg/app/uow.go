@@ -0,0 +1,12 @@
+package app
+
+import (
+	"context"
+)
+
+// UnitOfWork runs fn atomically.
+// Repositories join the transaction through the ctx passed to fn,
+// so fn must use that ctx instead of the outer one.
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,19 +2,104 @@ package app
 
 import (
 	"fmt"
+	"time"
 	"unicode"
 )
 
-func newUser(username string) (*User, error) {
+func newUser(username string, createdTime time.Time) (*User, error) {
 	err := validateUsername(username)
 	if err != nil {
 		return nil, err
 	}
-	return &User{Username: username}, nil
+	return &User{Username: username, CreatedTime: createdTime, Role: Role_User}, nil
 }
 
 type User struct {
-	Username string `gorm:"column:username;type:varchar(64);not null;primaryKey"`
+	Username    string    `gorm:"column:username;type:varchar(64);not null;primaryKey"`
+	CreatedTime time.Time `gorm:"column:created_time;not null"`
+	Quota       Quota     `gorm:"embedded"`
+
+	// PasswordHash is the bcrypt hash of the password, empty means the user doesn't have a password.
+	PasswordHash string `gorm:"column:password_hash;type:varchar(72);not null;default:''"`
+
+	// Role is Role_Admin for the users who manage the quotas and the other users.
+	Role Role `gorm:"column:role;type:varchar(16);not null;default:'user'"`
+}
+
+// Role decides what a user may do beside using its own account,
+// the first user registered while there are no admins becomes the admin.
+type Role string
+
+const (
+	Role_User  Role = "user"
+	Role_Admin Role = "admin"
+)
+
+func parseRole(role string) (Role, error) {
+	switch Role(role) {
+	case Role_User, Role_Admin:
+		return Role(role), nil
+	default:
+		return "", fmt.Errorf("Error: The role %v %w", role, ErrInvalidParams)
+	}
+}
+
+func (user *User) IsAdmin() bool {
+	return user.Role == Role_Admin
+}
+
+// Quota limits what the FileSystem of a user holds, a zero limit is unlimited.
+// The folders and files in the trash still count until the trash is emptied,
+// since they still take up the database.
+type Quota struct {
+	MaxFolders int   `gorm:"column:max_folders;not null;default:0"`
+	MaxFiles   int   `gorm:"column:max_files;not null;default:0"`
+	MaxBytes   int64 `gorm:"column:max_bytes;not null;default:0"`
+}
+
+func (q Quota) IsUnlimited() bool {
+	return q.MaxFolders == 0 && q.MaxFiles == 0 && q.MaxBytes == 0
+}
+
+// Check reports ErrQuotaExceeded when usage grown by delta goes beyond a limit.
+// Only the growing parts are checked, so a user over a lowered limit can still shrink.
+func (q Quota) Check(username string, usage FolderUsage, delta FolderUsage) error {
+	if q.MaxFolders > 0 && delta.Folders > 0 && usage.Folders+delta.Folders > q.MaxFolders {
+		return fmt.Errorf("Error: The %v %w of %v folders.", username, ErrQuotaExceeded, q.MaxFolders)
+	}
+	if q.MaxFiles > 0 && delta.Files > 0 && usage.Files+delta.Files > q.MaxFiles {
+		return fmt.Errorf("Error: The %v %w of %v files.", username, ErrQuotaExceeded, q.MaxFiles)
+	}
+	if q.MaxBytes > 0 && delta.Size > 0 && usage.Size+delta.Size > q.MaxBytes {
+		return fmt.Errorf("Error: The %v %w of %v bytes.", username, ErrQuotaExceeded, q.MaxBytes)
+	}
+	return nil
+}
+
+func (user *User) SetQuota(params SetQuotaParams) error {
+	quota := user.Quota
+	if params.MaxFolders != nil {
+		quota.MaxFolders = *params.MaxFolders
+	}
+	if params.MaxFiles != nil {
+		quota.MaxFiles = *params.MaxFiles
+	}
+	if params.MaxBytes != nil {
+		quota.MaxBytes = *params.MaxBytes
+	}
+
+	if quota.MaxFolders < 0 {
+		return fmt.Errorf("Error: The max-folders %v %w", quota.MaxFolders, ErrInvalidParams)
+	}
+	if quota.MaxFiles < 0 {
+		return fmt.Errorf("Error: The max-files %v %w", quota.MaxFiles, ErrInvalidParams)
+	}
+	if quota.MaxBytes < 0 {
+		return fmt.Errorf("Error: The max-bytes %v %w", quota.MaxBytes, ErrInvalidParams)
+	}
+
+	user.Quota = quota
+	return nil
 }
 
 func validateUsername(username string) error {
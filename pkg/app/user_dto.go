@@ -0,0 +1,109 @@
+package app
+
+import (
+	"time"
+)
+
+type RegisterParams struct {
+	Username    string `validate:"required,username"`
+	Password    string `validate:"required"`
+	CreatedTime time.Time
+}
+
+type RenameUserParams struct {
+	Username    string `validate:"required,username"`
+	NewUsername string `validate:"required,username"`
+}
+
+// SetQuotaParams changes the limits which aren't nil, zero means unlimited.
+type SetQuotaParams struct {
+	Username   string `validate:"required,username"`
+	MaxFolders *int
+	MaxFiles   *int
+	MaxBytes   *int64
+}
+
+type SetRoleParams struct {
+	Username string `validate:"required,username"`
+	Role     string `validate:"required"`
+}
+
+type SetPasswordParams struct {
+	Username string `validate:"required,username"`
+	Password string `validate:"required"`
+}
+
+type LoginParams struct {
+	Username  string `validate:"required,username"`
+	Password  string `validate:"required"`
+	LoginTime time.Time
+}
+
+func ToViewSession(token string, session *Session) ViewSession {
+	return ViewSession{
+		Token:       token,
+		Username:    session.Username,
+		ExpiredTime: session.ExpiredTime,
+	}
+}
+
+// ViewSession is returned by login only, since the token isn't stored.
+type ViewSession struct {
+	Token       string    `json:"token"`
+	Username    string    `json:"username"`
+	ExpiredTime time.Time `json:"expired_time"`
+}
+
+func ToViewUser(user *User) ViewUser {
+	return ViewUser{
+		Username:    user.Username,
+		CreatedTime: user.CreatedTime,
+	}
+}
+
+type ViewUser struct {
+	Username    string    `json:"username"`
+	CreatedTime time.Time `json:"created_time"`
+}
+
+// ToViewUserInfo shows the live folders and files of user, the trash excluded.
+func ToViewUserInfo(user *User, usage FolderUsage) ViewUserInfo {
+	return ViewUserInfo{
+		Username:    user.Username,
+		Role:        string(user.Role),
+		Folders:     usage.Folders,
+		Files:       usage.Files,
+		CreatedTime: user.CreatedTime,
+	}
+}
+
+type ViewUserInfo struct {
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	Folders     int       `json:"folders"`
+	Files       int       `json:"files"`
+	CreatedTime time.Time `json:"created_time"`
+}
+
+func ToViewQuota(user *User, usage FolderUsage) ViewQuota {
+	return ViewQuota{
+		Username:   user.Username,
+		MaxFolders: user.Quota.MaxFolders,
+		MaxFiles:   user.Quota.MaxFiles,
+		MaxBytes:   user.Quota.MaxBytes,
+		Folders:    usage.Folders,
+		Files:      usage.Files,
+		Size:       usage.Size,
+	}
+}
+
+// ViewQuota shows the limits beside the usage, the trash included.
+type ViewQuota struct {
+	Username   string `json:"username"`
+	MaxFolders int    `json:"max_folders"`
+	MaxFiles   int    `json:"max_files"`
+	MaxBytes   int64  `json:"max_bytes"`
+	Folders    int    `json:"folders"`
+	Files      int    `json:"files"`
+	Size       int64  `json:"size"`
+}
@@ -5,55 +5,540 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
 )
 
 type UserService interface {
-	Register(ctx context.Context, username string, created time.Time) error
+	// Register creates the user with its password, so nobody else can claim the account before it is set.
+	Register(ctx context.Context, params RegisterParams) error
+	ListUsers(ctx context.Context) ([]ViewUser, error)
+	DeleteUser(ctx context.Context, username string) error
+	RenameUser(ctx context.Context, params RenameUserParams) error
+	GetUserInfo(ctx context.Context, username string) (ViewUserInfo, error)
+	// SetQuota is done by an admin only, so a user can't lift its own limits.
+	SetQuota(ctx context.Context, params SetQuotaParams) error
+	GetQuota(ctx context.Context, username string) (ViewQuota, error)
+
+	// SetRole is done by an admin only, and keeps at least one admin.
+	SetRole(ctx context.Context, params SetRoleParams) error
+}
+
+type AuthService interface {
+	// SetPassword changes the password of the user, the user itself or an admin must login first.
+	SetPassword(ctx context.Context, params SetPasswordParams) error
+	Login(ctx context.Context, params LoginParams) (ViewSession, error)
+	Logout(ctx context.Context, token string) error
+
+	// Authenticate returns the Principal of the session which token identifies,
+	// the adapters put it into the context with ContextWithPrincipal.
+	Authenticate(ctx context.Context, token string, now time.Time) (Principal, error)
 }
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) error
 	QueryUserByName(ctx context.Context, username string) (*User, error)
+	ListUsers(ctx context.Context) ([]*User, error)
+	DeleteUser(ctx context.Context, user *User) error
+
+	// RenameUser changes the username of the user and its FileSystem.
+	RenameUser(ctx context.Context, user *User, newUsername string) error
+	UpdateQuota(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, user *User) error
+	UpdateRole(ctx context.Context, user *User) error
+	CountAdmins(ctx context.Context) (int, error)
+
+	CreateSession(ctx context.Context, session *Session) error
+	FindSession(ctx context.Context, tokenHash string) (*Session, error)
+	DeleteSession(ctx context.Context, session *Session) error
 }
 
-func NewUserUseCase(userRepo UserRepository, fsRepo FileSystemRepository) *UserUseCase {
+func NewUserUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *UserUseCase {
 	return &UserUseCase{
-		UserRepo: userRepo,
-		FsRepo:   fsRepo,
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
+		Time:      pkg.NewTimeFunc(),
 	}
 }
 
 type UserUseCase struct {
-	UserRepo UserRepository
-	FsRepo   FileSystemRepository
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
+
+	// Time stamps the audit entries of the use cases which don't take a time.
+	Time pkg.TimeFunc
 }
 
-func (uc *UserUseCase) Register(ctx context.Context, username string, created time.Time) error {
-	user, err := newUser(username)
+func (uc *UserUseCase) Register(ctx context.Context, params RegisterParams) error {
+	user, err := newUser(params.Username, params.CreatedTime)
 	if err != nil {
 		return err
 	}
 
-	_, err = uc.UserRepo.QueryUserByName(ctx, user.Username)
-	if err == nil {
-		return fmt.Errorf("Error: The %v %w", user.Username, ErrUserExists)
+	err = user.SetPassword(params.Password)
+	if err != nil {
+		return err
 	}
 
-	if !errors.Is(err, ErrUserNotExists) {
-		return err
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		_, err := uc.UserRepo.QueryUserByName(ctx, user.Username)
+		if err == nil {
+			return fmt.Errorf("Error: The %v %w", user.Username, ErrUserExists)
+		}
+
+		if !errors.Is(err, ErrUserNotExists) {
+			return err
+		}
+
+		admins, err := uc.UserRepo.CountAdmins(ctx)
+		if err != nil {
+			return err
+		}
+		if admins == 0 {
+			user.Role = Role_Admin
+		}
+
+		err = uc.UserRepo.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		fs := newFileSystem(user.Username, params.CreatedTime)
+
+		err = uc.FsRepo.CreateFileSystem(ctx, fs)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_Register, fs.Id, "", "", user.Username, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *UserUseCase) ListUsers(ctx context.Context) ([]ViewUser, error) {
+	_, err := requirePrincipal(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	err = uc.UserRepo.CreateUser(ctx, user)
+	var response []ViewUser
+	err = uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		users, err := uc.UserRepo.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(users) == 0 {
+			return ErrListUserEmpty
+		}
+
+		response = make([]ViewUser, len(users))
+		for i, user := range users {
+			response[i] = ToViewUser(user)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (uc *UserUseCase) DeleteUser(ctx context.Context, username string) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		err = uc.checkLastAdmin(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		err = uc.FsRepo.DeleteFileSystem(ctx, fs)
+		if err != nil {
+			return err
+		}
+
+		err = uc.UserRepo.DeleteUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_DeleteUser, fs.Id, "", user.Username, "", uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *UserUseCase) RenameUser(ctx context.Context, params RenameUserParams) error {
+	err := validateUsername(params.NewUsername)
 	if err != nil {
 		return err
 	}
 
-	fs := newFileSystem(user.Username, created)
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, params.Username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		// only changing the letter case of the username is allowed
+		other, err := uc.UserRepo.QueryUserByName(ctx, params.NewUsername)
+		if err == nil && other.Username != user.Username {
+			return fmt.Errorf("Error: The %v %w", params.NewUsername, ErrUserExists)
+		}
+
+		if err != nil && !errors.Is(err, ErrUserNotExists) {
+			return err
+		}
+
+		oldUsername := user.Username
+		err = uc.UserRepo.RenameUser(ctx, user, params.NewUsername)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, params.NewUsername)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_RenameUser, fs.Id, "", oldUsername, params.NewUsername, uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *UserUseCase) GetUserInfo(ctx context.Context, username string) (ViewUserInfo, error) {
+	var response ViewUserInfo
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		usage, err := uc.FsRepo.SumFolder(ctx, &fs.Root)
+		if err != nil {
+			return err
+		}
 
-	err = uc.FsRepo.CreateFileSystem(ctx, fs)
+		response = ToViewUserInfo(user, usage)
+		return nil
+	})
+	if err != nil {
+		return ViewUserInfo{}, err
+	}
+
+	return response, nil
+}
+
+func (uc *UserUseCase) SetQuota(ctx context.Context, params SetQuotaParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, params.Username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeAdmin(ctx, uc.UserRepo, "set the quota of "+user.Username)
+		if err != nil {
+			return err
+		}
+
+		before := auditQuota(user.Quota)
+		err = user.SetQuota(params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.UserRepo.UpdateQuota(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_SetQuota, fs.Id, "", before, auditQuota(user.Quota), uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *UserUseCase) GetQuota(ctx context.Context, username string) (ViewQuota, error) {
+	var response ViewQuota
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		usage, err := uc.FsRepo.SumFileSystem(ctx, fs)
+		if err != nil {
+			return err
+		}
+
+		response = ToViewQuota(user, usage)
+		return nil
+	})
+	if err != nil {
+		return ViewQuota{}, err
+	}
+
+	return response, nil
+}
+
+func (uc *UserUseCase) SetRole(ctx context.Context, params SetRoleParams) error {
+	role, err := parseRole(params.Role)
 	if err != nil {
 		return err
 	}
 
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, params.Username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeAdmin(ctx, uc.UserRepo, "set the role of "+user.Username)
+		if err != nil {
+			return err
+		}
+
+		if role == Role_User {
+			err = uc.checkLastAdmin(ctx, user)
+			if err != nil {
+				return err
+			}
+		}
+
+		before := user.Role
+		user.Role = role
+		err = uc.UserRepo.UpdateRole(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_SetRole, fs.Id, "", string(before), string(user.Role), uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// checkLastAdmin reports ErrLastAdmin when user is the only admin,
+// since nobody could set the quotas or the roles without it.
+func (uc *UserUseCase) checkLastAdmin(ctx context.Context, user *User) error {
+	if !user.IsAdmin() {
+		return nil
+	}
+
+	admins, err := uc.UserRepo.CountAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return fmt.Errorf("Error: The %v %w", user.Username, ErrLastAdmin)
+	}
 	return nil
 }
+
+func NewAuthUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, auditRepo AuditRepository) *AuthUseCase {
+	return &AuthUseCase{
+		Uow:       uow,
+		UserRepo:  userRepo,
+		FsRepo:    fsRepo,
+		AuditRepo: auditRepo,
+		Time:      pkg.NewTimeFunc(),
+	}
+}
+
+type AuthUseCase struct {
+	Uow       UnitOfWork
+	UserRepo  UserRepository
+	FsRepo    FileSystemRepository
+	AuditRepo AuditRepository
+
+	// Time stamps the audit entries of set-password, which doesn't take a time.
+	Time pkg.TimeFunc
+}
+
+func (uc *AuthUseCase) SetPassword(ctx context.Context, params SetPasswordParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, params.Username)
+		if err != nil {
+			return err
+		}
+
+		err = authorizeUser(ctx, uc.UserRepo, user)
+		if err != nil {
+			return err
+		}
+
+		err = user.SetPassword(params.Password)
+		if err != nil {
+			return err
+		}
+
+		err = uc.UserRepo.UpdatePassword(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		fs, err := uc.FsRepo.FindFileSystem(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_SetPassword, fs.Id, "", "", "", uc.Time.Now())
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *AuthUseCase) Login(ctx context.Context, params LoginParams) (ViewSession, error) {
+	var response ViewSession
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		user, err := uc.UserRepo.QueryUserByName(ctx, params.Username)
+		if errors.Is(err, ErrUserNotExists) {
+			// the same as a wrong password, so login doesn't tell which users exist
+			return ErrIncorrectPassword
+		}
+		if err != nil {
+			return err
+		}
+
+		err = user.VerifyPassword(params.Password)
+		if err != nil {
+			return err
+		}
+
+		token, session, err := newSession(user.Username, params.LoginTime)
+		if err != nil {
+			return err
+		}
+
+		err = uc.UserRepo.CreateSession(ctx, session)
+		if err != nil {
+			return err
+		}
+
+		response = ToViewSession(token, session)
+		return nil
+	})
+	if err != nil {
+		return ViewSession{}, err
+	}
+
+	return response, nil
+}
+
+func (uc *AuthUseCase) Logout(ctx context.Context, token string) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		session, err := uc.UserRepo.FindSession(ctx, hashToken(token))
+		if err != nil {
+			return err
+		}
+
+		err = uc.UserRepo.DeleteSession(ctx, session)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (uc *AuthUseCase) Authenticate(ctx context.Context, token string, now time.Time) (Principal, error) {
+	var principal Principal
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		session, err := uc.UserRepo.FindSession(ctx, hashToken(token))
+		if err != nil {
+			return err
+		}
+
+		if session.IsExpired(now) {
+			return fmt.Errorf("Error: The session %w", ErrSessionNotExists)
+		}
+
+		principal = Principal{Username: session.Username}
+		return nil
+	})
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return principal, nil
+}
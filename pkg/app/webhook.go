@@ -0,0 +1,155 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+const (
+	WebhookEventHeader     = "X-VFS-Event"
+	WebhookDeliveryHeader  = "X-VFS-Delivery"
+	WebhookSignatureHeader = "X-VFS-Signature"
+)
+
+// ParseEventNames accepts the names of EventNames, no names means all events.
+func ParseEventNames(names []string) ([]EventName, error) {
+	events := make([]EventName, 0, len(names))
+	for _, name := range names {
+		event := EventName(strings.ToLower(name))
+		if !slices.Contains(EventNames, event) {
+			return nil, fmt.Errorf("Error: The event %v %w", name, ErrInvalidParams)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func validateWebhookUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(rawUrl) > 2048 {
+		return fmt.Errorf("Error: The url %v %w", rawUrl, ErrInvalidParams)
+	}
+	return nil
+}
+
+func newWebhook(fs *FileSystem, params AddWebhookParams) (*Webhook, error) {
+	err := validateWebhookUrl(params.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]string, len(params.Events))
+	for i, event := range params.Events {
+		events[i] = string(event)
+	}
+
+	secret := make([]byte, 32)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Webhook{
+		Id:          pkg.NewUlid(),
+		FsId:        fs.Id,
+		Url:         params.Url,
+		Events:      strings.Join(events, ","),
+		Secret:      hex.EncodeToString(secret),
+		CreatedTime: params.CreatedTime,
+	}, nil
+}
+
+// Webhook posts the events of a FileSystem to Url, the payloads are signed by Secret.
+// Events is a comma separated list of EventName, an empty Events subscribes to all events.
+type Webhook struct {
+	Id          string    `gorm:"column:id;type:char(26);not null;primaryKey"`
+	FsId        string    `gorm:"column:fs_id;type:char(26);not null;index"`
+	Url         string    `gorm:"column:url;type:varchar(2048);not null"`
+	Events      string    `gorm:"column:events;type:varchar(512);not null"`
+	Secret      string    `gorm:"column:secret;type:char(64);not null"`
+	CreatedTime time.Time `gorm:"column:created_time;not null"`
+}
+
+func (hook *Webhook) EventNames() []EventName {
+	if hook.Events == "" {
+		return nil
+	}
+
+	var names []EventName
+	for _, name := range strings.Split(hook.Events, ",") {
+		names = append(names, EventName(name))
+	}
+	return names
+}
+
+func (hook *Webhook) Accepts(name EventName) bool {
+	names := hook.EventNames()
+	return len(names) == 0 || slices.Contains(names, name)
+}
+
+// WebhookPayload is the JSON body posted to a webhook.
+type WebhookPayload struct {
+	Id           string    `json:"id"`
+	Event        EventName `json:"event"`
+	Username     string    `json:"username"`
+	Actor        string    `json:"actor"`
+	TargetId     string    `json:"target_id"`
+	ParentId     string    `json:"parent_id"`
+	Path         string    `json:"path"`
+	OldPath      string    `json:"old_path,omitempty"`
+	OccurredTime time.Time `json:"occurred_time"`
+}
+
+func newWebhookPayload(event Event) WebhookPayload {
+	return WebhookPayload{
+		Id:           event.Id,
+		Event:        event.Name,
+		Username:     event.Username,
+		Actor:        event.Actor,
+		TargetId:     event.TargetId,
+		ParentId:     event.ParentId,
+		Path:         event.Path,
+		OldPath:      event.OldPath,
+		OccurredTime: event.OccurredTime,
+	}
+}
+
+// SignWebhook returns the value of WebhookSignatureHeader,
+// which is "sha256=" followed by the hex HMAC-SHA256 of body keyed by secret.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook lets a receiver check body is sent by vFS, in constant time.
+func VerifyWebhook(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, body)), []byte(signature))
+}
+
+// WebhookDelivery records how an event was posted to a webhook, an empty Error means it succeeded.
+// A failed delivery is retried by the outbox, so an event may have several deliveries,
+// Attempts counts them for the webhook.
+type WebhookDelivery struct {
+	Id            string    `gorm:"column:id;type:char(26);not null;primaryKey"`
+	WebhookId     string    `gorm:"column:webhook_id;type:char(26);not null"`
+	FsId          string    `gorm:"column:fs_id;type:char(26);not null"`
+	EventId       string    `gorm:"column:event_id;type:char(26);not null"`
+	EventName     EventName `gorm:"column:event_name;type:varchar(32);not null"`
+	Url           string    `gorm:"column:url;type:varchar(2048);not null"`
+	Attempts      int       `gorm:"column:attempts;not null"`
+	StatusCode    int       `gorm:"column:status_code;not null"`
+	Error         string    `gorm:"column:error_message;type:varchar(1024);not null"`
+	DeliveredTime time.Time `gorm:"column:delivered_time;not null"`
+}
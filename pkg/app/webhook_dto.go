@@ -0,0 +1,66 @@
+package app
+
+import (
+	"time"
+)
+
+// AddWebhookParams subscribes Url to Events, no Events means all events.
+type AddWebhookParams struct {
+	Url         string `validate:"required"`
+	Events      []EventName
+	CreatedTime time.Time
+}
+
+type RemoveWebhookParams struct {
+	Id string `validate:"required"`
+}
+
+// ToViewWebhook leaves out the secret, which is only returned once by AddWebhook.
+func ToViewWebhook(hook *Webhook, username string) ViewWebhook {
+	events := []string{}
+	for _, name := range hook.EventNames() {
+		events = append(events, string(name))
+	}
+	return ViewWebhook{
+		Id:          hook.Id,
+		Url:         hook.Url,
+		Events:      events,
+		CreatedTime: hook.CreatedTime,
+		Username:    username,
+	}
+}
+
+type ViewWebhook struct {
+	Id          string    `json:"id"`
+	Url         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedTime time.Time `json:"created_time"`
+	Username    string    `json:"username"`
+}
+
+func ToViewWebhookDelivery(delivery *WebhookDelivery) ViewWebhookDelivery {
+	return ViewWebhookDelivery{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		EventId:       delivery.EventId,
+		Event:         string(delivery.EventName),
+		Url:           delivery.Url,
+		Attempts:      delivery.Attempts,
+		StatusCode:    delivery.StatusCode,
+		Error:         delivery.Error,
+		DeliveredTime: delivery.DeliveredTime,
+	}
+}
+
+type ViewWebhookDelivery struct {
+	Id            string    `json:"id"`
+	WebhookId     string    `json:"webhook_id"`
+	EventId       string    `json:"event_id"`
+	Event         string    `json:"event"`
+	Url           string    `json:"url"`
+	Attempts      int       `json:"attempts"`
+	StatusCode    int       `json:"status_code"`
+	Error         string    `json:"error"`
+	DeliveredTime time.Time `json:"delivered_time"`
+}
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg"
+)
+
+type WebhookService interface {
+	// AddWebhook returns the webhook with its secret, the secret isn't returned again.
+	AddWebhook(ctx context.Context, username string, params AddWebhookParams) (ViewWebhook, error)
+	ListWebhooks(ctx context.Context, username string) ([]ViewWebhook, error)
+	RemoveWebhook(ctx context.Context, username string, params RemoveWebhookParams) error
+	// ListDeliveries returns the delivery log of the webhooks of the user, in the order they were delivered.
+	ListDeliveries(ctx context.Context, username string) ([]ViewWebhookDelivery, error)
+}
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, hook *Webhook) error
+	ListWebhooks(ctx context.Context, fsId string) ([]*Webhook, error)
+	DeleteWebhook(ctx context.Context, hook *Webhook) error
+
+	AppendDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(ctx context.Context, fsId string) ([]*WebhookDelivery, error)
+	// CountDeliveries returns how many times the event has been posted to the webhook,
+	// and whether one of them succeeded.
+	CountDeliveries(ctx context.Context, webhookId string, eventId string) (attempts int, delivered bool, err error)
+}
+
+// WebhookSender posts body to url with header, and returns the status code of the response.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error)
+}
+
+// NewWebhookUseCase subscribes the use case to bus, so it delivers all events to the webhooks.
+func NewWebhookUseCase(uow UnitOfWork, userRepo UserRepository, fsRepo FileSystemRepository, webhookRepo WebhookRepository, auditRepo AuditRepository, bus *EventBus, sender WebhookSender) *WebhookUseCase {
+	uc := &WebhookUseCase{
+		Uow:         uow,
+		UserRepo:    userRepo,
+		FsRepo:      fsRepo,
+		WebhookRepo: webhookRepo,
+		AuditRepo:   auditRepo,
+		Sender:      sender,
+		Time:        pkg.NewTimeFunc(),
+	}
+	bus.Subscribe(uc.Deliver)
+	return uc
+}
+
+type WebhookUseCase struct {
+	Uow         UnitOfWork
+	UserRepo    UserRepository
+	FsRepo      FileSystemRepository
+	WebhookRepo WebhookRepository
+	AuditRepo   AuditRepository
+	Sender      WebhookSender
+
+	// Time stamps the deliveries and the removals of the webhooks.
+	Time pkg.TimeFunc
+}
+
+// findFileSystem returns the FileSystem of the user which the principal of ctx manages.
+func (uc *WebhookUseCase) findFileSystem(ctx context.Context, username string) (*FileSystem, error) {
+	user, err := uc.UserRepo.QueryUserByName(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorizeUser(ctx, uc.UserRepo, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.FsRepo.FindFileSystem(ctx, user.Username)
+}
+
+func (uc *WebhookUseCase) AddWebhook(ctx context.Context, username string, params AddWebhookParams) (ViewWebhook, error) {
+	var response ViewWebhook
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.findFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		hook, err := newWebhook(fs, params)
+		if err != nil {
+			return err
+		}
+
+		err = uc.WebhookRepo.CreateWebhook(ctx, hook)
+		if err != nil {
+			return err
+		}
+
+		entry := newAuditEntry(ctx, fs, AuditAction_AddWebhook, hook.Id, "", "", hook.Url, params.CreatedTime)
+		err = uc.AuditRepo.AppendAudit(ctx, entry)
+		if err != nil {
+			return err
+		}
+
+		response = ToViewWebhook(hook, fs.Username)
+		response.Secret = hook.Secret
+		return nil
+	})
+	if err != nil {
+		return ViewWebhook{}, err
+	}
+
+	return response, nil
+}
+
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context, username string) ([]ViewWebhook, error) {
+	var response []ViewWebhook
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.findFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		hooks, err := uc.WebhookRepo.ListWebhooks(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			return ErrListWebhookEmpty
+		}
+
+		response = make([]ViewWebhook, len(hooks))
+		for i, hook := range hooks {
+			response[i] = ToViewWebhook(hook, fs.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (uc *WebhookUseCase) RemoveWebhook(ctx context.Context, username string, params RemoveWebhookParams) error {
+	return uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.findFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		hooks, err := uc.WebhookRepo.ListWebhooks(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+
+		for _, hook := range hooks {
+			if hook.Id != params.Id {
+				continue
+			}
+
+			err = uc.WebhookRepo.DeleteWebhook(ctx, hook)
+			if err != nil {
+				return err
+			}
+
+			entry := newAuditEntry(ctx, fs, AuditAction_RemoveWebhook, hook.Id, "", hook.Url, "", uc.Time.Now())
+			return uc.AuditRepo.AppendAudit(ctx, entry)
+		}
+		return fmt.Errorf("Error: The webhook %v %w", params.Id, ErrWebhookNotExists)
+	})
+}
+
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, username string) ([]ViewWebhookDelivery, error) {
+	var response []ViewWebhookDelivery
+	err := uc.Uow.WithTx(ctx, func(ctx context.Context) error {
+		fs, err := uc.findFileSystem(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		deliveries, err := uc.WebhookRepo.ListDeliveries(ctx, fs.Id)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return ErrListDeliveryEmpty
+		}
+
+		response = make([]ViewWebhookDelivery, len(deliveries))
+		for i, delivery := range deliveries {
+			response[i] = ToViewWebhookDelivery(delivery)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Deliver is the EventHandler which posts event to the webhooks subscribed to it, once for each webhook.
+// The webhooks which have received the event are skipped,
+// so the outbox only retries the failed ones after its backoff, nobody waits in Deliver.
+func (uc *WebhookUseCase) Deliver(ctx context.Context, event Event) error {
+	hooks, err := uc.WebhookRepo.ListWebhooks(ctx, event.FsId)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, hook := range hooks {
+		if !hook.Accepts(event.Name) {
+			continue
+		}
+
+		attempts, delivered, err := uc.WebhookRepo.CountDeliveries(ctx, hook.Id, event.Id)
+		if err != nil {
+			return err
+		}
+		if delivered {
+			continue
+		}
+
+		delivery, err := uc.send(ctx, hook, event, attempts+1)
+		if err != nil {
+			return err
+		}
+
+		err = uc.WebhookRepo.AppendDelivery(ctx, delivery)
+		if err != nil {
+			return err
+		}
+		if delivery.Error != "" {
+			errs = append(errs, fmt.Errorf("Error: The webhook %v failed to receive %v: %v", hook.Id, event.Id, delivery.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts event to hook, the attempt-th time for the webhook.
+// A response other than 2xx is a failure.
+func (uc *WebhookUseCase) send(ctx context.Context, hook *Webhook, event Event, attempt int) (*WebhookDelivery, error) {
+	body, err := json.Marshal(newWebhookPayload(event))
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &WebhookDelivery{
+		Id:        pkg.NewUlid(),
+		WebhookId: hook.Id,
+		FsId:      hook.FsId,
+		EventId:   event.Id,
+		EventName: event.Name,
+		Url:       hook.Url,
+		Attempts:  attempt,
+	}
+	header := map[string]string{
+		"Content-Type":         "application/json",
+		WebhookEventHeader:     string(event.Name),
+		WebhookDeliveryHeader:  delivery.Id,
+		WebhookSignatureHeader: SignWebhook(hook.Secret, body),
+	}
+
+	delivery.StatusCode, err = uc.Sender.Send(ctx, hook.Url, header, body)
+	switch {
+	case err != nil:
+		delivery.Error = truncateError(err, 1024)
+	case delivery.StatusCode < 200 || delivery.StatusCode >= 300:
+		delivery.Error = fmt.Sprintf("status code %v", delivery.StatusCode)
+	}
+
+	delivery.DeliveredTime = uc.Time.Now()
+	return delivery, nil
+}
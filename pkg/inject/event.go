@@ -0,0 +1,20 @@
+package inject
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/memory"
+	"github.com/KScaesar/IsCoolLab2024/pkg/app"
+)
+
+// newDatabaseUnitOfWork lets the use cases store the events of their transactions in the outbox,
+// and relay them to bus after the commit.
+func newDatabaseUnitOfWork(db *gorm.DB, bus *app.EventBus) app.UnitOfWork {
+	return app.NewEventUnitOfWork(database.NewUnitOfWork(db), bus)
+}
+
+// newMemoryUnitOfWork is newDatabaseUnitOfWork for memory.Store.
+func newMemoryUnitOfWork(store *memory.Store, bus *app.EventBus) app.UnitOfWork {
+	return app.NewEventUnitOfWork(memory.NewUnitOfWork(store), bus)
+}
@@ -4,11 +4,15 @@
 package inject
 
 import (
+	nethttp "net/http"
+
 	"github.com/google/wire"
 
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/cli"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/http"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/memory"
 	"github.com/KScaesar/IsCoolLab2024/pkg/app"
 )
 
@@ -25,7 +29,12 @@ func NewAppService(infra *adapters.Infra) *app.Service {
 	panic(wire.Build(
 		// https://github.com/google/wire/blob/main/docs/guide.md#use-fields-of-a-struct-as-providers
 		wire.FieldsOf(new(*adapters.Infra), "Database"),
-		wire.Struct(new(app.Service), "*"),
+
+		newDatabaseUnitOfWork,
+		app.NewEventBus,
+
+		database.NewOutboxRepository,
+		wire.Bind(new(app.OutboxRepository), new(*database.OutboxRepository)),
 
 		database.NewUserRepository,
 		wire.Bind(new(app.UserRepository), new(*database.UserRepository)),
@@ -33,20 +42,95 @@ func NewAppService(infra *adapters.Infra) *app.Service {
 		database.NewFileSystemRepository,
 		wire.Bind(new(app.FileSystemRepository), new(*database.FileSystemRepository)),
 
-		app.NewUserUseCase,
-		wire.Bind(new(app.UserService), new(*app.UserUseCase)),
+		database.NewAuditRepository,
+		wire.Bind(new(app.AuditRepository), new(*database.AuditRepository)),
 
-		app.NewFolderUseCase,
-		wire.Bind(new(app.FolderService), new(*app.FolderUseCase)),
+		database.NewWebhookRepository,
+		wire.Bind(new(app.WebhookRepository), new(*database.WebhookRepository)),
 
-		app.NewFileUseCase,
-		wire.Bind(new(app.FileService), new(*app.FileUseCase)),
+		http.NewWebhookSender,
+		wire.Bind(new(app.WebhookSender), new(*http.WebhookSender)),
+
+		useCaseSet,
+	))
+}
+
+// NewMemoryAppService keeps all data in memory,
+// so vFS can be embedded in other programs without a sql driver.
+func NewMemoryAppService() *app.Service {
+	panic(wire.Build(
+		memory.NewStore,
+
+		newMemoryUnitOfWork,
+		app.NewEventBus,
+
+		memory.NewOutboxRepository,
+		wire.Bind(new(app.OutboxRepository), new(*memory.OutboxRepository)),
+
+		memory.NewUserRepository,
+		wire.Bind(new(app.UserRepository), new(*memory.UserRepository)),
+
+		memory.NewFileSystemRepository,
+		wire.Bind(new(app.FileSystemRepository), new(*memory.FileSystemRepository)),
+
+		memory.NewAuditRepository,
+		wire.Bind(new(app.AuditRepository), new(*memory.AuditRepository)),
+
+		memory.NewWebhookRepository,
+		wire.Bind(new(app.WebhookRepository), new(*memory.WebhookRepository)),
+
+		http.NewWebhookSender,
+		wire.Bind(new(app.WebhookSender), new(*http.WebhookSender)),
+
+		useCaseSet,
+	))
+}
+
+var useCaseSet = wire.NewSet(
+	wire.Struct(new(app.Service), "*"),
+
+	app.NewUserUseCase,
+	wire.Bind(new(app.UserService), new(*app.UserUseCase)),
+
+	app.NewAuthUseCase,
+	wire.Bind(new(app.AuthService), new(*app.AuthUseCase)),
+
+	app.NewFolderUseCase,
+	wire.Bind(new(app.FolderService), new(*app.FolderUseCase)),
+
+	app.NewFileUseCase,
+	wire.Bind(new(app.FileService), new(*app.FileUseCase)),
+
+	app.NewTrashUseCase,
+	wire.Bind(new(app.TrashService), new(*app.TrashUseCase)),
+
+	app.NewStatUseCase,
+	wire.Bind(new(app.StatService), new(*app.StatUseCase)),
+
+	app.NewShareUseCase,
+	wire.Bind(new(app.ShareService), new(*app.ShareUseCase)),
+
+	app.NewAuditUseCase,
+	wire.Bind(new(app.AuditService), new(*app.AuditUseCase)),
+
+	app.NewWebhookUseCase,
+	wire.Bind(new(app.WebhookService), new(*app.WebhookUseCase)),
+)
+
+func NewHttpServer(infra *adapters.Infra) *http.Server {
+	panic(wire.Build(
+		NewAppService,
+		http.NewServer,
 	))
 }
 
 func NewRootCommand(infra *adapters.Infra) *cli.Command {
 	panic(wire.Build(
 		NewAppService,
+		http.NewServer,
+		wire.Bind(new(nethttp.Handler), new(*http.Server)),
+		wire.FieldsOf(new(*adapters.Infra), "Database"),
+		database.NewMigrator,
 		cli.NewRootCommand,
 	))
 }
@@ -7,9 +7,13 @@
 package inject
 
 import (
+	"github.com/google/wire"
+
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/cli"
 	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/database"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/http"
+	"github.com/KScaesar/IsCoolLab2024/pkg/adapters/memory"
 	"github.com/KScaesar/IsCoolLab2024/pkg/app"
 )
 
@@ -28,21 +32,89 @@ func NewInfra(conf *database.GormConfing) (*adapters.Infra, error) {
 
 func NewAppService(infra *adapters.Infra) *app.Service {
 	db := infra.Database
+	outboxRepository := database.NewOutboxRepository(db)
+	eventBus := app.NewEventBus(outboxRepository)
+	unitOfWork := newDatabaseUnitOfWork(db, eventBus)
 	userRepository := database.NewUserRepository(db)
 	fileSystemRepository := database.NewFileSystemRepository(db)
-	userUseCase := app.NewUserUseCase(userRepository, fileSystemRepository)
-	folderUseCase := app.NewFolderUseCase(fileSystemRepository)
-	fileUseCase := app.NewFileUseCase(fileSystemRepository)
+	auditRepository := database.NewAuditRepository(db)
+	webhookRepository := database.NewWebhookRepository(db)
+	webhookSender := http.NewWebhookSender()
+	userUseCase := app.NewUserUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	authUseCase := app.NewAuthUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	folderUseCase := app.NewFolderUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	fileUseCase := app.NewFileUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	trashUseCase := app.NewTrashUseCase(unitOfWork, fileSystemRepository, auditRepository)
+	statUseCase := app.NewStatUseCase(unitOfWork, fileSystemRepository)
+	shareUseCase := app.NewShareUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	auditUseCase := app.NewAuditUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	webhookUseCase := app.NewWebhookUseCase(unitOfWork, userRepository, fileSystemRepository, webhookRepository, auditRepository, eventBus, webhookSender)
+	service := &app.Service{
+		UserService:    userUseCase,
+		AuthService:    authUseCase,
+		FolderService:  folderUseCase,
+		FileService:    fileUseCase,
+		TrashService:   trashUseCase,
+		StatService:    statUseCase,
+		ShareService:   shareUseCase,
+		AuditService:   auditUseCase,
+		WebhookService: webhookUseCase,
+		Events:         eventBus,
+	}
+	return service
+}
+
+// NewMemoryAppService keeps all data in memory,
+// so vFS can be embedded in other programs without a sql driver.
+func NewMemoryAppService() *app.Service {
+	store := memory.NewStore()
+	outboxRepository := memory.NewOutboxRepository(store)
+	eventBus := app.NewEventBus(outboxRepository)
+	unitOfWork := newMemoryUnitOfWork(store, eventBus)
+	userRepository := memory.NewUserRepository(store)
+	fileSystemRepository := memory.NewFileSystemRepository(store)
+	auditRepository := memory.NewAuditRepository(store)
+	webhookRepository := memory.NewWebhookRepository(store)
+	webhookSender := http.NewWebhookSender()
+	userUseCase := app.NewUserUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	authUseCase := app.NewAuthUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	folderUseCase := app.NewFolderUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	fileUseCase := app.NewFileUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	trashUseCase := app.NewTrashUseCase(unitOfWork, fileSystemRepository, auditRepository)
+	statUseCase := app.NewStatUseCase(unitOfWork, fileSystemRepository)
+	shareUseCase := app.NewShareUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	auditUseCase := app.NewAuditUseCase(unitOfWork, userRepository, fileSystemRepository, auditRepository)
+	webhookUseCase := app.NewWebhookUseCase(unitOfWork, userRepository, fileSystemRepository, webhookRepository, auditRepository, eventBus, webhookSender)
 	service := &app.Service{
-		UserService:   userUseCase,
-		FolderService: folderUseCase,
-		FileService:   fileUseCase,
+		UserService:    userUseCase,
+		AuthService:    authUseCase,
+		FolderService:  folderUseCase,
+		FileService:    fileUseCase,
+		TrashService:   trashUseCase,
+		StatService:    statUseCase,
+		ShareService:   shareUseCase,
+		AuditService:   auditUseCase,
+		WebhookService: webhookUseCase,
+		Events:         eventBus,
 	}
 	return service
 }
 
+func NewHttpServer(infra *adapters.Infra) *http.Server {
+	service := NewAppService(infra)
+	server := http.NewServer(service)
+	return server
+}
+
 func NewRootCommand(infra *adapters.Infra) *cli.Command {
 	service := NewAppService(infra)
-	command := cli.NewRootCommand(service)
+	server := http.NewServer(service)
+	db := infra.Database
+	migrator := database.NewMigrator(db)
+	command := cli.NewRootCommand(service, server, migrator)
 	return command
 }
+
+// wire.go:
+
+var useCaseSet = wire.NewSet(wire.Struct(new(app.Service), "*"), app.NewUserUseCase, wire.Bind(new(app.UserService), new(*app.UserUseCase)), app.NewAuthUseCase, wire.Bind(new(app.AuthService), new(*app.AuthUseCase)), app.NewFolderUseCase, wire.Bind(new(app.FolderService), new(*app.FolderUseCase)), app.NewFileUseCase, wire.Bind(new(app.FileService), new(*app.FileUseCase)), app.NewTrashUseCase, wire.Bind(new(app.TrashService), new(*app.TrashUseCase)), app.NewStatUseCase, wire.Bind(new(app.StatService), new(*app.StatUseCase)), app.NewShareUseCase, wire.Bind(new(app.ShareService), new(*app.ShareUseCase)), app.NewAuditUseCase, wire.Bind(new(app.AuditService), new(*app.AuditUseCase)), app.NewWebhookUseCase, wire.Bind(new(app.WebhookService), new(*app.WebhookUseCase)))
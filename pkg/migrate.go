@@ -0,0 +1,337 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const SchemaMigrationTable = "schema_migrations"
+
+var (
+	ErrSchemaTooNew   = errors.New("is newer than vFS supports, please upgrade vFS.")
+	ErrSchemaOutdated = errors.New("is outdated, please run `vFS migrate up`.")
+)
+
+// Migration is a pair of sql scripts named as
+// "0001_init.up.sql" and "0001_init.down.sql".
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%v", m.Version, m.Name)
+}
+
+// MustLoadMigrations reads the migrations in dir,
+// it panics when the files are malformed, because they are embedded at build time.
+func MustLoadMigrations(fsys fs.FS, dir string) []Migration {
+	migrations, err := LoadMigrations(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return migrations
+}
+
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[int]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+		base, direction, ok := cutMigrationFilename(filename)
+		if !ok {
+			return nil, fmt.Errorf("invalid migration filename %v", filename)
+		}
+
+		prefix, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration filename %v", filename)
+		}
+
+		script, err := fs.ReadFile(fsys, path.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := table[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			table[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("duplicate migration version %v", version)
+		}
+
+		if direction == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(table))
+	for _, migration := range table {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %v requires both up and down scripts", migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration version %v is missing", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+func cutMigrationFilename(filename string) (base string, direction string, ok bool) {
+	base, ok = strings.CutSuffix(filename, ".up.sql")
+	if ok {
+		return base, "up", true
+	}
+	base, ok = strings.CutSuffix(filename, ".down.sql")
+	if ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedTime *time.Time
+}
+
+func NewMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Migrator applies the migrations in order,
+// and records the applied versions in SchemaMigrationTable.
+// Each migration runs in its own transaction.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+type schemaMigration struct {
+	Version     int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name        string    `gorm:"column:name;type:varchar(256);not null"`
+	AppliedTime time.Time `gorm:"column:applied_time;not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return SchemaMigrationTable
+}
+
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version returns the latest applied version, 0 means nothing has been applied.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for _, row := range applied {
+		version = max(version, row.Version)
+	}
+	return version, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) ([]schemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(SchemaMigrationTable) {
+		return nil, nil
+	}
+
+	var rows []schemaMigration
+	err := db.Table(SchemaMigrationTable).
+		Order("version").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[int]time.Time, len(applied))
+	for _, row := range applied {
+		times[row.Version] = row.AppliedTime
+	}
+
+	status := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		status[i].Migration = migration
+		appliedTime, ok := times[migration.Version]
+		if ok {
+			status[i].AppliedTime = &appliedTime
+		}
+	}
+	return status, nil
+}
+
+// Check verifies the database is migrated to the latest version.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version > m.Latest() {
+		return fmt.Errorf("Error: The schema version %v %w", version, ErrSchemaTooNew)
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("Error: The schema version %v %w", version, ErrSchemaOutdated)
+	}
+	return nil
+}
+
+// Init migrates an empty database to the latest version,
+// a database which already holds tables is only checked,
+// so its upgrade is always started by the user.
+func (m *Migrator) Init(ctx context.Context) error {
+	tables, err := m.db.WithContext(ctx).Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+
+	if len(tables) == 0 {
+		_, err = m.Up(ctx)
+		return err
+	}
+	return m.Check(ctx)
+}
+
+// Up applies all pending migrations, and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version > m.Latest() {
+		return nil, fmt.Errorf("Error: The schema version %v %w", version, ErrSchemaTooNew)
+	}
+
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(SchemaMigrationTable) {
+		err = db.Migrator().CreateTable(&schemaMigration{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations[version:] {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := execScript(tx, migration.Up)
+			if err != nil {
+				return fmt.Errorf("migrate up %v: %w", migration, err)
+			}
+
+			return tx.Table(SchemaMigrationTable).Create(&schemaMigration{
+				Version:     migration.Version,
+				Name:        migration.Name,
+				AppliedTime: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the latest applied migration, it returns nil when nothing has been applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version > m.Latest() {
+		return nil, fmt.Errorf("Error: The schema version %v %w", version, ErrSchemaTooNew)
+	}
+	if version == 0 {
+		return nil, nil
+	}
+
+	migration := m.migrations[version-1]
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := execScript(tx, migration.Down)
+		if err != nil {
+			return fmt.Errorf("migrate down %v: %w", migration, err)
+		}
+
+		return tx.Table(SchemaMigrationTable).
+			Delete(&schemaMigration{}, "version = ?", migration.Version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+// execScript executes the statements of script one by one,
+// because not every driver accepts multiple statements in a single call.
+// A statement ends with ";" at the end of a line.
+func execScript(db *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		err := db.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var builder strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		builder.WriteString(line)
+		builder.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, builder.String())
+			builder.Reset()
+		}
+	}
+
+	if strings.TrimSpace(builder.String()) != "" {
+		statements = append(statements, builder.String())
+	}
+	return statements
+}
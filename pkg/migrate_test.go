@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr bool
+	}{
+		{
+			name: "success",
+			files: fstest.MapFS{
+				"m/0002_book.up.sql":   {Data: []byte("CREATE TABLE books (id integer);")},
+				"m/0002_book.down.sql": {Data: []byte("DROP TABLE books;")},
+				"m/0001_user.up.sql":   {Data: []byte("CREATE TABLE users (id integer);")},
+				"m/0001_user.down.sql": {Data: []byte("DROP TABLE users;")},
+			},
+		},
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"m/0001_user.up.sql": {Data: []byte("CREATE TABLE users (id integer);")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing version",
+			files: fstest.MapFS{
+				"m/0002_book.up.sql":   {Data: []byte("CREATE TABLE books (id integer);")},
+				"m/0002_book.down.sql": {Data: []byte("DROP TABLE books;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid filename",
+			files: fstest.MapFS{
+				"m/user.sql": {Data: []byte("CREATE TABLE users (id integer);")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadMigrations() error=%v, wantErr=%v", err, tt.wantErr)
+				return
+			}
+			if err == nil && migrations[0].String() != "0001_user" {
+				t.Errorf("LoadMigrations() first=%v, want=%v", migrations[0], "0001_user")
+			}
+		})
+	}
+}
+
+func Test_splitStatements(t *testing.T) {
+	script := `-- comment;
+CREATE TABLE users (
+  id   integer,
+  name varchar(64) DEFAULT ';'
+);
+
+CREATE INDEX idx_users_name ON users (name);
+UPDATE users SET name = 'a'`
+
+	want := []string{
+		"CREATE TABLE users (\n  id   integer,\n  name varchar(64) DEFAULT ';'\n);\n",
+		"CREATE INDEX idx_users_name ON users (name);\n",
+		"UPDATE users SET name = 'a'\n",
+	}
+
+	got := splitStatements(script)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() got=%q, want=%q", got, want)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	db, err := NewSqliteGorm(":memory:", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	migrations := MustLoadMigrations(fstest.MapFS{
+		"m/0001_user.up.sql":   {Data: []byte("CREATE TABLE users (id integer);")},
+		"m/0001_user.down.sql": {Data: []byte("DROP TABLE users;")},
+		"m/0002_book.up.sql":   {Data: []byte("CREATE TABLE books (id integer);")},
+		"m/0002_book.down.sql": {Data: []byte("DROP TABLE books;")},
+	}, "m")
+
+	err = NewMigrator(db, migrations[:1]).Init(ctx)
+	if err != nil {
+		t.Fatalf("Init() error=%v", err)
+	}
+
+	migrator := NewMigrator(db, migrations)
+	err = migrator.Init(ctx)
+	if !errors.Is(err, ErrSchemaOutdated) {
+		t.Errorf("Init() error=%v, want=%v", err, ErrSchemaOutdated)
+	}
+
+	done, err := migrator.Up(ctx)
+	if err != nil || len(done) != 1 || done[0].Name != "book" {
+		t.Errorf("Up() done=%v, error=%v", done, err)
+	}
+
+	err = NewMigrator(db, migrations[:1]).Check(ctx)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Check() error=%v, want=%v", err, ErrSchemaTooNew)
+	}
+
+	reverted, err := migrator.Down(ctx)
+	if err != nil || reverted.Name != "book" || db.Migrator().HasTable("books") {
+		t.Errorf("Down() reverted=%v, error=%v", reverted, err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil || status[0].AppliedTime == nil || status[1].AppliedTime != nil {
+		t.Errorf("Status() status=%v, error=%v", status, err)
+	}
+}
@@ -1,8 +1,8 @@
 package pkg
 
 import (
+	"cmp"
 	"fmt"
-	"reflect"
 	"strings"
 )
 
@@ -13,47 +13,87 @@ const (
 	SortKind_Asc  SortKind = "asc"
 )
 
-func SortValidate(structParams any) (err error) {
-	SortTraversalParams(structParams, func(key string, value SortKind) {
-		if err != nil {
-			return
-		}
+func (kind SortKind) Validate() error {
+	if kind == SortKind_Asc || kind == SortKind_Desc {
+		return nil
+	}
+	return fmt.Errorf("%v is invalid value, want 'desc' or 'asc'", kind)
+}
 
-		value = SortKind(strings.ToLower(string(value)))
-		if value == SortKind_Asc || value == SortKind_Desc {
-			return
-		}
-		err = fmt.Errorf("%v is invalid value, want 'desc' or 'asc'", key)
-	})
-	return err
+// SortKey is one key of a multi-key sort, e.g. "created:desc".
+type SortKey struct {
+	Key  string
+	Kind SortKind
 }
 
-func SortTraversalParams(structParams any, action func(key string, value SortKind)) {
-	v := reflect.ValueOf(structParams)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	t := v.Type()
+func (k SortKey) IsDesc() bool {
+	return k.Kind == SortKind_Desc
+}
 
-	for i := 0; i < v.NumField(); i++ {
-		vField := v.Field(i)
+// ParseSortKeys parses the comma separated keys, e.g. "created:desc,name:asc",
+// the kind of a key defaults to asc.
+func ParseSortKeys(s string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		key, kind, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if key == "" {
+			return nil, fmt.Errorf("%q has an empty key", s)
+		}
+		if kind == "" {
+			kind = string(SortKind_Asc)
+		}
 
-		if IsZeroValue(vField) {
-			continue
+		k := SortKey{Key: strings.ToLower(key), Kind: SortKind(strings.ToLower(kind))}
+		err := k.Kind.Validate()
+		if err != nil {
+			return nil, err
 		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// NaturalCompare compares the runs of digits by their numeric values, and the others byte by byte,
+// so "file2" sorts before "file10".
+func NaturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := cutDigits(a)
+			numB, restB := cutDigits(b)
 
-		value, ok := vField.Interface().(SortKind)
-		if !ok {
+			// compare the values without the leading zeros, a longer value is a larger one
+			valueA := strings.TrimLeft(numA, "0")
+			valueB := strings.TrimLeft(numB, "0")
+			if len(valueA) != len(valueB) {
+				return cmp.Compare(len(valueA), len(valueB))
+			}
+			if c := strings.Compare(valueA, valueB); c != 0 {
+				return c
+			}
+			// "01" sorts after "1"
+			if len(numA) != len(numB) {
+				return cmp.Compare(len(numA), len(numB))
+			}
+			a, b = restA, restB
 			continue
 		}
 
-		tField := t.Field(i)
-		tag := tField.Tag.Get("sort")
-		key := tag
-		if key == "" {
-			key = tField.Name
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
 		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
 
-		action(key, value)
+func cutDigits(s string) (digits string, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
 	}
+	return s[:i], s[i:]
 }
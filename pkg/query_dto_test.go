@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []SortKey
+		wantErr bool
+	}{
+		{
+			name: "multiple keys",
+			text: "created:desc,name:asc",
+			want: []SortKey{{Key: "created", Kind: SortKind_Desc}, {Key: "name", Kind: SortKind_Asc}},
+		},
+		{
+			name: "asc by default",
+			text: "Size, name:DESC",
+			want: []SortKey{{Key: "size", Kind: SortKind_Asc}, {Key: "name", Kind: SortKind_Desc}},
+		},
+		{
+			name:    "empty key",
+			text:    "name,,created",
+			wantErr: true,
+		},
+		{
+			name:    "invalid kind",
+			text:    "name:up",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSortKeys(tt.text)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "file2", b: "file10", want: -1},
+		{a: "file10", b: "file10", want: 0},
+		{a: "file10.txt", b: "file9.txt", want: 1},
+		{a: "file1", b: "file01", want: -1},
+		{a: "v1.2.10", b: "v1.10.2", want: -1},
+		{a: "File2", b: "file1", want: -1},
+		{a: "file", b: "file1", want: -1},
+		{a: "99999999999999999999", b: "100000000000000000000", want: -1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NaturalCompare(tt.a, tt.b), "%v vs %v", tt.a, tt.b)
+	}
+}
@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -9,6 +12,21 @@ type TimeFunc interface {
 	Sleep(d time.Duration)
 }
 
+// NewTimeFunc returns the TimeFunc of the system clock.
+func NewTimeFunc() TimeFunc {
+	return systemTimeFunc{}
+}
+
+type systemTimeFunc struct{}
+
+func (systemTimeFunc) Now() time.Time {
+	return time.Now()
+}
+
+func (systemTimeFunc) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
 // NewMockTimeFunc
 // This can be useful for testing scenarios that involve time-sensitive operations without
 // actually manipulating the system clock.
@@ -42,3 +60,45 @@ func (f MockTimeFunc) Now() time.Time {
 func (f *MockTimeFunc) Sleep(d time.Duration) {
 	f.now = f.now.Add(d)
 }
+
+// ParseDuration extends time.ParseDuration with the day unit "d",
+// which can't be combined with other units.
+//
+// Example usage:
+//
+//	ParseDuration("30d")
+//	ParseDuration("12h30m")
+func ParseDuration(s string) (time.Duration, error) {
+	value, ok := strings.CutSuffix(s, "d")
+	if !ok {
+		return time.ParseDuration(s)
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// ParseTime accepts RFC3339, or a date and an optional time in loc.
+//
+// Example usage:
+//
+//	ParseTime("2024-05-27T23:00:00+08:00", time.Local)
+//	ParseTime("2024-05-27 23:00:00", time.Local)
+//	ParseTime("2024-05-27", time.Local)
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		t, err = time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("time: invalid time %q", s)
+}
@@ -2,6 +2,12 @@ package pkg
 
 import (
 	cryptoRand "crypto/rand"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/gookit/goutil/maputil"
 	"github.com/oklog/ulid/v2"
@@ -20,6 +26,60 @@ func NewUlid() string {
 	return id.String()
 }
 
+func IsUlid(s string) bool {
+	_, err := ulid.ParseStrict(s)
+	return err == nil
+}
+
+//
+
+// DetectContentType prefers the MIME type of the extension of name,
+// and sniffs data when the extension is unknown.
+func DetectContentType(name string, data []byte) string {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType != "" {
+		return contentType
+	}
+	if len(data) == 0 {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(data)
+}
+
+// ParseByteSize accepts a number of bytes with an optional binary unit,
+// "KB", "MB" or "GB" is 1024 times the former one.
+//
+// Example usage:
+//
+//	ParseByteSize("512")
+//	ParseByteSize("10MB")
+func ParseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	value, size := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, unit := range units {
+		number, ok := strings.CutSuffix(value, unit.suffix)
+		if ok {
+			value, size = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n > (1<<63-1)/size {
+		return 0, fmt.Errorf("size: invalid size %q", s)
+	}
+	return n * size, nil
+}
+
 //
 
 type MapData maputil.Data